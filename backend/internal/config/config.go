@@ -9,41 +9,59 @@ import (
 
 // Config stores runtime configuration values loaded from environment variables.
 type Config struct {
-	AppName            string
-	ServerPort         string
-	DBDSN              string
-	JWTSecret          string
-	AccessTokenTTL     time.Duration
-	RefreshTokenTTL    time.Duration
-	UploadDir          string
-	FrontendURL        string
-	SeedMasterEmail    string
-	SeedMasterPassword string
-	SMTPHost           string
-	SMTPPort           int
-	SMTPUsername       string
-	SMTPPassword       string
-	SMTPFrom           string
+	AppName               string
+	ServerPort            string
+	DBDSN                 string
+	JWTSecret             string
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	UploadDir             string
+	FrontendURL           string
+	SeedMasterEmail       string
+	SeedMasterPassword    string
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	SMTPFrom              string
+	MaxOpenJobsPerTeknisi int
+	PMSchedulerInterval   time.Duration
+	SLACheckInterval      time.Duration
+	DispatchNoPattern     string
+	DispatchNoCounter     string
+	ExportDir             string
+	ExportAsyncRows       int
+	SealKeyFile           string
+	VerifyURL             string
 }
 
 // Load reads environment variables and returns a Config with safe defaults.
 func Load() *Config {
 	return &Config{
-		AppName:            getEnv("APP_NAME", "Service Report"),
-		ServerPort:         getEnv("SERVER_PORT", "8080"),
-		DBDSN:              getEnv("DB_DSN", "root:@tcp(127.0.0.1:3306)/service_reports?parseTime=true&loc=Local"),
-		JWTSecret:          getEnv("JWT_SECRET", "CHANGE_ME"),
-		AccessTokenTTL:     getDuration("JWT_ACCESS_TTL", 15*time.Minute),
-		RefreshTokenTTL:    getDuration("JWT_REFRESH_TTL", 24*time.Hour),
-		UploadDir:          getEnv("UPLOAD_DIR", "./uploads"),
-		FrontendURL:        getEnv("FRONTEND_URL", "http://localhost:5173"),
-		SeedMasterEmail:    getEnv("SEED_MASTER_EMAIL", "master@corp.com"),
-		SeedMasterPassword: getEnv("SEED_MASTER_PASSWORD", "ChangeMe123!"),
-		SMTPHost:           getEnv("SMTP_HOST", ""),
-		SMTPPort:           getInt("SMTP_PORT", 587),
-		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
-		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
-		SMTPFrom:           getEnv("SMTP_FROM", ""),
+		AppName:               getEnv("APP_NAME", "Service Report"),
+		ServerPort:            getEnv("SERVER_PORT", "8080"),
+		DBDSN:                 getEnv("DB_DSN", "root:@tcp(127.0.0.1:3306)/service_reports?parseTime=true&loc=Local"),
+		JWTSecret:             getEnv("JWT_SECRET", "CHANGE_ME"),
+		AccessTokenTTL:        getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL:       getDuration("JWT_REFRESH_TTL", 24*time.Hour),
+		UploadDir:             getEnv("UPLOAD_DIR", "./uploads"),
+		FrontendURL:           getEnv("FRONTEND_URL", "http://localhost:5173"),
+		SeedMasterEmail:       getEnv("SEED_MASTER_EMAIL", "master@corp.com"),
+		SeedMasterPassword:    getEnv("SEED_MASTER_PASSWORD", "ChangeMe123!"),
+		SMTPHost:              getEnv("SMTP_HOST", ""),
+		SMTPPort:              getInt("SMTP_PORT", 587),
+		SMTPUsername:          getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:              getEnv("SMTP_FROM", ""),
+		MaxOpenJobsPerTeknisi: getInt("MAX_OPEN_JOBS_PER_TEKNISI", 0),
+		PMSchedulerInterval:   getDuration("PM_SCHEDULER_INTERVAL", time.Hour),
+		SLACheckInterval:      getDuration("SLA_CHECK_INTERVAL", 5*time.Minute),
+		DispatchNoPattern:     getEnv("DISPATCH_NO_PATTERN", "SR/{YYYY}/{MM}/{seq:00000}"),
+		DispatchNoCounter:     getEnv("DISPATCH_NO_COUNTER", "year"),
+		ExportDir:             getEnv("EXPORT_DIR", "./exports"),
+		ExportAsyncRows:       getInt("EXPORT_ASYNC_ROWS", 5000),
+		SealKeyFile:           getEnv("SEAL_KEY_FILE", "./keys/seal.key"),
+		VerifyURL:             getEnv("VERIFY_URL", "http://localhost:8080/verify"),
 	}
 }
 
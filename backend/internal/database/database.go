@@ -4,8 +4,15 @@ import (
 	"fmt"
 
 	"github.com/company/internal-service-report/internal/config"
+	"github.com/company/internal-service-report/internal/domain/asset"
+	"github.com/company/internal-service-report/internal/domain/calendar"
+	"github.com/company/internal-service-report/internal/domain/customer"
+	"github.com/company/internal-service-report/internal/domain/inventory"
+	"github.com/company/internal-service-report/internal/domain/maintenance"
 	"github.com/company/internal-service-report/internal/domain/partner"
 	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/internal/domain/seal"
+	"github.com/company/internal-service-report/internal/domain/sla"
 	"github.com/company/internal-service-report/internal/domain/user"
 	"github.com/company/internal-service-report/pkg/bcrypt"
 	"gorm.io/driver/mysql"
@@ -23,6 +30,17 @@ func Connect(dsn string) (*gorm.DB, error) {
 
 // AutoMigrate syncs schema for all entities.
 func AutoMigrate(db *gorm.DB) error {
+	// Visits recorded before they carried a device take it from their report, so that the
+	// per device cycle index can be built on existing rows.
+	if db.Migrator().HasTable(&maintenance.PMVisit{}) && !db.Migrator().HasColumn(&maintenance.PMVisit{}, "DeviceID") {
+		if err := db.Migrator().AddColumn(&maintenance.PMVisit{}, "DeviceID"); err != nil {
+			return err
+		}
+		if err := db.Exec("UPDATE pm_visits AS v JOIN service_reports AS r ON r.id = v.report_id SET v.device_id = COALESCE(r.device_id, 0)").Error; err != nil {
+			return err
+		}
+	}
+
 	if err := db.AutoMigrate(
 		&user.Role{},
 		&user.User{},
@@ -30,7 +48,33 @@ func AutoMigrate(db *gorm.DB) error {
 		&report.ReportPhoto{},
 		&report.ReportAttachment{},
 		&report.StatusLog{},
+		&report.ReportEvent{},
+		&report.ReportAssignment{},
+		&report.ReportSearchDoc{},
+		&report.ReportSparePart{},
+		&report.ReportTool{},
+		&report.ReportDevice{},
+		&report.ReportTimesheet{},
+		&report.DispatchSequence{},
+		&report.ExportJob{},
 		&partner.PartnerLocation{},
+		&asset.Device{},
+		&customer.Customer{},
+		&customer.Department{},
+		&customer.Contact{},
+		&inventory.Part{},
+		&inventory.StockLocation{},
+		&inventory.Stock{},
+		&inventory.LedgerEntry{},
+		&maintenance.PMPlan{},
+		&maintenance.PMVisit{},
+		&sla.SLAPolicy{},
+		&sla.ReportSLA{},
+		&calendar.Region{},
+		&calendar.RegionProvince{},
+		&calendar.Holiday{},
+		&seal.ReportSeal{},
+		&seal.VerificationToken{},
 	); err != nil {
 		return err
 	}
@@ -50,6 +94,16 @@ func AutoMigrate(db *gorm.DB) error {
 			return err
 		}
 	}
+	if !db.Migrator().HasIndex(&report.ServiceReport{}, "idx_updated_at") {
+		if err := db.Migrator().CreateIndex(&report.ServiceReport{}, "idx_updated_at"); err != nil {
+			return err
+		}
+	}
+	if !db.Migrator().HasIndex(&report.ServiceReport{}, "idx_completed_at") {
+		if err := db.Migrator().CreateIndex(&report.ServiceReport{}, "idx_completed_at"); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,57 @@
+package asset
+
+import "time"
+
+// CreateDeviceRequest registers a device.
+type CreateDeviceRequest struct {
+	Manufacturer      string     `json:"manufacturer" binding:"required"`
+	Model             string     `json:"model" binding:"required"`
+	SerialNumber      string     `json:"serial_number" binding:"required"`
+	Name              string     `json:"name"`
+	PartnerLocationID *uint64    `json:"partner_location_id"`
+	Location          string     `json:"location"`
+	InstalledAt       *time.Time `json:"installed_at"`
+	Notes             string     `json:"notes"`
+}
+
+// UpdateDeviceRequest changes device fields; nil fields are left untouched.
+type UpdateDeviceRequest struct {
+	Manufacturer      *string    `json:"manufacturer"`
+	Model             *string    `json:"model"`
+	SerialNumber      *string    `json:"serial_number"`
+	Name              *string    `json:"name"`
+	PartnerLocationID *uint64    `json:"partner_location_id"`
+	Location          *string    `json:"location"`
+	InstalledAt       *time.Time `json:"installed_at"`
+	Notes             *string    `json:"notes"`
+}
+
+// ListQuery binds the device listing parameters.
+type ListQuery struct {
+	Q                 string `form:"q"`
+	PartnerLocationID uint64 `form:"partner_location_id"`
+	Page              int    `form:"page" binding:"omitempty,min=1"`
+	PageSize          int    `form:"page_size" binding:"omitempty,min=1,max=200"`
+}
+
+// HistoryPart is a spare part used on a past report.
+type HistoryPart struct {
+	PartNo      string  `json:"part_no"`
+	Description string  `json:"description"`
+	Qty         float64 `json:"qty"`
+}
+
+// HistoryEntry is one past report of a device.
+type HistoryEntry struct {
+	ReportID     uint64        `json:"report_id"`
+	DispatchNo   string        `json:"dispatch_no"`
+	Status       string        `json:"status"`
+	CustomerName string        `json:"customer_name"`
+	OpenedAt     time.Time     `json:"opened_at"`
+	CompletedAt  *time.Time    `json:"completed_at"`
+	TeknisiID    *uint64       `json:"teknisi_id"`
+	TeknisiName  string        `json:"teknisi_name"`
+	Complaint    string        `json:"complaint"`
+	ActionTaken  string        `json:"action_taken"`
+	Parts        []HistoryPart `json:"parts"`
+}
@@ -0,0 +1,113 @@
+package asset
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes device registry endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) List(c *gin.Context) {
+	var q ListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	devices, total, err := h.svc.List(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OKWithMeta(c, devices, gin.H{"total": total})
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+	device, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, device)
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	device, err := h.svc.Create(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, device)
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+	var req UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	device, err := h.svc.Update(c.Request.Context(), id, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, device)
+}
+
+func (h *Handler) History(c *gin.Context) {
+	id, ok := bindID(c)
+	if !ok {
+		return
+	}
+	entries, err := h.svc.History(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, entries)
+}
+
+func bindID(c *gin.Context) (uint64, bool) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return 0, false
+	}
+	return uri.ID, true
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, report.ErrDeviceNotFound):
+		response.NotFound(c, "device not found")
+	case errors.Is(err, ErrDuplicateDevice):
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
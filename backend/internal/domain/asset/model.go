@@ -0,0 +1,21 @@
+package asset
+
+import "time"
+
+// Device is one physical unit, identified by manufacturer, model and serial number.
+// SerialKey is the serial with case, spaces and punctuation removed so that
+// "SN-1234 a" and "sn1234A" resolve to the same unit.
+type Device struct {
+	ID                uint64     `gorm:"primaryKey" json:"id"`
+	Manufacturer      string     `gorm:"size:100;uniqueIndex:idx_device_identity,priority:1" json:"manufacturer"`
+	Model             string     `gorm:"size:100;uniqueIndex:idx_device_identity,priority:2" json:"model"`
+	SerialNumber      string     `gorm:"size:100" json:"serial_number"`
+	SerialKey         string     `gorm:"size:100;uniqueIndex:idx_device_identity,priority:3;index" json:"-"`
+	Name              string     `gorm:"size:100" json:"name"`
+	PartnerLocationID *uint64    `gorm:"index" json:"partner_location_id"`
+	Location          string     `gorm:"size:120" json:"location"`
+	InstalledAt       *time.Time `json:"installed_at"`
+	Notes             string     `gorm:"type:text" json:"notes"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
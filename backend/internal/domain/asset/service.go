@@ -0,0 +1,391 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/internal/domain/user"
+)
+
+var ErrDuplicateDevice = errors.New("a device with this manufacturer, model and serial number already exists")
+
+// Service manages the device registry.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// placeholderSerials are normalized values typed when a unit has no readable serial, such
+// as "N/A" or "-". They identify nothing, so they are treated like a blank serial.
+var placeholderSerials = map[string]bool{
+	"NA":       true,
+	"NONE":     true,
+	"NIL":      true,
+	"NULL":     true,
+	"UNKNOWN":  true,
+	"TIDAKADA": true,
+}
+
+// SerialKey normalizes a serial number for matching. Placeholder serials normalize to "".
+func SerialKey(serial string) string {
+	var b strings.Builder
+	for _, r := range serial {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	if placeholderSerials[b.String()] {
+		return ""
+	}
+	return b.String()
+}
+
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (s *Service) List(ctx context.Context, q ListQuery) ([]Device, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Device{})
+	if q.PartnerLocationID != 0 {
+		query = query.Where("partner_location_id = ?", q.PartnerLocationID)
+	}
+	if term := strings.TrimSpace(q.Q); term != "" {
+		like := "%" + term + "%"
+		if key := SerialKey(term); key != "" {
+			query = query.Where("(manufacturer LIKE ? OR model LIKE ? OR name LIKE ? OR serial_number LIKE ? OR serial_key = ?)",
+				like, like, like, like, key)
+		} else {
+			query = query.Where("(manufacturer LIKE ? OR model LIKE ? OR name LIKE ? OR serial_number LIKE ?)",
+				like, like, like, like)
+		}
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	page, size := q.Page, q.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 50
+	}
+	var devices []Device
+	if err := query.Order("manufacturer ASC, model ASC, serial_key ASC").
+		Limit(size).Offset((page - 1) * size).
+		Find(&devices).Error; err != nil {
+		return nil, 0, err
+	}
+	return devices, total, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uint64) (*Device, error) {
+	var device Device
+	if err := s.db.WithContext(ctx).First(&device, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, report.ErrDeviceNotFound
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (s *Service) Create(ctx context.Context, req CreateDeviceRequest) (*Device, error) {
+	device := &Device{
+		Manufacturer:      cleanText(req.Manufacturer),
+		Model:             cleanText(req.Model),
+		SerialNumber:      strings.TrimSpace(req.SerialNumber),
+		SerialKey:         SerialKey(req.SerialNumber),
+		Name:              cleanText(req.Name),
+		PartnerLocationID: req.PartnerLocationID,
+		Location:          cleanText(req.Location),
+		InstalledAt:       req.InstalledAt,
+		Notes:             req.Notes,
+	}
+	if device.Name == "" {
+		device.Name = cleanText(device.Manufacturer + " " + device.Model)
+	}
+	if err := s.ensureUnique(s.db.WithContext(ctx), device, 0); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(device).Error; err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *Service) Update(ctx context.Context, id uint64, req UpdateDeviceRequest) (*Device, error) {
+	device, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Manufacturer != nil {
+		device.Manufacturer = cleanText(*req.Manufacturer)
+	}
+	if req.Model != nil {
+		device.Model = cleanText(*req.Model)
+	}
+	if req.SerialNumber != nil {
+		device.SerialNumber = strings.TrimSpace(*req.SerialNumber)
+		device.SerialKey = SerialKey(*req.SerialNumber)
+	}
+	if req.Name != nil {
+		device.Name = cleanText(*req.Name)
+	}
+	if req.PartnerLocationID != nil {
+		device.PartnerLocationID = req.PartnerLocationID
+	}
+	if req.Location != nil {
+		device.Location = cleanText(*req.Location)
+	}
+	if req.InstalledAt != nil {
+		device.InstalledAt = req.InstalledAt
+	}
+	if req.Notes != nil {
+		device.Notes = *req.Notes
+	}
+	if err := s.ensureUnique(s.db.WithContext(ctx), device, device.ID); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(device).Error; err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *Service) ensureUnique(db *gorm.DB, device *Device, exceptID uint64) error {
+	var count int64
+	if err := db.Model(&Device{}).
+		Where("manufacturer = ? AND model = ? AND serial_key = ? AND id <> ?", device.Manufacturer, device.Model, device.SerialKey, exceptID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateDevice
+	}
+	return nil
+}
+
+// History lists every report of a device, newest first, with the parts used on each.
+func (s *Service) History(ctx context.Context, id uint64) ([]HistoryEntry, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	var reports []report.ServiceReport
+	if err := s.db.WithContext(ctx).
+		Where("device_id = ?", id).
+		Order("opened_at DESC").
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return []HistoryEntry{}, nil
+	}
+
+	reportIDs := make([]uint64, 0, len(reports))
+	teknisiIDs := make([]uint64, 0, len(reports))
+	for _, r := range reports {
+		reportIDs = append(reportIDs, r.ID)
+		if r.TeknisiID != nil {
+			teknisiIDs = append(teknisiIDs, *r.TeknisiID)
+		}
+	}
+	var parts []report.ReportSparePart
+	if err := s.db.WithContext(ctx).Where("report_id IN ?", reportIDs).Order("position ASC").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	partsByReport := map[uint64][]HistoryPart{}
+	for _, p := range parts {
+		partsByReport[p.ReportID] = append(partsByReport[p.ReportID], HistoryPart{
+			PartNo:      p.PartNo,
+			Description: p.Description,
+			Qty:         p.Qty,
+		})
+	}
+	names := map[uint64]string{}
+	if len(teknisiIDs) > 0 {
+		var users []user.User
+		if err := s.db.WithContext(ctx).Select("id", "full_name").Where("id IN ?", teknisiIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = u.FullName
+		}
+	}
+
+	entries := make([]HistoryEntry, 0, len(reports))
+	for _, r := range reports {
+		entry := HistoryEntry{
+			ReportID:     r.ID,
+			DispatchNo:   r.DispatchNo,
+			Status:       r.Status,
+			CustomerName: r.CustomerName,
+			OpenedAt:     r.OpenedAt,
+			CompletedAt:  r.CompletedAt,
+			TeknisiID:    r.TeknisiID,
+			Complaint:    r.Complaint,
+			ActionTaken:  r.ActionTaken,
+			Parts:        partsByReport[r.ID],
+		}
+		if r.TeknisiID != nil {
+			entry.TeknisiName = names[*r.TeknisiID]
+		}
+		if entry.Parts == nil {
+			entry.Parts = []HistoryPart{}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ResolveDevice implements report.DeviceResolver. A serial number that matches exactly one
+// registered device resolves to it; an unknown serial registers a new device. Blank and
+// placeholder serials leave the report without a device.
+func (s *Service) ResolveDevice(tx *gorm.DB, ref report.DeviceRef) (report.DeviceRef, error) {
+	var device Device
+	if ref.ID != nil {
+		if err := tx.First(&device, *ref.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ref, report.ErrDeviceNotFound
+			}
+			return ref, err
+		}
+		return toRef(&device, ref), nil
+	}
+
+	key := SerialKey(ref.Serial)
+	if key == "" {
+		return ref, nil
+	}
+	query := tx.Where("serial_key = ?", key)
+	if m := cleanText(ref.Manufacturer); m != "" {
+		query = query.Where("manufacturer = ?", m)
+	}
+	if m := cleanText(ref.Model); m != "" {
+		query = query.Where("model = ?", m)
+	}
+	var matches []Device
+	if err := query.Limit(2).Find(&matches).Error; err != nil {
+		return ref, err
+	}
+	switch len(matches) {
+	case 0:
+		device = Device{
+			Manufacturer:      cleanText(ref.Manufacturer),
+			Model:             cleanText(ref.Model),
+			SerialNumber:      strings.TrimSpace(ref.Serial),
+			SerialKey:         key,
+			Name:              cleanText(ref.Name),
+			PartnerLocationID: ref.PartnerLocationID,
+			Location:          cleanText(ref.Location),
+		}
+		if err := tx.Create(&device).Error; err != nil {
+			return ref, err
+		}
+	case 1:
+		device = matches[0]
+		updates := map[string]interface{}{}
+		if device.PartnerLocationID == nil && ref.PartnerLocationID != nil {
+			device.PartnerLocationID = ref.PartnerLocationID
+			updates["partner_location_id"] = ref.PartnerLocationID
+		}
+		if device.Location == "" && cleanText(ref.Location) != "" {
+			device.Location = cleanText(ref.Location)
+			updates["location"] = device.Location
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&device).Updates(updates).Error; err != nil {
+				return ref, err
+			}
+		}
+	default:
+		return ref, report.ErrDeviceAmbiguous
+	}
+	return toRef(&device, ref), nil
+}
+
+func toRef(device *Device, ref report.DeviceRef) report.DeviceRef {
+	id := device.ID
+	out := report.DeviceRef{
+		ID:                &id,
+		Manufacturer:      device.Manufacturer,
+		Model:             device.Model,
+		Name:              device.Name,
+		Serial:            device.SerialNumber,
+		Location:          ref.Location,
+		PartnerLocationID: device.PartnerLocationID,
+	}
+	if out.Name == "" {
+		out.Name = cleanText(ref.Name)
+	}
+	if out.Name == "" {
+		out.Name = cleanText(device.Manufacturer + " " + device.Model)
+	}
+	if out.Location == "" {
+		out.Location = device.Location
+	}
+	return out
+}
+
+// LinkReports attaches reports created before the registry existed to their devices.
+// Reports whose serial matches several devices, or is a placeholder, are left unlinked.
+func (s *Service) LinkReports(ctx context.Context) error {
+	if err := s.unlinkPlaceholders(ctx); err != nil {
+		return err
+	}
+	var reports []report.ServiceReport
+	return s.db.WithContext(ctx).
+		Select("id", "device_name", "serial_number", "device_location").
+		Where("device_id IS NULL AND serial_number <> ''").
+		FindInBatches(&reports, 100, func(_ *gorm.DB, _ int) error {
+			for _, r := range reports {
+				err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+					ref, err := s.ResolveDevice(tx, report.DeviceRef{
+						Name:     r.DeviceName,
+						Serial:   r.SerialNumber,
+						Location: r.DeviceLocation,
+					})
+					if err != nil || ref.ID == nil {
+						return err
+					}
+					return tx.Model(&report.ServiceReport{}).Where("id = ?", r.ID).UpdateColumn("device_id", *ref.ID).Error
+				})
+				if err != nil {
+					log.Printf("asset: link report %d: %v", r.ID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+// unlinkPlaceholders detaches reports from devices that were registered under a
+// placeholder serial such as "N/A" before those were recognized, so unrelated units no
+// longer share one service history.
+func (s *Service) unlinkPlaceholders(ctx context.Context) error {
+	keys := make([]string, 0, len(placeholderSerials))
+	for key := range placeholderSerials {
+		keys = append(keys, key)
+	}
+	var ids []uint64
+	if err := s.db.WithContext(ctx).Model(&Device{}).Where("serial_key IN ?", keys).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	result := s.db.WithContext(ctx).Model(&report.ServiceReport{}).Where("device_id IN ?", ids).UpdateColumn("device_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	log.Printf("asset: unlinked %d reports from %d placeholder-serial devices", result.RowsAffected, len(ids))
+	return nil
+}
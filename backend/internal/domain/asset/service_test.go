@@ -0,0 +1,27 @@
+package asset
+
+import "testing"
+
+func TestSerialKey(t *testing.T) {
+	tests := []struct {
+		serial, want string
+	}{
+		{"SN-1234 a", "SN1234A"},
+		{"sn1234A", "SN1234A"},
+		{"  ab.12/34 ", "AB1234"},
+		{"", ""},
+		{"-", ""},
+		{"N/A", ""},
+		{"n.a.", ""},
+		{"NA", ""},
+		{"none", ""},
+		{"Tidak ada", ""},
+		{"NA-1", "NA1"},
+		{"NAN", "NAN"},
+	}
+	for _, tt := range tests {
+		if got := SerialKey(tt.serial); got != tt.want {
+			t.Errorf("SerialKey(%q) = %q, want %q", tt.serial, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Indonesian time zones. The country observes no daylight saving, so fixed offsets are exact
+// and do not depend on the tz database being installed.
+const (
+	ZoneWIB  = "WIB"
+	ZoneWITA = "WITA"
+	ZoneWIT  = "WIT"
+)
+
+var zoneOffsets = map[string]int{ZoneWIB: 7, ZoneWITA: 8, ZoneWIT: 9}
+
+// maxSearchDays bounds the day walk in AddBusiness for calendars with very few working days.
+const maxSearchDays = 3660
+
+var ErrInvalidHours = errors.New(`hours must map mon..sun to spans like "08:00-17:00"`)
+
+var weekdayKeys = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// provinceZones maps BPS province codes to their time zone.
+var provinceZones = map[string]string{
+	"51": ZoneWITA, "52": ZoneWITA, "53": ZoneWITA,
+	"63": ZoneWITA, "64": ZoneWITA, "65": ZoneWITA,
+	"71": ZoneWITA, "72": ZoneWITA, "73": ZoneWITA, "74": ZoneWITA, "75": ZoneWITA, "76": ZoneWITA,
+	"81": ZoneWIT, "82": ZoneWIT,
+	"91": ZoneWIT, "92": ZoneWIT, "93": ZoneWIT, "94": ZoneWIT, "95": ZoneWIT, "96": ZoneWIT,
+}
+
+// ZoneForProvince returns the time zone of a province, WIB when unknown.
+func ZoneForProvince(code string) string {
+	if zone, ok := provinceZones[strings.TrimSpace(code)]; ok {
+		return zone
+	}
+	return ZoneWIB
+}
+
+// Location returns the fixed location for a zone name, WIB when unknown.
+func Location(zone string) *time.Location {
+	offset, ok := zoneOffsets[zone]
+	if !ok {
+		zone, offset = ZoneWIB, zoneOffsets[ZoneWIB]
+	}
+	return time.FixedZone(zone, offset*3600)
+}
+
+// IsZone reports whether zone is WIB, WITA or WIT.
+func IsZone(zone string) bool {
+	_, ok := zoneOffsets[zone]
+	return ok
+}
+
+// DefaultHours is Monday to Friday, 08:00 to 17:00.
+var DefaultHours = map[string][]string{
+	"mon": {"08:00-17:00"}, "tue": {"08:00-17:00"}, "wed": {"08:00-17:00"},
+	"thu": {"08:00-17:00"}, "fri": {"08:00-17:00"},
+}
+
+type span struct {
+	start, end time.Duration // offsets from local midnight
+}
+
+// Calendar answers business time questions for one region.
+type Calendar struct {
+	zone     string
+	loc      *time.Location
+	week     [7][]span
+	holidays map[string]string
+}
+
+// New builds a calendar from weekday hours and holidays keyed by YYYY-MM-DD.
+func New(zone string, hours map[string][]string, holidays map[string]string) (*Calendar, error) {
+	if !IsZone(zone) {
+		zone = ZoneWIB
+	}
+	c := &Calendar{zone: zone, loc: Location(zone), holidays: holidays}
+	if c.holidays == nil {
+		c.holidays = map[string]string{}
+	}
+	for key, spans := range hours {
+		day, ok := weekdayKeys[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return nil, ErrInvalidHours
+		}
+		for _, raw := range spans {
+			s, err := parseSpan(raw)
+			if err != nil {
+				return nil, err
+			}
+			c.week[day] = append(c.week[day], s)
+		}
+		sort.Slice(c.week[day], func(i, j int) bool { return c.week[day][i].start < c.week[day][j].start })
+		for i := 1; i < len(c.week[day]); i++ {
+			if c.week[day][i].start < c.week[day][i-1].end {
+				return nil, ErrInvalidHours
+			}
+		}
+	}
+	return c, nil
+}
+
+// ValidateHours checks a weekday hours map without building a calendar.
+func ValidateHours(hours map[string][]string) error {
+	_, err := New(ZoneWIB, hours, nil)
+	return err
+}
+
+func parseSpan(raw string) (span, error) {
+	parts := strings.Split(strings.TrimSpace(raw), "-")
+	if len(parts) != 2 {
+		return span{}, ErrInvalidHours
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return span{}, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return span{}, err
+	}
+	if end <= start {
+		return span{}, ErrInvalidHours
+	}
+	return span{start: start, end: end}, nil
+}
+
+func parseClock(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidHours, raw)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Zone returns the calendar's time zone name.
+func (c *Calendar) Zone() string { return c.zone }
+
+// Location returns the calendar's time zone.
+func (c *Calendar) Location() *time.Location { return c.loc }
+
+func (c *Calendar) hasHours() bool {
+	for _, spans := range c.week {
+		if len(spans) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// workingSpans returns the working intervals of the day starting at midnight.
+func (c *Calendar) workingSpans(midnight time.Time) []span {
+	if _, ok := c.holidays[midnight.Format("2006-01-02")]; ok {
+		return nil
+	}
+	return c.week[midnight.Weekday()]
+}
+
+func (c *Calendar) midnight(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// IsHoliday reports whether t falls on a holiday in the calendar's zone.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	_, ok := c.holidays[c.midnight(t).Format("2006-01-02")]
+	return ok
+}
+
+// IsWorkingTime reports whether t falls inside working hours.
+func (c *Calendar) IsWorkingTime(t time.Time) bool {
+	day := c.midnight(t)
+	for _, s := range c.workingSpans(day) {
+		if !t.Before(day.Add(s.start)) && t.Before(day.Add(s.end)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BusinessDuration returns the working time between from and to, skipping nights, days off
+// and holidays. A calendar without any working hours counts nothing.
+func (c *Calendar) BusinessDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	var total time.Duration
+	for day := c.midnight(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, s := range c.workingSpans(day) {
+			start, end := day.Add(s.start), day.Add(s.end)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+	return total
+}
+
+// AddBusiness returns the instant d of working time after from. Calendars without working
+// hours fall back to wall-clock time.
+func (c *Calendar) AddBusiness(from time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return from
+	}
+	if !c.hasHours() {
+		return from.Add(d)
+	}
+	remaining := d
+	day := c.midnight(from)
+	for i := 0; i < maxSearchDays; i++ {
+		for _, s := range c.workingSpans(day) {
+			start, end := day.Add(s.start), day.Add(s.end)
+			if !end.After(from) {
+				continue
+			}
+			if start.Before(from) {
+				start = from
+			}
+			avail := end.Sub(start)
+			if remaining <= avail {
+				return start.Add(remaining)
+			}
+			remaining -= avail
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return from.Add(d)
+}
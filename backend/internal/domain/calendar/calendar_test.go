@@ -0,0 +1,176 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustCalendar(t *testing.T, zone string, hours map[string][]string, holidays map[string]string) *Calendar {
+	t.Helper()
+	c, err := New(zone, hours, holidays)
+	if err != nil {
+		t.Fatalf("New(%s): %v", zone, err)
+	}
+	return c
+}
+
+func at(zone, value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, Location(zone))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestZoneForProvince(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"31", ZoneWIB},  // DKI Jakarta
+		{"51", ZoneWITA}, // Bali
+		{"73", ZoneWITA}, // Sulawesi Selatan
+		{"81", ZoneWIT},  // Maluku
+		{"91", ZoneWIT},  // Papua
+		{" 94 ", ZoneWIT},
+		{"", ZoneWIB},
+		{"XX", ZoneWIB},
+	}
+	for _, tt := range tests {
+		if got := ZoneForProvince(tt.code); got != tt.want {
+			t.Errorf("ZoneForProvince(%q) = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidHours(t *testing.T) {
+	tests := []map[string][]string{
+		{"monday": {"08:00-17:00"}},
+		{"mon": {"17:00-08:00"}},
+		{"mon": {"08:00"}},
+		{"mon": {"8am-5pm"}},
+		{"mon": {"08:00-12:00", "11:00-17:00"}},
+	}
+	for _, hours := range tests {
+		if _, err := New(ZoneWIB, hours, nil); !errors.Is(err, ErrInvalidHours) {
+			t.Errorf("New(%v) error = %v, want ErrInvalidHours", hours, err)
+		}
+	}
+	if err := ValidateHours(map[string][]string{"Sat": {"08:00-12:00"}, "sun": {"00:00-24:00"}}); err != nil {
+		t.Errorf("ValidateHours: %v", err)
+	}
+}
+
+func TestBusinessDuration(t *testing.T) {
+	split := map[string][]string{
+		"mon": {"08:00-12:00", "13:00-17:00"},
+		"tue": {"08:00-12:00", "13:00-17:00"},
+		"wed": {"08:00-12:00", "13:00-17:00"},
+		"thu": {"08:00-12:00", "13:00-17:00"},
+		"fri": {"08:00-11:30", "13:00-17:00"},
+	}
+	// 2024-03-01 is a Friday; 2024-03-11 (Nyepi) is a Monday.
+	holidays := map[string]string{"2024-03-11": "Hari Suci Nyepi"}
+	wib := mustCalendar(t, ZoneWIB, DefaultHours, holidays)
+	wit := mustCalendar(t, ZoneWIT, DefaultHours, holidays)
+	lunch := mustCalendar(t, ZoneWIB, split, nil)
+
+	tests := []struct {
+		name     string
+		cal      *Calendar
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"same working day", wib, at(ZoneWIB, "2024-03-04 09:00"), at(ZoneWIB, "2024-03-04 11:30"), 150 * time.Minute},
+		{"before opening to after closing", wib, at(ZoneWIB, "2024-03-04 06:00"), at(ZoneWIB, "2024-03-04 20:00"), 9 * time.Hour},
+		{"overnight", wib, at(ZoneWIB, "2024-03-04 16:00"), at(ZoneWIB, "2024-03-05 09:00"), 2 * time.Hour},
+		{"inside the night", wib, at(ZoneWIB, "2024-03-04 18:00"), at(ZoneWIB, "2024-03-05 07:00"), 0},
+		{"whole weekend", wib, at(ZoneWIB, "2024-03-02 00:00"), at(ZoneWIB, "2024-03-04 00:00"), 0},
+		{"full week", wib, at(ZoneWIB, "2024-03-04 00:00"), at(ZoneWIB, "2024-03-11 00:00"), 45 * time.Hour},
+		{"holiday skipped", wib, at(ZoneWIB, "2024-03-08 16:00"), at(ZoneWIB, "2024-03-12 09:00"), 2 * time.Hour},
+		{"lunch break", lunch, at(ZoneWIB, "2024-03-04 11:00"), at(ZoneWIB, "2024-03-04 14:00"), 2 * time.Hour},
+		{"friday prayer break", lunch, at(ZoneWIB, "2024-03-01 11:00"), at(ZoneWIB, "2024-03-01 14:00"), 90 * time.Minute},
+		{"reversed", wib, at(ZoneWIB, "2024-03-05 09:00"), at(ZoneWIB, "2024-03-04 09:00"), 0},
+		{"empty", wib, at(ZoneWIB, "2024-03-04 09:00"), at(ZoneWIB, "2024-03-04 09:00"), 0},
+
+		// A job opened Friday evening in Papua and picked up Monday morning in Jayapura counts
+		// only Monday's working time, whatever zone the timestamps were recorded in.
+		{"jayapura friday evening", wit, at(ZoneWIT, "2024-03-01 18:30"), at(ZoneWIT, "2024-03-04 10:00"), 2 * time.Hour},
+		{"jayapura from WIB timestamps", wit, at(ZoneWIB, "2024-03-01 19:00"), at(ZoneWIB, "2024-03-04 08:00"), 2 * time.Hour},
+		{"jayapura from UTC timestamps", wit, at(ZoneWIB, "2024-03-01 19:00").UTC(), at(ZoneWIB, "2024-03-04 08:00").UTC(), 2 * time.Hour},
+		{"same span on a WIB calendar", wib, at(ZoneWIB, "2024-03-01 19:00"), at(ZoneWIB, "2024-03-04 08:00"), 0},
+		{"jayapura friday afternoon", wit, at(ZoneWIT, "2024-03-01 15:00"), at(ZoneWIT, "2024-03-04 10:00"), 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := tt.cal.BusinessDuration(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: BusinessDuration() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBusinessDurationWithoutHours(t *testing.T) {
+	c := mustCalendar(t, ZoneWIB, nil, nil)
+	if got := c.BusinessDuration(at(ZoneWIB, "2024-03-04 09:00"), at(ZoneWIB, "2024-03-05 09:00")); got != 0 {
+		t.Errorf("BusinessDuration() = %v, want 0", got)
+	}
+}
+
+func TestAddBusiness(t *testing.T) {
+	holidays := map[string]string{"2024-03-11": "Hari Suci Nyepi"}
+	wib := mustCalendar(t, ZoneWIB, DefaultHours, holidays)
+	wit := mustCalendar(t, ZoneWIT, DefaultHours, holidays)
+	none := mustCalendar(t, ZoneWIB, nil, nil)
+
+	tests := []struct {
+		name string
+		cal  *Calendar
+		from time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{"within the day", wib, at(ZoneWIB, "2024-03-04 09:00"), 2 * time.Hour, at(ZoneWIB, "2024-03-04 11:00")},
+		{"ends at closing", wib, at(ZoneWIB, "2024-03-04 15:00"), 2 * time.Hour, at(ZoneWIB, "2024-03-04 17:00")},
+		{"rolls overnight", wib, at(ZoneWIB, "2024-03-04 16:00"), 2 * time.Hour, at(ZoneWIB, "2024-03-05 09:00")},
+		{"starts before opening", wib, at(ZoneWIB, "2024-03-04 05:00"), time.Hour, at(ZoneWIB, "2024-03-04 09:00")},
+		{"skips the weekend", wib, at(ZoneWIB, "2024-03-01 16:00"), 2 * time.Hour, at(ZoneWIB, "2024-03-04 09:00")},
+		{"skips weekend and holiday", wib, at(ZoneWIB, "2024-03-08 16:00"), 2 * time.Hour, at(ZoneWIB, "2024-03-12 09:00")},
+		{"jayapura friday evening", wit, at(ZoneWIT, "2024-03-01 18:30"), 4 * time.Hour, at(ZoneWIT, "2024-03-04 12:00")},
+		{"zero", wib, at(ZoneWIB, "2024-03-02 10:00"), 0, at(ZoneWIB, "2024-03-02 10:00")},
+		{"no hours is wall clock", none, at(ZoneWIB, "2024-03-02 10:00"), 3 * time.Hour, at(ZoneWIB, "2024-03-02 13:00")},
+	}
+	for _, tt := range tests {
+		got := tt.cal.AddBusiness(tt.from, tt.d)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: AddBusiness() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.cal.hasHours() && tt.d > 0 {
+			if back := tt.cal.BusinessDuration(tt.from, got); back != tt.d {
+				t.Errorf("%s: BusinessDuration(from, AddBusiness(from, d)) = %v, want %v", tt.name, back, tt.d)
+			}
+		}
+	}
+}
+
+func TestIsWorkingTime(t *testing.T) {
+	c := mustCalendar(t, ZoneWIT, DefaultHours, map[string]string{"2024-03-11": "Hari Suci Nyepi"})
+	tests := []struct {
+		when time.Time
+		want bool
+	}{
+		{at(ZoneWIT, "2024-03-04 08:00"), true},
+		{at(ZoneWIT, "2024-03-04 16:59"), true},
+		{at(ZoneWIT, "2024-03-04 17:00"), false},
+		{at(ZoneWIB, "2024-03-04 06:30"), true}, // 08:30 WIT
+		{at(ZoneWIT, "2024-03-02 10:00"), false},
+		{at(ZoneWIT, "2024-03-11 10:00"), false},
+	}
+	for _, tt := range tests {
+		if got := c.IsWorkingTime(tt.when); got != tt.want {
+			t.Errorf("IsWorkingTime(%v) = %v, want %v", tt.when, got, tt.want)
+		}
+	}
+	// 22:30 WIB on the 10th is already the 11th in Papua; 21:30 WIB is not.
+	if !c.IsHoliday(at(ZoneWIB, "2024-03-10 22:30")) || c.IsHoliday(at(ZoneWIB, "2024-03-10 21:30")) {
+		t.Error("IsHoliday does not use the calendar's zone")
+	}
+}
@@ -0,0 +1,72 @@
+package calendar
+
+import "time"
+
+// RegionRequest creates or replaces a region. Hours defaults to Monday to Friday 08:00-17:00.
+type RegionRequest struct {
+	Code      string              `json:"code" binding:"required,max=16"`
+	Name      string              `json:"name" binding:"required"`
+	TimeZone  string              `json:"time_zone" binding:"required,oneof=WIB WITA WIT"`
+	Hours     map[string][]string `json:"hours"`
+	Provinces []string            `json:"provinces"`
+	IsDefault bool                `json:"is_default"`
+}
+
+// HolidayRequest adds a holiday. RegionID left out makes it national.
+type HolidayRequest struct {
+	Date     string `json:"date" binding:"required,datetime=2006-01-02"`
+	Name     string `json:"name" binding:"required,max=150"`
+	RegionID uint64 `json:"region_id"`
+}
+
+// HolidayQuery filters the holiday list.
+type HolidayQuery struct {
+	Year     int     `form:"year" binding:"omitempty,min=2000,max=2100"`
+	RegionID *uint64 `form:"region_id"`
+}
+
+// DurationQuery asks for the business time between two instants for a partner's region,
+// or for a province when no partner is given.
+type DurationQuery struct {
+	From      time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	PartnerID *uint64   `form:"partner_id"`
+	Province  string    `form:"province"`
+}
+
+// DurationResult is the answer to a DurationQuery.
+type DurationResult struct {
+	TimeZone        string `json:"time_zone"`
+	ElapsedMinutes  int64  `json:"elapsed_minutes"`
+	BusinessMinutes int64  `json:"business_minutes"`
+}
+
+// Holiday import row statuses.
+const (
+	ImportCreate  = "create"
+	ImportUpdate  = "update"
+	ImportSaved   = "saved"
+	ImportInvalid = "invalid"
+)
+
+// ImportRow is the outcome of one data row of a holiday import file. Row is the 1-based line
+// number in the file, counting the header.
+type ImportRow struct {
+	Row      int      `json:"row"`
+	Status   string   `json:"status"`
+	Date     string   `json:"date"`
+	Name     string   `json:"name"`
+	Region   string   `json:"region,omitempty"`
+	RegionID uint64   `json:"region_id"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ImportResult summarizes a holiday import or its dry-run preview.
+type ImportResult struct {
+	DryRun  bool        `json:"dry_run"`
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Invalid int         `json:"invalid"`
+	Rows    []ImportRow `json:"rows"`
+}
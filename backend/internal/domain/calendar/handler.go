@@ -0,0 +1,185 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/pkg/response"
+	"github.com/company/internal-service-report/pkg/tabular"
+)
+
+// Handler exposes region, holiday and business duration endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type idURI struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+func (h *Handler) ListRegions(c *gin.Context) {
+	regions, err := h.svc.ListRegions(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, regions)
+}
+
+func (h *Handler) CreateRegion(c *gin.Context) {
+	var req RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	region, err := h.svc.CreateRegion(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, region)
+}
+
+func (h *Handler) UpdateRegion(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	region, err := h.svc.UpdateRegion(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, region)
+}
+
+func (h *Handler) DeleteRegion(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeleteRegion(c.Request.Context(), uri.ID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *Handler) ListHolidays(c *gin.Context) {
+	var q HolidayQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	holidays, err := h.svc.ListHolidays(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, holidays)
+}
+
+func (h *Handler) CreateHoliday(c *gin.Context) {
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	holiday, err := h.svc.CreateHoliday(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, holiday)
+}
+
+func (h *Handler) DeleteHoliday(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeleteHoliday(c.Request.Context(), uri.ID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// ImportHolidays accepts a multipart "file" field. Pass dry_run=true to preview the outcome.
+func (h *Handler) ImportHolidays(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportSize+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if file.Size > MaxImportSize {
+		response.BadRequest(c, fmt.Errorf("import file is larger than %d MB", MaxImportSize>>20))
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	defer f.Close()
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	result, err := h.svc.ImportHolidays(c.Request.Context(), file.Filename, f, dryRun)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if dryRun {
+		response.OK(c, result)
+		return
+	}
+	response.Created(c, result)
+}
+
+// Duration returns the business time between two RFC 3339 instants.
+func (h *Handler) Duration(c *gin.Context) {
+	var q DurationQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	result, err := h.svc.Duration(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, result)
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrRegionNotFound):
+		response.NotFound(c, "region not found")
+	case errors.Is(err, ErrHolidayNotFound):
+		response.NotFound(c, "holiday not found")
+	case errors.Is(err, ErrDuplicateRegion):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, ErrInvalidHours), errors.Is(err, ErrImportEmpty), errors.Is(err, ErrImportNoColumns),
+		errors.Is(err, tabular.ErrUnsupportedFile):
+		response.UnprocessableEntity(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
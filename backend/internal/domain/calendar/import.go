@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/company/internal-service-report/pkg/tabular"
+)
+
+// MaxImportSize caps the size of an uploaded holiday file.
+const MaxImportSize = 2 << 20
+
+var (
+	ErrImportEmpty     = errors.New("import file has no data rows")
+	ErrImportNoColumns = errors.New("import file needs date and name columns")
+)
+
+var holidayColumns = map[string]string{
+	"date":        "date",
+	"tanggal":     "date",
+	"name":        "name",
+	"nama":        "name",
+	"holiday":     "name",
+	"keterangan":  "name",
+	"region":      "region",
+	"region_code": "region",
+	"wilayah":     "region",
+}
+
+// holidayDateLayouts are the date spellings accepted in import files, ISO first. XLSX date
+// cells are read as serial numbers, whatever format they are displayed in.
+var holidayDateLayouts = []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006", "2-1-2006"}
+
+// ImportHolidays reads holidays from a CSV or XLSX file with date, name and an optional
+// region code column; rows without a region are national. Existing days are renamed rather
+// than duplicated. With dryRun the outcome is only previewed.
+func (s *Service) ImportHolidays(ctx context.Context, filename string, r io.Reader, dryRun bool) (*ImportResult, error) {
+	records, date1904, err := tabular.ReadRaw(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, ErrImportEmpty
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		if field, ok := holidayColumns[tabular.HeaderKey(name)]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, ErrImportNoColumns
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, ErrImportNoColumns
+	}
+
+	var regions []Region
+	if err := s.db.WithContext(ctx).Select("id", "code").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+	regionIDs := make(map[string]uint64, len(regions))
+	for _, region := range regions {
+		regionIDs[region.Code] = region.ID
+	}
+	var existing []Holiday
+	if err := s.db.WithContext(ctx).Select("date", "region_id").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, h := range existing {
+		known[fmt.Sprintf("%s|%d", h.Date, h.RegionID)] = true
+	}
+
+	xlsx := strings.EqualFold(filepath.Ext(filename), ".xlsx")
+	cell := func(record []string, field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.Join(strings.Fields(record[idx]), " ")
+	}
+	result := &ImportResult{DryRun: dryRun, Rows: []ImportRow{}}
+	for i, record := range records[1:] {
+		if tabular.IsBlank(record) {
+			continue
+		}
+		row := ImportRow{Row: i + 2, Name: cell(record, "name"), Region: strings.ToUpper(cell(record, "region"))}
+		rawDate := cell(record, "date")
+		if date, ok := parseHolidayDate(rawDate, xlsx, date1904); ok {
+			row.Date = date
+		} else {
+			row.Date = rawDate
+			row.Errors = append(row.Errors, "date must look like 2026-08-17 or 17/08/2026")
+		}
+		switch {
+		case row.Name == "":
+			row.Errors = append(row.Errors, "name is required")
+		case utf8.RuneCountInString(row.Name) > 150:
+			row.Errors = append(row.Errors, "name is longer than 150 characters")
+		}
+		if row.Region != "" {
+			id, ok := regionIDs[row.Region]
+			if !ok {
+				row.Errors = append(row.Errors, "unknown region "+row.Region)
+			}
+			row.RegionID = id
+		}
+		key := fmt.Sprintf("%s|%d", row.Date, row.RegionID)
+		switch {
+		case len(row.Errors) > 0:
+			row.Status = ImportInvalid
+			result.Invalid++
+		case known[key]:
+			row.Status = ImportUpdate
+			result.Updated++
+		default:
+			row.Status = ImportCreate
+			known[key] = true
+			result.Created++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total = len(result.Rows)
+	if result.Total == 0 {
+		return nil, ErrImportEmpty
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range result.Rows {
+			row := &result.Rows[i]
+			if row.Status == ImportInvalid {
+				continue
+			}
+			holiday := Holiday{Date: row.Date, RegionID: row.RegionID, Name: row.Name}
+			if err := tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"name"}),
+			}).Create(&holiday).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			row.Status = ImportSaved
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return result, nil
+}
+
+// parseHolidayDate reads a date cell as one of holidayDateLayouts or, from an XLSX file, as
+// a serial date. Dates typed as text in a workbook still go through the layouts.
+func parseHolidayDate(raw string, xlsx, date1904 bool) (string, bool) {
+	for _, layout := range holidayDateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02"), true
+		}
+	}
+	if xlsx {
+		if t, ok := tabular.ExcelDate(raw, date1904); ok {
+			return t.Format("2006-01-02"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/company/internal-service-report/pkg/tabular"
+)
+
+func TestParseHolidayDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		xlsx bool
+		want string
+	}{
+		{"2026-08-17", false, "2026-08-17"},
+		{"17/08/2026", false, "2026-08-17"},
+		{"1/5/2026", false, "2026-05-01"},
+		{"17-08-2026", false, "2026-08-17"},
+		{"46251", true, "2026-08-17"},
+		{"46251.75", true, "2026-08-17"},
+		{"46251", false, ""},
+		{"08-17-26", true, ""},
+		{"17 Agustus", true, ""},
+		{"", true, ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseHolidayDate(tt.raw, tt.xlsx, false)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("parseHolidayDate(%q, %v) = %q %v, want %q", tt.raw, tt.xlsx, got, ok, tt.want)
+		}
+	}
+}
+
+// TestHolidayXLSXRoundTrip reads dates back from a workbook the way Excel saves them: a date
+// cell displayed as mm-dd-yy, a date cell in a custom format and a date typed as text.
+func TestHolidayXLSXRoundTrip(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	builtin, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := "d mmmm yyyy"
+	custom, err := f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"Tanggal", "Nama"},
+		{time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC), "Hari Kemerdekaan"},
+		{time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), "Hari Natal"},
+		{"01/05/2026", "Hari Buruh"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SetCellStyle(sheet, "A2", "A2", builtin); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle(sheet, "A3", "A3", custom); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, date1904, err := tabular.ReadRaw("libur.xlsx", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2026-08-17", "2026-12-25", "2026-05-01"}
+	if len(records) != len(want)+1 {
+		t.Fatalf("read %d rows, want %d", len(records), len(want)+1)
+	}
+	for i, w := range want {
+		got, ok := parseHolidayDate(records[i+1][0], true, date1904)
+		if !ok || got != w {
+			t.Errorf("row %d: date cell %q read as %q %v, want %s", i+2, records[i+1][0], got, ok, w)
+		}
+	}
+}
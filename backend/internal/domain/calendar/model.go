@@ -0,0 +1,37 @@
+package calendar
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Region groups provinces that share working hours and a time zone. Hours maps weekday
+// keys (mon..sun) to working spans such as "08:00-12:00"; a missing day is a day off.
+type Region struct {
+	ID        uint64           `gorm:"primaryKey" json:"id"`
+	Code      string           `gorm:"size:16;uniqueIndex" json:"code"`
+	Name      string           `gorm:"size:120" json:"name"`
+	TimeZone  string           `gorm:"size:8" json:"time_zone"`
+	Hours     datatypes.JSON   `gorm:"type:json" json:"hours"`
+	IsDefault bool             `json:"is_default"`
+	Provinces []RegionProvince `gorm:"foreignKey:RegionID;constraint:OnDelete:CASCADE" json:"provinces"`
+	CreatedAt time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RegionProvince assigns a province (BPS code, as used by partners) to a region.
+type RegionProvince struct {
+	ProvinceCode string `gorm:"primaryKey;size:10" json:"province_code"`
+	RegionID     uint64 `gorm:"index" json:"region_id"`
+}
+
+// Holiday is a day without business hours. RegionID 0 marks a national holiday.
+// Date is stored as YYYY-MM-DD so it never shifts with the database time zone.
+type Holiday struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Date      string    `gorm:"type:char(10);uniqueIndex:idx_holiday_day,priority:1" json:"date"`
+	RegionID  uint64    `gorm:"uniqueIndex:idx_holiday_day,priority:2" json:"region_id"`
+	Name      string    `gorm:"size:150" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,363 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/company/internal-service-report/internal/domain/partner"
+)
+
+var (
+	ErrRegionNotFound  = errors.New("region not found")
+	ErrDuplicateRegion = errors.New("a region with this code already exists")
+	ErrHolidayNotFound = errors.New("holiday not found")
+)
+
+// Service manages regions and holidays and builds business-hours calendars from them.
+// Built calendars are cached per province until a region or holiday changes.
+type Service struct {
+	db    *gorm.DB
+	mu    sync.Mutex
+	cache map[string]*Calendar
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db, cache: map[string]*Calendar{}}
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cache = map[string]*Calendar{}
+	s.mu.Unlock()
+}
+
+func (s *Service) ListRegions(ctx context.Context) ([]Region, error) {
+	var regions []Region
+	if err := s.db.WithContext(ctx).Preload("Provinces").Order("code ASC").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+func (s *Service) GetRegion(ctx context.Context, id uint64) (*Region, error) {
+	var region Region
+	if err := s.db.WithContext(ctx).Preload("Provinces").First(&region, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRegionNotFound
+		}
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (s *Service) CreateRegion(ctx context.Context, req RegionRequest) (*Region, error) {
+	region := &Region{}
+	if err := s.saveRegion(ctx, region, req); err != nil {
+		return nil, err
+	}
+	return s.GetRegion(ctx, region.ID)
+}
+
+func (s *Service) UpdateRegion(ctx context.Context, id uint64, req RegionRequest) (*Region, error) {
+	region, err := s.GetRegion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveRegion(ctx, region, req); err != nil {
+		return nil, err
+	}
+	return s.GetRegion(ctx, id)
+}
+
+// saveRegion writes a region and moves the listed provinces to it. Only one region can be
+// the default, which applies to provinces no region lists.
+func (s *Service) saveRegion(ctx context.Context, region *Region, req RegionRequest) error {
+	hours := req.Hours
+	if hours == nil {
+		hours = DefaultHours
+	}
+	if err := ValidateHours(hours); err != nil {
+		return err
+	}
+	rawHours, err := json.Marshal(hours)
+	if err != nil {
+		return err
+	}
+	region.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	region.Name = strings.TrimSpace(req.Name)
+	region.TimeZone = req.TimeZone
+	region.Hours = datatypes.JSON(rawHours)
+	region.IsDefault = req.IsDefault
+	region.Provinces = nil
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Region{}).Where("code = ? AND id <> ?", region.Code, region.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicateRegion
+		}
+		if err := tx.Omit("Provinces").Save(region).Error; err != nil {
+			return err
+		}
+		if region.IsDefault {
+			if err := tx.Model(&Region{}).Where("id <> ?", region.ID).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("region_id = ?", region.ID).Delete(&RegionProvince{}).Error; err != nil {
+			return err
+		}
+		for _, code := range req.Provinces {
+			code = strings.TrimSpace(code)
+			if code == "" {
+				continue
+			}
+			row := RegionProvince{ProvinceCode: code, RegionID: region.ID}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// DeleteRegion removes a region with its province assignments and regional holidays.
+func (s *Service) DeleteRegion(ctx context.Context, id uint64) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Region{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRegionNotFound
+		}
+		if err := tx.Where("region_id = ?", id).Delete(&RegionProvince{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("region_id = ?", id).Delete(&Holiday{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// ListHolidays returns holidays ordered by date. A region filter includes national holidays.
+func (s *Service) ListHolidays(ctx context.Context, q HolidayQuery) ([]Holiday, error) {
+	query := s.db.WithContext(ctx).Model(&Holiday{})
+	if q.Year != 0 {
+		query = query.Where("date LIKE ?", fmt.Sprintf("%04d-%%", q.Year))
+	}
+	if q.RegionID != nil {
+		query = query.Where("region_id IN ?", []uint64{0, *q.RegionID})
+	}
+	var holidays []Holiday
+	if err := query.Order("date ASC, region_id ASC").Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// CreateHoliday adds a holiday, or renames it when the day is already listed for the region.
+func (s *Service) CreateHoliday(ctx context.Context, req HolidayRequest) (*Holiday, error) {
+	if err := s.ensureRegion(ctx, req.RegionID); err != nil {
+		return nil, err
+	}
+	holiday := &Holiday{Date: req.Date, RegionID: req.RegionID, Name: strings.TrimSpace(req.Name)}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(holiday).Error; err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	if err := s.db.WithContext(ctx).Where("date = ? AND region_id = ?", holiday.Date, holiday.RegionID).First(holiday).Error; err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+func (s *Service) DeleteHoliday(ctx context.Context, id uint64) error {
+	result := s.db.WithContext(ctx).Delete(&Holiday{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHolidayNotFound
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *Service) ensureRegion(ctx context.Context, id uint64) error {
+	if id == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Select("id").First(&Region{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRegionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ForPartner returns the calendar of a partner's province. Reports without a partner use
+// the default region, or Monday to Friday office hours in WIB.
+func (s *Service) ForPartner(ctx context.Context, partnerID *uint64) (*Calendar, error) {
+	province := ""
+	if partnerID != nil {
+		var location partner.PartnerLocation
+		err := s.db.WithContext(ctx).Select("province_code").First(&location, *partnerID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		province = location.ProvinceCode
+	}
+	return s.ForProvince(ctx, province)
+}
+
+// ForProvince returns the calendar for a province code. The province's region supplies
+// hours, time zone and regional holidays; without one the default region's hours apply in
+// the province's own time zone.
+func (s *Service) ForProvince(ctx context.Context, code string) (*Calendar, error) {
+	code = strings.TrimSpace(code)
+	s.mu.Lock()
+	cal, ok := s.cache[code]
+	s.mu.Unlock()
+	if ok {
+		return cal, nil
+	}
+
+	region, listed, err := s.regionFor(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	zone := ZoneForProvince(code)
+	if region != nil && (listed || code == "") {
+		zone = region.TimeZone
+	}
+
+	hours := DefaultHours
+	regionIDs := []uint64{0}
+	if region != nil {
+		if len(region.Hours) > 0 {
+			if err := json.Unmarshal(region.Hours, &hours); err != nil {
+				return nil, err
+			}
+		}
+		regionIDs = append(regionIDs, region.ID)
+	}
+	var holidays []Holiday
+	if err := s.db.WithContext(ctx).Where("region_id IN ?", regionIDs).Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	days := make(map[string]string, len(holidays))
+	for _, h := range holidays {
+		days[h.Date] = h.Name
+	}
+	cal, err = New(zone, hours, days)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache[code] = cal
+	s.mu.Unlock()
+	return cal, nil
+}
+
+// regionFor returns the region listing a province, or the default region. listed is false
+// for the default region. Both are empty when no region applies.
+func (s *Service) regionFor(ctx context.Context, code string) (region *Region, listed bool, err error) {
+	var link RegionProvince
+	err = s.db.WithContext(ctx).Where("province_code = ?", code).First(&link).Error
+	switch {
+	case err == nil:
+		region, err = s.GetRegion(ctx, link.RegionID)
+		if err == nil {
+			return region, true, nil
+		}
+		if !errors.Is(err, ErrRegionNotFound) {
+			return nil, false, err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, false, err
+	}
+	var fallback Region
+	err = s.db.WithContext(ctx).Where("is_default = ?", true).First(&fallback).Error
+	if err == nil {
+		return &fallback, false, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	return nil, false, err
+}
+
+// BranchCode returns the code of the region serving a partner, used as the branch in
+// dispatch numbers. It implements report.BranchResolver.
+func (s *Service) BranchCode(ctx context.Context, partnerID *uint64) (string, error) {
+	province := ""
+	if partnerID != nil {
+		var location partner.PartnerLocation
+		err := s.db.WithContext(ctx).Select("province_code").First(&location, *partnerID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		province = location.ProvinceCode
+	}
+	region, _, err := s.regionFor(ctx, strings.TrimSpace(province))
+	if err != nil || region == nil {
+		return "", err
+	}
+	return region.Code, nil
+}
+
+// BusinessDuration returns the working time between from and to in the calendar of a
+// partner's region. It implements report.BusinessClock.
+func (s *Service) BusinessDuration(ctx context.Context, partnerID *uint64, from, to time.Time) (time.Duration, error) {
+	cal, err := s.ForPartner(ctx, partnerID)
+	if err != nil {
+		return 0, err
+	}
+	return cal.BusinessDuration(from, to), nil
+}
+
+// Duration answers a DurationQuery.
+func (s *Service) Duration(ctx context.Context, q DurationQuery) (*DurationResult, error) {
+	var cal *Calendar
+	var err error
+	if q.PartnerID != nil {
+		cal, err = s.ForPartner(ctx, q.PartnerID)
+	} else {
+		cal, err = s.ForProvince(ctx, q.Province)
+	}
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Duration(0)
+	if q.To.After(q.From) {
+		elapsed = q.To.Sub(q.From)
+	}
+	return &DurationResult{
+		TimeZone:        cal.Zone(),
+		ElapsedMinutes:  int64(elapsed / time.Minute),
+		BusinessMinutes: int64(cal.BusinessDuration(q.From, q.To) / time.Minute),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package customer
+
+// CreateCustomerRequest registers a customer at a partner location.
+type CreateCustomerRequest struct {
+	PartnerLocationID uint64 `json:"partner_location_id" binding:"required"`
+	Name              string `json:"name" binding:"required"`
+	Address           string `json:"address"`
+	Phone             string `json:"phone"`
+	Email             string `json:"email" binding:"omitempty,email"`
+}
+
+// UpdateCustomerRequest changes customer fields; nil fields are left untouched.
+type UpdateCustomerRequest struct {
+	PartnerLocationID *uint64 `json:"partner_location_id"`
+	Name              *string `json:"name"`
+	Address           *string `json:"address"`
+	Phone             *string `json:"phone"`
+	Email             *string `json:"email" binding:"omitempty,email"`
+}
+
+// DepartmentRequest creates or renames a department.
+type DepartmentRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ContactRequest creates or replaces a contact person.
+type ContactRequest struct {
+	DepartmentID *uint64 `json:"department_id"`
+	Name         string  `json:"name" binding:"required"`
+	Title        string  `json:"title"`
+	Phone        string  `json:"phone"`
+	Email        string  `json:"email" binding:"omitempty,email"`
+}
+
+// ListQuery binds the customer listing parameters.
+type ListQuery struct {
+	PartnerLocationID uint64 `form:"partner_location_id"`
+	Q                 string `form:"q"`
+}
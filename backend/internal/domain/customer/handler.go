@@ -0,0 +1,222 @@
+package customer
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/partner"
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes customer registry endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type customerURI struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+type childURI struct {
+	ID      uint64 `uri:"id" binding:"required"`
+	ChildID uint64 `uri:"child_id" binding:"required"`
+}
+
+func (h *Handler) List(c *gin.Context) {
+	var q ListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	customers, err := h.svc.List(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, customers)
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	var uri customerURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	customer, err := h.svc.Get(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, customer)
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	customer, err := h.svc.Create(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, customer)
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	var uri customerURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	customer, err := h.svc.Update(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, customer)
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	var uri customerURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), uri.ID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *Handler) CreateDepartment(c *gin.Context) {
+	var uri customerURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	department, err := h.svc.CreateDepartment(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, department)
+}
+
+func (h *Handler) UpdateDepartment(c *gin.Context) {
+	var uri childURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req DepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	department, err := h.svc.UpdateDepartment(c.Request.Context(), uri.ID, uri.ChildID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, department)
+}
+
+func (h *Handler) DeleteDepartment(c *gin.Context) {
+	var uri childURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeleteDepartment(c.Request.Context(), uri.ID, uri.ChildID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *Handler) CreateContact(c *gin.Context) {
+	var uri customerURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req ContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	contact, err := h.svc.CreateContact(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, contact)
+}
+
+func (h *Handler) UpdateContact(c *gin.Context) {
+	var uri childURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req ContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	contact, err := h.svc.UpdateContact(c.Request.Context(), uri.ID, uri.ChildID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, contact)
+}
+
+func (h *Handler) DeleteContact(c *gin.Context) {
+	var uri childURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeleteContact(c.Request.Context(), uri.ID, uri.ChildID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, report.ErrCustomerNotFound):
+		response.NotFound(c, "customer not found")
+	case errors.Is(err, report.ErrContactNotFound):
+		response.NotFound(c, "contact not found")
+	case errors.Is(err, ErrDepartmentNotFound):
+		response.NotFound(c, "department not found")
+	case errors.Is(err, partner.ErrPartnerNotFound):
+		response.NotFound(c, "partner not found")
+	case errors.Is(err, ErrCustomerInUse):
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
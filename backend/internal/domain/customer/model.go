@@ -0,0 +1,39 @@
+package customer
+
+import "time"
+
+// Customer is an organisation served at a partner location, usually the hospital itself.
+type Customer struct {
+	ID                uint64       `gorm:"primaryKey" json:"id"`
+	PartnerLocationID uint64       `gorm:"index" json:"partner_location_id"`
+	Name              string       `gorm:"size:120" json:"name"`
+	Address           string       `gorm:"size:255" json:"address"`
+	Phone             string       `gorm:"size:50" json:"phone"`
+	Email             string       `gorm:"size:120" json:"email"`
+	Departments       []Department `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"departments,omitempty"`
+	Contacts          []Contact    `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"contacts,omitempty"`
+	CreatedAt         time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Department is a unit inside a customer such as ICU or Radiology.
+type Department struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	CustomerID uint64    `gorm:"index" json:"customer_id"`
+	Name       string    `gorm:"size:120" json:"name"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Contact is a person at a customer, optionally attached to a department.
+type Contact struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	CustomerID   uint64    `gorm:"index" json:"customer_id"`
+	DepartmentID *uint64   `gorm:"index" json:"department_id"`
+	Name         string    `gorm:"size:120" json:"name"`
+	Title        string    `gorm:"size:120" json:"title"`
+	Phone        string    `gorm:"size:50" json:"phone"`
+	Email        string    `gorm:"size:120" json:"email"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
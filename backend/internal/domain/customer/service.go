@@ -0,0 +1,327 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/partner"
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+var (
+	ErrDepartmentNotFound = errors.New("department not found")
+	ErrCustomerInUse      = errors.New("customer is referenced by service reports")
+)
+
+// Service manages customers, their departments and contact persons.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+func (s *Service) List(ctx context.Context, q ListQuery) ([]Customer, error) {
+	query := s.db.WithContext(ctx).Model(&Customer{})
+	if q.PartnerLocationID != 0 {
+		query = query.Where("partner_location_id = ?", q.PartnerLocationID)
+	}
+	if term := strings.TrimSpace(q.Q); term != "" {
+		query = query.Where("name LIKE ?", "%"+term+"%")
+	}
+	var customers []Customer
+	if err := query.Order("name ASC").Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// Get returns a customer with its departments and contacts.
+func (s *Service) Get(ctx context.Context, id uint64) (*Customer, error) {
+	var customer Customer
+	if err := s.db.WithContext(ctx).
+		Preload("Departments", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).
+		Preload("Contacts", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).
+		First(&customer, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, report.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (s *Service) Create(ctx context.Context, req CreateCustomerRequest) (*Customer, error) {
+	if err := s.ensurePartner(ctx, req.PartnerLocationID); err != nil {
+		return nil, err
+	}
+	customer := &Customer{
+		PartnerLocationID: req.PartnerLocationID,
+		Name:              strings.TrimSpace(req.Name),
+		Address:           strings.TrimSpace(req.Address),
+		Phone:             strings.TrimSpace(req.Phone),
+		Email:             strings.TrimSpace(req.Email),
+	}
+	if err := s.db.WithContext(ctx).Create(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (s *Service) Update(ctx context.Context, id uint64, req UpdateCustomerRequest) (*Customer, error) {
+	customer, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if req.PartnerLocationID != nil {
+		if err := s.ensurePartner(ctx, *req.PartnerLocationID); err != nil {
+			return nil, err
+		}
+		updates["partner_location_id"] = *req.PartnerLocationID
+	}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Address != nil {
+		updates["address"] = strings.TrimSpace(*req.Address)
+	}
+	if req.Phone != nil {
+		updates["phone"] = strings.TrimSpace(*req.Phone)
+	}
+	if req.Email != nil {
+		updates["email"] = strings.TrimSpace(*req.Email)
+	}
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(customer).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(ctx, id)
+}
+
+// Delete removes a customer that no report refers to. Reports keep their snapshot,
+// so a customer with history must stay to keep that history reachable.
+func (s *Service) Delete(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&report.ServiceReport{}).Where("customer_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCustomerInUse
+		}
+		if err := tx.Where("customer_id = ?", id).Delete(&Contact{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("customer_id = ?", id).Delete(&Department{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Customer{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return report.ErrCustomerNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Service) CreateDepartment(ctx context.Context, customerID uint64, req DepartmentRequest) (*Department, error) {
+	if err := s.ensureCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	department := &Department{CustomerID: customerID, Name: strings.TrimSpace(req.Name)}
+	if err := s.db.WithContext(ctx).Create(department).Error; err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+func (s *Service) UpdateDepartment(ctx context.Context, customerID, id uint64, req DepartmentRequest) (*Department, error) {
+	var department Department
+	if err := s.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&department, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+	department.Name = strings.TrimSpace(req.Name)
+	if err := s.db.WithContext(ctx).Model(&department).Update("name", department.Name).Error; err != nil {
+		return nil, err
+	}
+	return &department, nil
+}
+
+// DeleteDepartment removes a department and detaches its contacts.
+func (s *Service) DeleteDepartment(ctx context.Context, customerID, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("customer_id = ?", customerID).Delete(&Department{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDepartmentNotFound
+		}
+		return tx.Model(&Contact{}).Where("department_id = ?", id).Update("department_id", nil).Error
+	})
+}
+
+func (s *Service) CreateContact(ctx context.Context, customerID uint64, req ContactRequest) (*Contact, error) {
+	if err := s.ensureCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureDepartment(ctx, customerID, req.DepartmentID); err != nil {
+		return nil, err
+	}
+	contact := &Contact{
+		CustomerID:   customerID,
+		DepartmentID: req.DepartmentID,
+		Name:         strings.TrimSpace(req.Name),
+		Title:        strings.TrimSpace(req.Title),
+		Phone:        strings.TrimSpace(req.Phone),
+		Email:        strings.TrimSpace(req.Email),
+	}
+	if err := s.db.WithContext(ctx).Create(contact).Error; err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+func (s *Service) UpdateContact(ctx context.Context, customerID, id uint64, req ContactRequest) (*Contact, error) {
+	var contact Contact
+	if err := s.db.WithContext(ctx).Where("customer_id = ?", customerID).First(&contact, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, report.ErrContactNotFound
+		}
+		return nil, err
+	}
+	if err := s.ensureDepartment(ctx, customerID, req.DepartmentID); err != nil {
+		return nil, err
+	}
+	contact.DepartmentID = req.DepartmentID
+	contact.Name = strings.TrimSpace(req.Name)
+	contact.Title = strings.TrimSpace(req.Title)
+	contact.Phone = strings.TrimSpace(req.Phone)
+	contact.Email = strings.TrimSpace(req.Email)
+	if err := s.db.WithContext(ctx).Save(&contact).Error; err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// DeleteContact removes a contact. Reports that named the contact keep their snapshot.
+func (s *Service) DeleteContact(ctx context.Context, customerID, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("customer_id = ?", customerID).Delete(&Contact{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return report.ErrContactNotFound
+		}
+		return tx.Model(&report.ServiceReport{}).Where("contact_id = ?", id).UpdateColumn("contact_id", nil).Error
+	})
+}
+
+// ResolveCustomer implements report.CustomerResolver.
+func (s *Service) ResolveCustomer(db *gorm.DB, customerID, contactID *uint64) (*report.CustomerSnapshot, error) {
+	var contact *Contact
+	if contactID != nil {
+		var c Contact
+		query := db.Where("id = ?", *contactID)
+		if customerID != nil {
+			query = query.Where("customer_id = ?", *customerID)
+		}
+		if err := query.First(&c).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, report.ErrContactNotFound
+			}
+			return nil, err
+		}
+		contact = &c
+		customerID = &c.CustomerID
+	}
+
+	var customer Customer
+	if err := db.First(&customer, *customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, report.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	snapshot := &report.CustomerSnapshot{
+		CustomerID:        customer.ID,
+		PartnerLocationID: customer.PartnerLocationID,
+		Name:              customer.Name,
+		Address:           customer.Address,
+		Phone:             customer.Phone,
+		Email:             customer.Email,
+	}
+	if contact != nil {
+		id := contact.ID
+		snapshot.ContactID = &id
+		snapshot.Person = contact.Name
+		if contact.Phone != "" {
+			snapshot.Phone = contact.Phone
+		}
+		if contact.Email != "" {
+			snapshot.Email = contact.Email
+		}
+		if contact.DepartmentID != nil {
+			var department Department
+			if err := db.Select("name").First(&department, *contact.DepartmentID).Error; err == nil {
+				snapshot.Department = department.Name
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+		}
+	}
+	if snapshot.Address == "" {
+		var location partner.PartnerLocation
+		if err := db.Select("address").First(&location, customer.PartnerLocationID).Error; err == nil {
+			snapshot.Address = location.Address
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+func (s *Service) ensurePartner(ctx context.Context, id uint64) error {
+	if err := s.db.WithContext(ctx).Select("id").First(&partner.PartnerLocation{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return partner.ErrPartnerNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) ensureCustomer(ctx context.Context, id uint64) error {
+	if err := s.db.WithContext(ctx).Select("id").First(&Customer{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return report.ErrCustomerNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) ensureDepartment(ctx context.Context, customerID uint64, id *uint64) error {
+	if id == nil {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Select("id").Where("customer_id = ?", customerID).First(&Department{}, *id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDepartmentNotFound
+		}
+		return err
+	}
+	return nil
+}
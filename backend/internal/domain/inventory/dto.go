@@ -0,0 +1,72 @@
+package inventory
+
+// CreatePartRequest adds a part to the catalog.
+type CreatePartRequest struct {
+	PartNo   string  `json:"part_no" binding:"required"`
+	Name     string  `json:"name" binding:"required"`
+	Unit     string  `json:"unit"`
+	MinStock float64 `json:"min_stock" binding:"min=0"`
+}
+
+// UpdatePartRequest changes catalog fields; nil fields are left untouched.
+type UpdatePartRequest struct {
+	Name     *string  `json:"name"`
+	Unit     *string  `json:"unit"`
+	MinStock *float64 `json:"min_stock" binding:"omitempty,min=0"`
+	Active   *bool    `json:"active"`
+}
+
+// CreateLocationRequest adds a warehouse or technician van.
+type CreateLocationRequest struct {
+	Code      string  `json:"code" binding:"required"`
+	Name      string  `json:"name" binding:"required"`
+	Kind      string  `json:"kind" binding:"required,oneof=warehouse van"`
+	TeknisiID *uint64 `json:"teknisi_id"`
+	IsDefault bool    `json:"is_default"`
+}
+
+// AdjustmentRequest moves stock manually. Qty is signed; Kind defaults to adjustment.
+type AdjustmentRequest struct {
+	PartID     uint64  `json:"part_id" binding:"required"`
+	LocationID uint64  `json:"location_id" binding:"required"`
+	Qty        float64 `json:"qty" binding:"required"`
+	Kind       string  `json:"kind" binding:"omitempty,oneof=receipt adjustment"`
+	Reason     string  `json:"reason" binding:"required"`
+}
+
+// ThresholdRequest sets the low-stock threshold of a part at one location.
+type ThresholdRequest struct {
+	PartID     uint64  `json:"part_id" binding:"required"`
+	LocationID uint64  `json:"location_id" binding:"required"`
+	MinQty     float64 `json:"min_qty" binding:"min=0"`
+}
+
+// StockQuery binds the stock report parameters.
+type StockQuery struct {
+	LocationID uint64 `form:"location_id"`
+	PartNo     string `form:"part_no"`
+	LowOnly    bool   `form:"low_only"`
+}
+
+// LedgerQuery binds the ledger listing parameters.
+type LedgerQuery struct {
+	PartID     uint64 `form:"part_id"`
+	LocationID uint64 `form:"location_id"`
+	ReportID   uint64 `form:"report_id"`
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	PageSize   int    `form:"page_size" binding:"omitempty,min=1,max=200"`
+}
+
+// StockRow is one line of the stock report.
+type StockRow struct {
+	PartID       uint64  `json:"part_id"`
+	PartNo       string  `json:"part_no"`
+	PartName     string  `json:"part_name"`
+	Unit         string  `json:"unit"`
+	LocationID   uint64  `json:"location_id"`
+	LocationCode string  `json:"location_code"`
+	LocationName string  `json:"location_name"`
+	Qty          float64 `json:"qty"`
+	Threshold    float64 `json:"threshold"`
+	Low          bool    `json:"low"`
+}
@@ -0,0 +1,172 @@
+package inventory
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes inventory endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) ListParts(c *gin.Context) {
+	parts, err := h.svc.ListParts(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, parts)
+}
+
+func (h *Handler) CreatePart(c *gin.Context) {
+	var req CreatePartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	part, err := h.svc.CreatePart(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, ErrDuplicatePart) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.Created(c, part)
+}
+
+func (h *Handler) UpdatePart(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req UpdatePartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	part, err := h.svc.UpdatePart(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		if errors.Is(err, ErrPartNotFound) {
+			response.NotFound(c, "part not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, part)
+}
+
+func (h *Handler) ListLocations(c *gin.Context) {
+	locations, err := h.svc.ListLocations(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, locations)
+}
+
+func (h *Handler) CreateLocation(c *gin.Context) {
+	var req CreateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	location, err := h.svc.CreateLocation(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDuplicateLocation):
+			response.Conflict(c, err.Error())
+		case errors.Is(err, ErrVanWithoutOwner):
+			response.UnprocessableEntity(c, err.Error())
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+	response.Created(c, location)
+}
+
+func (h *Handler) Adjust(c *gin.Context) {
+	var req AdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	entry, err := h.svc.Adjust(c.Request.Context(), c.GetUint64("userID"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPartNotFound):
+			response.NotFound(c, "part not found")
+		case errors.Is(err, ErrLocationNotFound):
+			response.NotFound(c, "location not found")
+		case errors.Is(err, ErrZeroQuantity):
+			response.UnprocessableEntity(c, err.Error())
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+	response.Created(c, entry)
+}
+
+func (h *Handler) SetThreshold(c *gin.Context) {
+	var req ThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	stock, err := h.svc.SetThreshold(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPartNotFound):
+			response.NotFound(c, "part not found")
+		case errors.Is(err, ErrLocationNotFound):
+			response.NotFound(c, "location not found")
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+	response.OK(c, stock)
+}
+
+func (h *Handler) Stock(c *gin.Context) {
+	var q StockQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	rows, err := h.svc.StockReport(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, rows)
+}
+
+func (h *Handler) Ledger(c *gin.Context) {
+	var q LedgerQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	entries, total, err := h.svc.Ledger(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OKWithMeta(c, entries, gin.H{"total": total})
+}
@@ -0,0 +1,66 @@
+package inventory
+
+import "time"
+
+// Location kinds.
+const (
+	LocationWarehouse = "warehouse"
+	LocationVan       = "van"
+)
+
+// Ledger entry kinds.
+const (
+	EntryReceipt     = "receipt"
+	EntryAdjustment  = "adjustment"
+	EntryConsumption = "consumption"
+)
+
+// Part is a catalog entry keyed by its normalized part number.
+type Part struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	PartNo    string    `gorm:"size:100;uniqueIndex" json:"part_no"`
+	Name      string    `gorm:"size:255" json:"name"`
+	Unit      string    `gorm:"size:32;default:'pcs'" json:"unit"`
+	MinStock  float64   `gorm:"default:0" json:"min_stock"`
+	Active    bool      `gorm:"default:true" json:"active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// StockLocation is a place holding stock: a warehouse or a technician's van.
+type StockLocation struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Code      string    `gorm:"size:50;uniqueIndex" json:"code"`
+	Name      string    `gorm:"size:150" json:"name"`
+	Kind      string    `gorm:"size:16;default:'warehouse'" json:"kind"`
+	TeknisiID *uint64   `gorm:"index" json:"teknisi_id,omitempty"`
+	IsDefault bool      `gorm:"default:false" json:"is_default"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Stock is the current quantity of a part at a location.
+// MinQty overrides the part's MinStock for this location when greater than zero.
+type Stock struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	PartID     uint64    `gorm:"uniqueIndex:idx_stock_part_location,priority:1" json:"part_id"`
+	LocationID uint64    `gorm:"uniqueIndex:idx_stock_part_location,priority:2;index" json:"location_id"`
+	Qty        float64   `gorm:"default:0" json:"qty"`
+	MinQty     float64   `gorm:"default:0" json:"min_qty"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// LedgerEntry records one stock movement. Qty is signed: receipts are positive,
+// consumption negative.
+type LedgerEntry struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	PartID       uint64    `gorm:"index" json:"part_id"`
+	LocationID   uint64    `gorm:"index" json:"location_id"`
+	Kind         string    `gorm:"size:16" json:"kind"`
+	Qty          float64   `json:"qty"`
+	BalanceAfter float64   `json:"balance_after"`
+	ReportID     *uint64   `gorm:"index" json:"report_id,omitempty"`
+	ActorID      uint64    `json:"actor_id"`
+	Reason       string    `gorm:"size:255" json:"reason"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
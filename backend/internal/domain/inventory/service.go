@@ -0,0 +1,462 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+var (
+	ErrPartNotFound      = errors.New("part not found")
+	ErrLocationNotFound  = errors.New("location not found")
+	ErrDuplicatePart     = errors.New("part number already exists")
+	ErrDuplicateLocation = errors.New("location code already exists")
+	ErrVanWithoutOwner   = errors.New("a van location needs a technician")
+	ErrZeroQuantity      = errors.New("quantity must not be zero")
+)
+
+// Service manages the parts catalog, stock levels and the stock ledger.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// NormalizePartNo is the catalog key used for part numbers typed by technicians.
+func NormalizePartNo(partNo string) string {
+	return strings.ToUpper(strings.TrimSpace(partNo))
+}
+
+func (s *Service) ListParts(ctx context.Context) ([]Part, error) {
+	var parts []Part
+	if err := s.db.WithContext(ctx).Order("part_no ASC").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+func (s *Service) CreatePart(ctx context.Context, req CreatePartRequest) (*Part, error) {
+	part := &Part{
+		PartNo:   NormalizePartNo(req.PartNo),
+		Name:     strings.TrimSpace(req.Name),
+		Unit:     strings.TrimSpace(req.Unit),
+		MinStock: req.MinStock,
+		Active:   true,
+	}
+	if part.Unit == "" {
+		part.Unit = "pcs"
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Part{}).Where("part_no = ?", part.PartNo).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrDuplicatePart
+	}
+	if err := s.db.WithContext(ctx).Create(part).Error; err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+func (s *Service) UpdatePart(ctx context.Context, id uint64, req UpdatePartRequest) (*Part, error) {
+	var part Part
+	if err := s.db.WithContext(ctx).First(&part, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPartNotFound
+		}
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Unit != nil {
+		updates["unit"] = strings.TrimSpace(*req.Unit)
+	}
+	if req.MinStock != nil {
+		updates["min_stock"] = *req.MinStock
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		return &part, nil
+	}
+	if err := s.db.WithContext(ctx).Model(&part).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).First(&part, id).Error; err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+func (s *Service) ListLocations(ctx context.Context) ([]StockLocation, error) {
+	var locations []StockLocation
+	if err := s.db.WithContext(ctx).Order("kind DESC").Order("code ASC").Find(&locations).Error; err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (s *Service) CreateLocation(ctx context.Context, req CreateLocationRequest) (*StockLocation, error) {
+	if req.Kind == LocationVan && req.TeknisiID == nil {
+		return nil, ErrVanWithoutOwner
+	}
+	location := &StockLocation{
+		Code:      strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:      strings.TrimSpace(req.Name),
+		Kind:      req.Kind,
+		TeknisiID: req.TeknisiID,
+		IsDefault: req.IsDefault && req.Kind == LocationWarehouse,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&StockLocation{}).Where("code = ?", location.Code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicateLocation
+		}
+		if location.IsDefault {
+			if err := tx.Model(&StockLocation{}).Where("is_default = ?", true).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(location).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// Adjust records a manual stock movement and returns the ledger entry.
+func (s *Service) Adjust(ctx context.Context, actorID uint64, req AdjustmentRequest) (*LedgerEntry, error) {
+	if req.Qty == 0 {
+		return nil, ErrZeroQuantity
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = EntryAdjustment
+	}
+	var entry *LedgerEntry
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureRefs(tx, req.PartID, req.LocationID); err != nil {
+			return err
+		}
+		var err error
+		entry, err = post(tx, LedgerEntry{
+			PartID:     req.PartID,
+			LocationID: req.LocationID,
+			Kind:       kind,
+			Qty:        req.Qty,
+			ActorID:    actorID,
+			Reason:     strings.TrimSpace(req.Reason),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// SetThreshold sets the low-stock threshold of a part at one location.
+func (s *Service) SetThreshold(ctx context.Context, req ThresholdRequest) (*Stock, error) {
+	var stock *Stock
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureRefs(tx, req.PartID, req.LocationID); err != nil {
+			return err
+		}
+		var err error
+		stock, err = lockStock(tx, req.PartID, req.LocationID)
+		if err != nil {
+			return err
+		}
+		stock.MinQty = req.MinQty
+		return tx.Model(stock).Update("min_qty", req.MinQty).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+// StockReport lists stock per part and location with the effective low-stock threshold.
+func (s *Service) StockReport(ctx context.Context, q StockQuery) ([]StockRow, error) {
+	threshold := "CASE WHEN stocks.min_qty > 0 THEN stocks.min_qty ELSE parts.min_stock END"
+	query := s.db.WithContext(ctx).
+		Table("stocks").
+		Select("parts.id AS part_id, parts.part_no, parts.name AS part_name, parts.unit, " +
+			"stock_locations.id AS location_id, stock_locations.code AS location_code, stock_locations.name AS location_name, " +
+			"stocks.qty, " + threshold + " AS threshold").
+		Joins("JOIN parts ON parts.id = stocks.part_id").
+		Joins("JOIN stock_locations ON stock_locations.id = stocks.location_id")
+	if q.LocationID != 0 {
+		query = query.Where("stocks.location_id = ?", q.LocationID)
+	}
+	if q.PartNo != "" {
+		query = query.Where("parts.part_no = ?", NormalizePartNo(q.PartNo))
+	}
+	if q.LowOnly {
+		query = query.Where("stocks.qty <= " + threshold)
+	}
+	var rows []StockRow
+	if err := query.Order("parts.part_no ASC").Order("stock_locations.code ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Low = rows[i].Qty <= rows[i].Threshold
+	}
+	return rows, nil
+}
+
+// Ledger lists stock movements, newest first.
+func (s *Service) Ledger(ctx context.Context, q LedgerQuery) ([]LedgerEntry, int64, error) {
+	query := s.db.WithContext(ctx).Model(&LedgerEntry{})
+	if q.PartID != 0 {
+		query = query.Where("part_id = ?", q.PartID)
+	}
+	if q.LocationID != 0 {
+		query = query.Where("location_id = ?", q.LocationID)
+	}
+	if q.ReportID != 0 {
+		query = query.Where("report_id = ?", q.ReportID)
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	page, size := q.Page, q.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 50
+	}
+	var entries []LedgerEntry
+	if err := query.Order("created_at DESC").Order("id DESC").Limit(size).Offset((page - 1) * size).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ReportFinalized implements report.FinalizeHook. It books the spare parts of a finalized
+// report against the lead technician's van, or the default warehouse when the technician
+// has no van. Only the difference to earlier postings for the report is booked, so a report
+// that is reopened and finalized again does not consume its parts twice.
+// Part numbers that are not in the catalog are skipped.
+func (s *Service) ReportFinalized(tx *gorm.DB, r *report.ServiceReport, actorID uint64) error {
+	var used []partUsage
+	if err := tx.Model(&report.ReportSparePart{}).
+		Select("part_no, SUM(qty) AS qty").
+		Where("report_id = ?", r.ID).
+		Group("part_no").
+		Scan(&used).Error; err != nil {
+		return err
+	}
+
+	var posted []postedUsage
+	if err := tx.Model(&LedgerEntry{}).
+		Select("part_id, location_id, SUM(qty) AS qty").
+		Where("report_id = ? AND kind = ?", r.ID, EntryConsumption).
+		Group("part_id, location_id").
+		Scan(&posted).Error; err != nil {
+		return err
+	}
+	if len(used) == 0 && len(posted) == 0 {
+		return nil
+	}
+
+	location, err := consumptionLocation(tx, r.TeknisiID)
+	if err != nil {
+		return err
+	}
+
+	partNos := make([]string, 0, len(used))
+	for _, u := range used {
+		partNos = append(partNos, NormalizePartNo(u.PartNo))
+	}
+	var parts []Part
+	if len(partNos) > 0 {
+		if err := tx.Where("part_no IN ?", partNos).Find(&parts).Error; err != nil {
+			return err
+		}
+	}
+	partIDs := make(map[string]uint64, len(parts))
+	for _, p := range parts {
+		partIDs[p.PartNo] = p.ID
+	}
+
+	var locationID uint64
+	if location != nil {
+		locationID = location.ID
+	} else if len(parts) > 0 {
+		log.Printf("inventory: report %d: no stock location for its parts", r.ID)
+	}
+
+	reportID := r.ID
+	for _, d := range consumptionDeltas(used, partIDs, locationID, posted) {
+		if _, err := post(tx, LedgerEntry{
+			PartID:     d.part,
+			LocationID: d.location,
+			Kind:       EntryConsumption,
+			Qty:        d.qty,
+			ReportID:   &reportID,
+			ActorID:    actorID,
+			Reason:     fmt.Sprintf("Report %s", r.DispatchNo),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partUsage is the summed quantity of one part number on a report.
+type partUsage struct {
+	PartNo string
+	Qty    float64
+}
+
+// postedUsage is the summed consumption already booked for a report at one location.
+type postedUsage struct {
+	PartID     uint64
+	LocationID uint64
+	Qty        float64
+}
+
+type stockDelta struct {
+	part, location uint64
+	qty            float64
+}
+
+// qtyScale sets the precision of booked quantities to four decimals. Sums of fractional
+// quantities are rounded to it, so 0.1+0.2 against a posted 0.3 is no change.
+const qtyScale = 1e4
+
+func roundQty(qty float64) float64 {
+	return math.Round(qty*qtyScale) / qtyScale
+}
+
+// consumptionDeltas returns the consumption entries that bring what was posted for a
+// report to what it uses now, booked at locationID. Part numbers missing from partIDs are
+// skipped, as is all usage when locationID is 0. Postings for parts no longer used are
+// reversed. Entries are sorted by part, then location, so that concurrent finalizations
+// lock stock rows in the same order.
+func consumptionDeltas(used []partUsage, partIDs map[string]uint64, locationID uint64, posted []postedUsage) []stockDelta {
+	type key struct{ part, location uint64 }
+	want := map[key]float64{}
+	for _, u := range used {
+		id, ok := partIDs[NormalizePartNo(u.PartNo)]
+		if !ok || locationID == 0 {
+			continue
+		}
+		want[key{id, locationID}] -= u.Qty
+	}
+	have := map[key]float64{}
+	for _, p := range posted {
+		k := key{p.PartID, p.LocationID}
+		have[k] += p.Qty
+		if _, ok := want[k]; !ok {
+			want[k] = 0
+		}
+	}
+
+	var out []stockDelta
+	for k, qty := range want {
+		if delta := roundQty(qty - have[k]); delta != 0 {
+			out = append(out, stockDelta{part: k.part, location: k.location, qty: delta})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].part != out[j].part {
+			return out[i].part < out[j].part
+		}
+		return out[i].location < out[j].location
+	})
+	return out
+}
+
+// consumptionLocation picks the van of teknisiID, falling back to the default warehouse.
+func consumptionLocation(tx *gorm.DB, teknisiID *uint64) (*StockLocation, error) {
+	var location StockLocation
+	if teknisiID != nil {
+		err := tx.Where("kind = ? AND teknisi_id = ?", LocationVan, *teknisiID).Order("id ASC").First(&location).Error
+		if err == nil {
+			return &location, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	err := tx.Where("is_default = ?", true).First(&location).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func ensureRefs(tx *gorm.DB, partID, locationID uint64) error {
+	if err := tx.Select("id").First(&Part{}, partID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPartNotFound
+		}
+		return err
+	}
+	if err := tx.Select("id").First(&StockLocation{}, locationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLocationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// lockStock returns the stock row for part and location, creating it if needed,
+// and holds a row lock until tx ends.
+func lockStock(tx *gorm.DB, partID, locationID uint64) (*Stock, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Stock{PartID: partID, LocationID: locationID}).Error; err != nil {
+		return nil, err
+	}
+	var stock Stock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("part_id = ? AND location_id = ?", partID, locationID).
+		First(&stock).Error; err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// post applies entry.Qty to the stock row and appends the ledger entry.
+func post(tx *gorm.DB, entry LedgerEntry) (*LedgerEntry, error) {
+	stock, err := lockStock(tx, entry.PartID, entry.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	stock.Qty += entry.Qty
+	if err := tx.Model(stock).Update("qty", stock.Qty).Error; err != nil {
+		return nil, err
+	}
+	entry.BalanceAfter = stock.Qty
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
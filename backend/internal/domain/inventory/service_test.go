@@ -0,0 +1,91 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsumptionDeltas(t *testing.T) {
+	const van, warehouse = 7, 1
+	partIDs := map[string]uint64{"FLT-01": 10, "BAT-12V": 20, "SEN-02": 30}
+	tests := []struct {
+		name     string
+		used     []partUsage
+		location uint64
+		posted   []postedUsage
+		want     []stockDelta
+	}{
+		{
+			name:     "first finalization",
+			used:     []partUsage{{"SEN-02", 1}, {" flt-01 ", 2}, {"BAT-12V", 1}},
+			location: van,
+			want:     []stockDelta{{10, van, -2}, {20, van, -1}, {30, van, -1}},
+		},
+		{
+			name:     "finalized again unchanged",
+			used:     []partUsage{{"FLT-01", 2}},
+			location: van,
+			posted:   []postedUsage{{10, van, -2}},
+		},
+		{
+			name:     "finalized again with more",
+			used:     []partUsage{{"FLT-01", 3}},
+			location: van,
+			posted:   []postedUsage{{10, van, -2}},
+			want:     []stockDelta{{10, van, -1}},
+		},
+		{
+			name:     "part removed after reopen",
+			used:     []partUsage{{"BAT-12V", 1}},
+			location: van,
+			posted:   []postedUsage{{10, van, -2}, {20, van, -1}},
+			want:     []stockDelta{{10, van, 2}},
+		},
+		{
+			name:     "technician changed vans",
+			used:     []partUsage{{"FLT-01", 2}},
+			location: warehouse,
+			posted:   []postedUsage{{10, van, -2}},
+			want:     []stockDelta{{10, warehouse, -2}, {10, van, 2}},
+		},
+		{
+			name:     "unknown part numbers are skipped",
+			used:     []partUsage{{"XYZ-99", 4}, {"", 1}},
+			location: van,
+		},
+		{
+			name:   "no stock location",
+			used:   []partUsage{{"FLT-01", 2}},
+			posted: []postedUsage{{10, van, -2}},
+			want:   []stockDelta{{10, van, 2}},
+		},
+		{
+			name:     "fractional sums",
+			used:     []partUsage{{"FLT-01", 0.1 + 0.2}},
+			location: van,
+			posted:   []postedUsage{{10, van, -0.3}},
+		},
+	}
+	for _, tt := range tests {
+		got := consumptionDeltas(tt.used, partIDs, tt.location, tt.posted)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: deltas = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRoundQty(t *testing.T) {
+	tests := map[float64]float64{
+		0.1 + 0.2 - 0.3: 0,
+		1.23456:         1.2346,
+		-2.5:            -2.5,
+	}
+	for in, want := range tests {
+		if got := roundQty(in); got != want {
+			t.Errorf("roundQty(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PlanRequest creates or replaces a PM plan. Either DeviceID or PartnerLocationID is required.
+// Calendar plans need FirstDueAt; usage plans need UsageInterval.
+type PlanRequest struct {
+	Name              string          `json:"name" binding:"required"`
+	DeviceID          *uint64         `json:"device_id"`
+	PartnerLocationID *uint64         `json:"partner_location_id"`
+	CustomerID        *uint64         `json:"customer_id"`
+	ContactID         *uint64         `json:"contact_id"`
+	TeknisiID         *uint64         `json:"teknisi_id"`
+	IntervalKind      string          `json:"interval_kind" binding:"required,oneof=monthly quarterly semiannual yearly days usage"`
+	IntervalDays      int             `json:"interval_days" binding:"omitempty,min=1"`
+	UsageInterval     float64         `json:"usage_interval" binding:"omitempty,gt=0"`
+	UsageUnit         string          `json:"usage_unit"`
+	LeadDays          *int            `json:"lead_days" binding:"omitempty,min=0,max=90"`
+	FirstDueAt        *time.Time      `json:"first_due_at"`
+	Checklist         json.RawMessage `json:"checklist"`
+	Active            *bool           `json:"active"`
+}
+
+// UsageRequest records a usage meter reading for a usage-based plan.
+type UsageRequest struct {
+	Reading float64 `json:"reading" binding:"min=0"`
+}
+
+// ChecklistItem is one line of a PM checklist template.
+type ChecklistItem struct {
+	Item     string `json:"item"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// OverdueItem is a PM cycle past its due date without a finished report.
+type OverdueItem struct {
+	PlanID            uint64     `json:"plan_id"`
+	PlanName          string     `json:"plan_name"`
+	DeviceID          *uint64    `json:"device_id"`
+	PartnerLocationID *uint64    `json:"partner_location_id"`
+	DueAt             time.Time  `json:"due_at"`
+	DaysOverdue       int        `json:"days_overdue"`
+	ReportID          *uint64    `json:"report_id,omitempty"`
+	DispatchNo        string     `json:"dispatch_no,omitempty"`
+	ReportStatus      string     `json:"report_status,omitempty"`
+	GeneratedAt       *time.Time `json:"generated_at,omitempty"`
+}
+
+// PlanQuery filters the plan list.
+type PlanQuery struct {
+	DeviceID          uint64 `form:"device_id"`
+	PartnerLocationID uint64 `form:"partner_id"`
+	Active            *bool  `form:"active"`
+}
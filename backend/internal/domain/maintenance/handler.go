@@ -0,0 +1,156 @@
+package maintenance
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/partner"
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes preventive maintenance endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type planURI struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+func (h *Handler) ListPlans(c *gin.Context) {
+	var q PlanQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	plans, err := h.svc.ListPlans(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, plans)
+}
+
+func (h *Handler) GetPlan(c *gin.Context) {
+	var uri planURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	plan, err := h.svc.GetPlan(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, plan)
+}
+
+func (h *Handler) CreatePlan(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	plan, err := h.svc.CreatePlan(c.Request.Context(), c.GetUint64("userID"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, plan)
+}
+
+func (h *Handler) UpdatePlan(c *gin.Context) {
+	var uri planURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	plan, err := h.svc.UpdatePlan(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, plan)
+}
+
+func (h *Handler) DeletePlan(c *gin.Context) {
+	var uri planURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeletePlan(c.Request.Context(), uri.ID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *Handler) RecordUsage(c *gin.Context) {
+	var uri planURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req UsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	plan, err := h.svc.RecordUsage(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, plan)
+}
+
+func (h *Handler) Visits(c *gin.Context) {
+	var uri planURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	visits, err := h.svc.Visits(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, visits)
+}
+
+func (h *Handler) Overdue(c *gin.Context) {
+	items, err := h.svc.Overdue(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, items)
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrPlanNotFound):
+		response.NotFound(c, "pm plan not found")
+	case errors.Is(err, report.ErrDeviceNotFound):
+		response.NotFound(c, "device not found")
+	case errors.Is(err, partner.ErrPartnerNotFound):
+		response.NotFound(c, "partner not found")
+	case errors.Is(err, ErrPlanTarget), errors.Is(err, ErrPlanInterval), errors.Is(err, ErrPlanFirstDue),
+		errors.Is(err, ErrInvalidChecklist), errors.Is(err, ErrNotUsagePlan), errors.Is(err, ErrPlanAddress):
+		response.UnprocessableEntity(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,58 @@
+package maintenance
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Plan interval kinds.
+const (
+	IntervalMonthly    = "monthly"
+	IntervalQuarterly  = "quarterly"
+	IntervalSemiannual = "semiannual"
+	IntervalYearly     = "yearly"
+	IntervalDays       = "days"
+	IntervalUsage      = "usage"
+)
+
+// PMPlan schedules recurring preventive maintenance for a device or for a whole partner site.
+// Calendar plans are due at NextDueAt and month based intervals keep to AnchorDay, the day of
+// month of the first due date. Usage plans are due once CurrentUsage has grown UsageInterval
+// past LastPMUsage; their NextDueAt is only set while a cycle that failed part way waits to
+// be retried.
+type PMPlan struct {
+	ID                uint64         `gorm:"primaryKey" json:"id"`
+	Name              string         `gorm:"size:150" json:"name"`
+	DeviceID          *uint64        `gorm:"index" json:"device_id"`
+	PartnerLocationID *uint64        `gorm:"index" json:"partner_location_id"`
+	CustomerID        *uint64        `json:"customer_id"`
+	ContactID         *uint64        `json:"contact_id"`
+	TeknisiID         *uint64        `json:"teknisi_id"`
+	IntervalKind      string         `gorm:"size:16" json:"interval_kind"`
+	IntervalDays      int            `json:"interval_days"`
+	UsageInterval     float64        `json:"usage_interval"`
+	UsageUnit         string         `gorm:"size:32" json:"usage_unit"`
+	CurrentUsage      float64        `json:"current_usage"`
+	LastPMUsage       float64        `json:"last_pm_usage"`
+	LeadDays          int            `json:"lead_days"`
+	NextDueAt         *time.Time     `gorm:"index" json:"next_due_at"`
+	AnchorDay         int            `json:"anchor_day"`
+	Checklist         datatypes.JSON `gorm:"type:json" json:"checklist"`
+	Active            bool           `gorm:"index" json:"active"`
+	CreatedBy         uint64         `json:"created_by"`
+	LastGeneratedAt   *time.Time     `json:"last_generated_at"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PMVisit links a plan cycle to the report generated for one of its devices. A cycle has at
+// most one visit per device, so a cycle retried after a failure only opens the missing ones.
+type PMVisit struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	PlanID    uint64    `gorm:"index;uniqueIndex:idx_pm_visit_cycle,priority:1" json:"plan_id"`
+	DeviceID  uint64    `gorm:"uniqueIndex:idx_pm_visit_cycle,priority:3" json:"device_id"`
+	ReportID  uint64    `gorm:"uniqueIndex" json:"report_id"`
+	DueAt     time.Time `gorm:"index;uniqueIndex:idx_pm_visit_cycle,priority:2" json:"due_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package maintenance
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/company/internal-service-report/pkg/schedule"
+)
+
+// Run generates due PM reports every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	schedule.Every(ctx, interval, func(ctx context.Context) {
+		if n, err := s.GenerateDue(ctx); err != nil {
+			log.Printf("pm: scheduler run failed: %v", err)
+		} else if n > 0 {
+			log.Printf("pm: opened %d preventive maintenance reports", n)
+		}
+	})
+}
@@ -0,0 +1,573 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/asset"
+	"github.com/company/internal-service-report/internal/domain/partner"
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+var (
+	ErrPlanNotFound     = errors.New("pm plan not found")
+	ErrPlanTarget       = errors.New("pm plan needs a device_id or a partner_location_id")
+	ErrPlanInterval     = errors.New("interval_days is required for a days interval and usage_interval for a usage interval")
+	ErrPlanFirstDue     = errors.New("first_due_at is required for calendar intervals")
+	ErrInvalidChecklist = errors.New("checklist must be a list of items with a non-empty item text")
+	ErrNotUsagePlan     = errors.New("usage readings only apply to usage based plans")
+	ErrPlanAddress      = errors.New("pm plan without a customer_id needs a partner or device with an address for the report's customer details")
+)
+
+// Service manages preventive maintenance plans and generates their reports.
+type Service struct {
+	db      *gorm.DB
+	reports *report.Service
+	now     func() time.Time
+}
+
+func NewService(db *gorm.DB, reports *report.Service) *Service {
+	return &Service{db: db, reports: reports, now: time.Now}
+}
+
+func (s *Service) ListPlans(ctx context.Context, q PlanQuery) ([]PMPlan, error) {
+	query := s.db.WithContext(ctx).Model(&PMPlan{})
+	if q.DeviceID != 0 {
+		query = query.Where("device_id = ?", q.DeviceID)
+	}
+	if q.PartnerLocationID != 0 {
+		query = query.Where("partner_location_id = ?", q.PartnerLocationID)
+	}
+	if q.Active != nil {
+		query = query.Where("active = ?", *q.Active)
+	}
+	var plans []PMPlan
+	if err := query.Order("next_due_at IS NULL, next_due_at ASC, id ASC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (s *Service) GetPlan(ctx context.Context, id uint64) (*PMPlan, error) {
+	var plan PMPlan
+	if err := s.db.WithContext(ctx).First(&plan, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (s *Service) CreatePlan(ctx context.Context, adminID uint64, req PlanRequest) (*PMPlan, error) {
+	plan := &PMPlan{CreatedBy: adminID, Active: true, LeadDays: 7}
+	if err := s.applyRequest(ctx, plan, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(plan).Error; err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan replaces a plan's settings. Usage counters are kept so that a plan switched
+// between usage intervals does not lose its last PM reading.
+func (s *Service) UpdatePlan(ctx context.Context, id uint64, req PlanRequest) (*PMPlan, error) {
+	plan, err := s.GetPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(ctx, plan, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(plan).Error; err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// DeletePlan removes a plan. Reports already generated for it are kept.
+func (s *Service) DeletePlan(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&PMPlan{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPlanNotFound
+		}
+		return tx.Where("plan_id = ?", id).Delete(&PMVisit{}).Error
+	})
+}
+
+func (s *Service) applyRequest(ctx context.Context, plan *PMPlan, req PlanRequest) error {
+	if req.DeviceID == nil && req.PartnerLocationID == nil {
+		return ErrPlanTarget
+	}
+	switch req.IntervalKind {
+	case IntervalDays:
+		if req.IntervalDays <= 0 {
+			return ErrPlanInterval
+		}
+	case IntervalUsage:
+		if req.UsageInterval <= 0 {
+			return ErrPlanInterval
+		}
+	}
+	if req.IntervalKind != IntervalUsage && req.FirstDueAt == nil && plan.NextDueAt == nil {
+		return ErrPlanFirstDue
+	}
+	checklist, err := normalizeChecklist(req.Checklist)
+	if err != nil {
+		return err
+	}
+
+	db := s.db.WithContext(ctx)
+	var device *asset.Device
+	if req.DeviceID != nil {
+		device = &asset.Device{}
+		if err := db.First(device, *req.DeviceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return report.ErrDeviceNotFound
+			}
+			return err
+		}
+	}
+	siteID := req.PartnerLocationID
+	if siteID == nil && device != nil {
+		siteID = device.PartnerLocationID
+	}
+	var site *partner.PartnerLocation
+	if siteID != nil {
+		site = &partner.PartnerLocation{}
+		if err := db.First(site, *siteID).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if req.PartnerLocationID != nil {
+				return partner.ErrPartnerNotFound
+			}
+			site = nil
+		}
+	}
+	if req.CustomerID == nil && req.ContactID == nil {
+		// A partner plan falls back to each device's location when the site has no address,
+		// but only the site address is known to be there for every device.
+		if device == nil && (site == nil || strings.TrimSpace(site.Address) == "") {
+			return ErrPlanAddress
+		}
+		if device != nil && siteCustomer(site, device).Address == "" {
+			return ErrPlanAddress
+		}
+	}
+
+	plan.Name = strings.TrimSpace(req.Name)
+	plan.DeviceID = req.DeviceID
+	plan.PartnerLocationID = req.PartnerLocationID
+	plan.CustomerID = req.CustomerID
+	plan.ContactID = req.ContactID
+	plan.TeknisiID = req.TeknisiID
+	plan.IntervalKind = req.IntervalKind
+	plan.IntervalDays = req.IntervalDays
+	plan.UsageInterval = req.UsageInterval
+	plan.UsageUnit = strings.TrimSpace(req.UsageUnit)
+	plan.Checklist = checklist
+	if req.LeadDays != nil {
+		plan.LeadDays = *req.LeadDays
+	}
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	if req.IntervalKind == IntervalUsage {
+		plan.NextDueAt = nil
+	} else if req.FirstDueAt != nil {
+		due := *req.FirstDueAt
+		plan.NextDueAt = &due
+		plan.AnchorDay = due.Day()
+	}
+	return nil
+}
+
+// normalizeChecklist accepts a list of strings or of {item, required} objects.
+func normalizeChecklist(raw json.RawMessage) (datatypes.JSON, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return datatypes.JSON("[]"), nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, ErrInvalidChecklist
+	}
+	items := make([]ChecklistItem, 0, len(entries))
+	for _, entry := range entries {
+		var item ChecklistItem
+		var text string
+		if err := json.Unmarshal(entry, &text); err == nil {
+			item.Item = text
+		} else if err := json.Unmarshal(entry, &item); err != nil {
+			return nil, ErrInvalidChecklist
+		}
+		item.Item = strings.TrimSpace(item.Item)
+		if item.Item == "" {
+			return nil, ErrInvalidChecklist
+		}
+		items = append(items, item)
+	}
+	out, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(out), nil
+}
+
+// RecordUsage stores a meter reading on a usage based plan. The next scheduler run opens a
+// report once the reading has grown UsageInterval past the reading of the last PM.
+func (s *Service) RecordUsage(ctx context.Context, id uint64, req UsageRequest) (*PMPlan, error) {
+	plan, err := s.GetPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if plan.IntervalKind != IntervalUsage {
+		return nil, ErrNotUsagePlan
+	}
+	plan.CurrentUsage = req.Reading
+	if err := s.db.WithContext(ctx).Model(plan).Update("current_usage", req.Reading).Error; err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Visits lists the reports generated for a plan, newest first.
+func (s *Service) Visits(ctx context.Context, planID uint64) ([]PMVisit, error) {
+	if _, err := s.GetPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	var visits []PMVisit
+	if err := s.db.WithContext(ctx).Where("plan_id = ?", planID).Order("due_at DESC, id DESC").Find(&visits).Error; err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+// Overdue lists PM cycles past their due date: generated reports that are not done or
+// cancelled yet, and active calendar plans whose report has not been generated at all.
+func (s *Service) Overdue(ctx context.Context) ([]OverdueItem, error) {
+	now := s.now()
+	items := []OverdueItem{}
+
+	var visits []struct {
+		PMVisit
+		PlanName          string
+		DeviceID          *uint64
+		PartnerLocationID *uint64
+		DispatchNo        string
+		Status            string
+	}
+	err := s.db.WithContext(ctx).Table("pm_visits AS v").
+		Select("v.*, p.name AS plan_name, p.device_id, p.partner_location_id, r.dispatch_no, r.status").
+		Joins("JOIN pm_plans AS p ON p.id = v.plan_id").
+		Joins("JOIN service_reports AS r ON r.id = v.report_id").
+		Where("v.due_at < ? AND r.status NOT IN ?", now, []string{report.StatusDone, report.StatusCancelled}).
+		Order("v.due_at ASC").
+		Scan(&visits).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range visits {
+		reportID := v.ReportID
+		generated := v.CreatedAt
+		items = append(items, OverdueItem{
+			PlanID:            v.PlanID,
+			PlanName:          v.PlanName,
+			DeviceID:          v.DeviceID,
+			PartnerLocationID: v.PartnerLocationID,
+			DueAt:             v.DueAt,
+			DaysOverdue:       daysBetween(v.DueAt, now),
+			ReportID:          &reportID,
+			DispatchNo:        v.DispatchNo,
+			ReportStatus:      v.Status,
+			GeneratedAt:       &generated,
+		})
+	}
+
+	var plans []PMPlan
+	if err := s.db.WithContext(ctx).
+		Where("active = ? AND interval_kind <> ? AND next_due_at < ?", true, IntervalUsage, now).
+		Order("next_due_at ASC").
+		Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range plans {
+		items = append(items, OverdueItem{
+			PlanID:            p.ID,
+			PlanName:          p.Name,
+			DeviceID:          p.DeviceID,
+			PartnerLocationID: p.PartnerLocationID,
+			DueAt:             *p.NextDueAt,
+			DaysOverdue:       daysBetween(*p.NextDueAt, now),
+		})
+	}
+	return items, nil
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// nextDue advances a calendar plan by one interval. Month based intervals land on the plan's
+// anchor day, or the last day of shorter months, so a plan due on the 31st stays at month end.
+func nextDue(plan *PMPlan, from time.Time) time.Time {
+	switch plan.IntervalKind {
+	case IntervalMonthly:
+		return addMonths(from, 1, plan.AnchorDay)
+	case IntervalQuarterly:
+		return addMonths(from, 3, plan.AnchorDay)
+	case IntervalSemiannual:
+		return addMonths(from, 6, plan.AnchorDay)
+	case IntervalYearly:
+		return addMonths(from, 12, plan.AnchorDay)
+	default:
+		return from.AddDate(0, 0, plan.IntervalDays)
+	}
+}
+
+// addMonths moves t by months to the given day of month, clamped to the length of the target
+// month. A day of 0 keeps the day of t, for plans saved before the anchor day was recorded.
+func addMonths(t time.Time, months, day int) time.Time {
+	if day <= 0 {
+		day = t.Day()
+	}
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// calendarCycle reports whether a calendar plan's cycle at NextDueAt is open for generation at
+// now, which is LeadDays before it is due, and the due date the plan moves on to. Cycles
+// missed while the scheduler was down are skipped so that next lies after now.
+func calendarCycle(plan *PMPlan, now time.Time) (next time.Time, ok bool) {
+	if plan.NextDueAt == nil || now.Before(plan.NextDueAt.AddDate(0, 0, -plan.LeadDays)) {
+		return time.Time{}, false
+	}
+	next = nextDue(plan, *plan.NextDueAt)
+	for !next.After(now) {
+		next = nextDue(plan, next)
+	}
+	return next, true
+}
+
+// GenerateDue opens reports for every active plan that is due. Calendar plans are due
+// LeadDays before NextDueAt; usage plans once their reading has grown past the interval.
+// Each plan is claimed by moving its due marker first, so concurrent runs never open the
+// same cycle twice. When some devices fail the claim is handed back and the next run opens
+// only the devices that have no visit for the cycle yet. It returns the number of reports
+// created.
+func (s *Service) GenerateDue(ctx context.Context) (int, error) {
+	now := s.now()
+	var plans []PMPlan
+	if err := s.db.WithContext(ctx).Where("active = ?", true).Find(&plans).Error; err != nil {
+		return 0, err
+	}
+	created := 0
+	for i := range plans {
+		plan := &plans[i]
+		n, err := s.generatePlan(ctx, plan, now)
+		created += n
+		if err != nil {
+			log.Printf("pm: plan %d: %v", plan.ID, err)
+		}
+	}
+	return created, nil
+}
+
+func (s *Service) generatePlan(ctx context.Context, plan *PMPlan, now time.Time) (int, error) {
+	if plan.IntervalKind == IntervalUsage {
+		if plan.UsageInterval <= 0 || plan.CurrentUsage-plan.LastPMUsage < plan.UsageInterval {
+			return 0, nil
+		}
+		dueAt := now.Truncate(time.Second)
+		if plan.NextDueAt != nil {
+			// A cycle that failed part way is retried under its first due date.
+			dueAt = *plan.NextDueAt
+		}
+		claimed, err := s.moveCycle(ctx, plan.ID,
+			map[string]interface{}{"last_pm_usage": plan.LastPMUsage},
+			map[string]interface{}{"last_pm_usage": plan.CurrentUsage, "next_due_at": nil, "last_generated_at": now})
+		if !claimed {
+			return 0, err
+		}
+		n, err := s.openReports(ctx, plan, dueAt)
+		if err != nil {
+			err = s.releaseCycle(ctx, plan.ID, err,
+				map[string]interface{}{"last_pm_usage": plan.CurrentUsage},
+				map[string]interface{}{"last_pm_usage": plan.LastPMUsage, "next_due_at": dueAt})
+		}
+		return n, err
+	}
+
+	next, ok := calendarCycle(plan, now)
+	if !ok {
+		return 0, nil
+	}
+	dueAt := *plan.NextDueAt
+	claimed, err := s.moveCycle(ctx, plan.ID,
+		map[string]interface{}{"next_due_at": dueAt},
+		map[string]interface{}{"next_due_at": next, "last_generated_at": now})
+	if !claimed {
+		return 0, err
+	}
+	n, err := s.openReports(ctx, plan, dueAt)
+	if err != nil {
+		err = s.releaseCycle(ctx, plan.ID, err,
+			map[string]interface{}{"next_due_at": next},
+			map[string]interface{}{"next_due_at": dueAt})
+	}
+	return n, err
+}
+
+// moveCycle updates a plan's cycle columns from one state to another and reports whether
+// this run made the change.
+func (s *Service) moveCycle(ctx context.Context, planID uint64, from, to map[string]interface{}) (bool, error) {
+	where := map[string]interface{}{"id": planID}
+	for column, value := range from {
+		where[column] = value
+	}
+	result := s.db.WithContext(ctx).Model(&PMPlan{}).Where(where).Updates(to)
+	return result.Error == nil && result.RowsAffected > 0, result.Error
+}
+
+// releaseCycle hands a claimed cycle back after openReports failed so the next run retries it.
+func (s *Service) releaseCycle(ctx context.Context, planID uint64, cause error, claimed, restore map[string]interface{}) error {
+	if _, err := s.moveCycle(ctx, planID, claimed, restore); err != nil {
+		return fmt.Errorf("%w; cycle not released for a retry: %v", cause, err)
+	}
+	return cause
+}
+
+// openReports creates the PM reports for one plan cycle: one for a device plan, or one per
+// registered device for a partner plan. Devices that already have a visit for the cycle are
+// skipped, and a failing device does not keep the others from being opened.
+func (s *Service) openReports(ctx context.Context, plan *PMPlan, dueAt time.Time) (int, error) {
+	var devices []asset.Device
+	query := s.db.WithContext(ctx)
+	if plan.DeviceID != nil {
+		query = query.Where("id = ?", *plan.DeviceID)
+	} else {
+		query = query.Where("partner_location_id = ?", *plan.PartnerLocationID)
+	}
+	if err := query.Order("id ASC").Find(&devices).Error; err != nil {
+		return 0, err
+	}
+	if len(devices) == 0 {
+		return 0, report.ErrDeviceNotFound
+	}
+
+	var site *partner.PartnerLocation
+	partnerID := plan.PartnerLocationID
+	if partnerID == nil {
+		partnerID = devices[0].PartnerLocationID
+	}
+	if partnerID != nil {
+		var p partner.PartnerLocation
+		if err := s.db.WithContext(ctx).First(&p, *partnerID).Error; err == nil {
+			site = &p
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+
+	var visited []uint64
+	if err := s.db.WithContext(ctx).Model(&PMVisit{}).
+		Where("plan_id = ? AND due_at = ?", plan.ID, dueAt).
+		Pluck("device_id", &visited).Error; err != nil {
+		return 0, err
+	}
+	opened := make(map[uint64]bool, len(visited))
+	for _, id := range visited {
+		opened[id] = true
+	}
+
+	created := 0
+	var errs []error
+	for i := range devices {
+		if opened[devices[i].ID] {
+			continue
+		}
+		if err := s.openReport(ctx, plan, &devices[i], site, dueAt); err != nil {
+			errs = append(errs, fmt.Errorf("device %d: %w", devices[i].ID, err))
+			continue
+		}
+		created++
+	}
+	return created, errors.Join(errs...)
+}
+
+func (s *Service) openReport(ctx context.Context, plan *PMPlan, device *asset.Device, site *partner.PartnerLocation, dueAt time.Time) error {
+	var checklist []ChecklistItem
+	_ = json.Unmarshal(plan.Checklist, &checklist)
+	payload := map[string]interface{}{
+		"jobInfo":     []string{"Preventive Maintenance"},
+		"pmPlanId":    plan.ID,
+		"pmDueDate":   dueAt.Format("2006-01-02"),
+		"pmChecklist": checklist,
+		"deviceRows": []map[string]string{{
+			"description": device.Name,
+			"serialNo":    device.SerialNumber,
+			"location":    device.Location,
+		}},
+	}
+	req := report.CreateReportRequest{
+		CustomerID: plan.CustomerID,
+		ContactID:  plan.ContactID,
+		PartnerID:  plan.PartnerLocationID,
+		Device:     report.DeviceInfo{ID: &device.ID},
+		Complaint:  fmt.Sprintf("Preventive maintenance %s (due %s)", plan.Name, dueAt.Format("2006-01-02")),
+	}
+	if plan.CustomerID == nil {
+		req.Customer = siteCustomer(site, device)
+		payload["customerName"] = req.Customer.Name
+		payload["address"] = req.Customer.Address
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req.FormPayload = raw
+
+	created, err := s.reports.CreateLinked(ctx, plan.CreatedBy, req, func(tx *gorm.DB, r *report.ServiceReport) error {
+		return tx.Create(&PMVisit{PlanID: plan.ID, DeviceID: device.ID, ReportID: r.ID, DueAt: dueAt}).Error
+	})
+	if err != nil {
+		return err
+	}
+	if plan.TeknisiID != nil {
+		if _, err := s.reports.Assign(ctx, created.ID, plan.CreatedBy, report.AssignRequest{TeknisiID: *plan.TeknisiID}); err != nil {
+			log.Printf("pm: report %d left unassigned: %v", created.ID, err)
+		}
+	}
+	return nil
+}
+
+// siteCustomer is the free-text customer of a PM report for a plan without a registry
+// customer: the partner site, or the device itself when it has no site. The address falls
+// back to the device location.
+func siteCustomer(site *partner.PartnerLocation, device *asset.Device) report.CustomerInfo {
+	c := report.CustomerInfo{Name: device.Name, Contact: "-"}
+	if site != nil {
+		c.Name = site.HospitalName
+		c.Address = strings.TrimSpace(site.Address)
+	}
+	if c.Address == "" {
+		c.Address = strings.TrimSpace(device.Location)
+	}
+	return c
+}
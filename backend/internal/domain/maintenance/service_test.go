@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/company/internal-service-report/internal/domain/asset"
+	"github.com/company/internal-service-report/internal/domain/partner"
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+func TestSiteCustomer(t *testing.T) {
+	device := &asset.Device{Name: "Philips IntelliVue MX450", Location: "ICU Lt. 3"}
+	tests := []struct {
+		name   string
+		site   *partner.PartnerLocation
+		device *asset.Device
+		want   report.CustomerInfo
+	}{
+		{
+			name:   "site with address",
+			site:   &partner.PartnerLocation{HospitalName: "RS Citra Medika", Address: "Jl. Merdeka 1"},
+			device: device,
+			want:   report.CustomerInfo{Name: "RS Citra Medika", Address: "Jl. Merdeka 1", Contact: "-"},
+		},
+		{
+			name:   "site without address",
+			site:   &partner.PartnerLocation{HospitalName: "RS Citra Medika", Address: " "},
+			device: device,
+			want:   report.CustomerInfo{Name: "RS Citra Medika", Address: "ICU Lt. 3", Contact: "-"},
+		},
+		{
+			name:   "no site",
+			device: device,
+			want:   report.CustomerInfo{Name: "Philips IntelliVue MX450", Address: "ICU Lt. 3", Contact: "-"},
+		},
+		{
+			name:   "no site and no location",
+			device: &asset.Device{Name: "Mindray BeneHeart D3"},
+			want:   report.CustomerInfo{Name: "Mindray BeneHeart D3", Contact: "-"},
+		},
+	}
+	for _, tt := range tests {
+		if got := siteCustomer(tt.site, tt.device); got != tt.want {
+			t.Errorf("%s: siteCustomer() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNextDue(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name string
+		plan PMPlan
+		from time.Time
+		want time.Time
+	}{
+		{"monthly from the 31st", PMPlan{IntervalKind: IntervalMonthly, AnchorDay: 31}, day(2026, 1, 31), day(2026, 2, 28)},
+		{"monthly back to the anchor", PMPlan{IntervalKind: IntervalMonthly, AnchorDay: 31}, day(2026, 2, 28), day(2026, 3, 31)},
+		{"monthly into a 30 day month", PMPlan{IntervalKind: IntervalMonthly, AnchorDay: 31}, day(2026, 3, 31), day(2026, 4, 30)},
+		{"monthly leap february", PMPlan{IntervalKind: IntervalMonthly, AnchorDay: 30}, day(2028, 1, 30), day(2028, 2, 29)},
+		{"quarterly from nov 30", PMPlan{IntervalKind: IntervalQuarterly, AnchorDay: 30}, day(2025, 11, 30), day(2026, 2, 28)},
+		{"quarterly back to the anchor", PMPlan{IntervalKind: IntervalQuarterly, AnchorDay: 30}, day(2026, 2, 28), day(2026, 5, 30)},
+		{"semiannual across the year", PMPlan{IntervalKind: IntervalSemiannual, AnchorDay: 31}, day(2026, 8, 31), day(2027, 2, 28)},
+		{"yearly from feb 29", PMPlan{IntervalKind: IntervalYearly, AnchorDay: 29}, day(2028, 2, 29), day(2029, 2, 28)},
+		{"yearly back to feb 29", PMPlan{IntervalKind: IntervalYearly, AnchorDay: 29}, day(2031, 2, 28), day(2032, 2, 29)},
+		{"no anchor keeps the day", PMPlan{IntervalKind: IntervalMonthly}, day(2026, 1, 15), day(2026, 2, 15)},
+		{"days interval", PMPlan{IntervalKind: IntervalDays, IntervalDays: 45}, day(2026, 1, 31), day(2026, 3, 17)},
+	}
+	for _, tt := range tests {
+		if got := nextDue(&tt.plan, tt.from); !got.Equal(tt.want) {
+			t.Errorf("%s: nextDue(%s) = %s, want %s", tt.name, tt.from.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestCalendarCycle(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 8, 0, 0, 0, time.Local) }
+	at := func(t time.Time) *time.Time { return &t }
+	monthly := func(due time.Time, lead int) *PMPlan {
+		return &PMPlan{IntervalKind: IntervalMonthly, AnchorDay: due.Day(), NextDueAt: &due, LeadDays: lead}
+	}
+	tests := []struct {
+		name   string
+		plan   *PMPlan
+		now    time.Time
+		wantOK bool
+		next   time.Time
+	}{
+		{"before the lead window", monthly(day(3, 31), 7), day(3, 23), false, time.Time{}},
+		{"first day of the lead window", monthly(day(3, 31), 7), day(3, 24), true, day(4, 30)},
+		{"on the due date", monthly(day(3, 31), 0), day(3, 31), true, day(4, 30)},
+		{"missed cycles are skipped", monthly(day(1, 31), 7), day(5, 2), true, day(5, 31)},
+		{"next due equal to now is skipped", monthly(day(1, 15), 0), day(2, 15), true, day(3, 15)},
+		{"no due date", &PMPlan{IntervalKind: IntervalMonthly, LeadDays: 7}, day(3, 1), false, time.Time{}},
+		{"days interval", &PMPlan{IntervalKind: IntervalDays, IntervalDays: 10, NextDueAt: at(day(3, 10)), LeadDays: 3}, day(3, 8), true, day(3, 20)},
+	}
+	for _, tt := range tests {
+		next, ok := calendarCycle(tt.plan, tt.now)
+		if ok != tt.wantOK || !next.Equal(tt.next) {
+			t.Errorf("%s: calendarCycle = %s %v, want %s %v", tt.name, next.Format("2006-01-02"), ok, tt.next.Format("2006-01-02"), tt.wantOK)
+		}
+	}
+}
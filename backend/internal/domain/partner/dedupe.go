@@ -0,0 +1,182 @@
+package partner
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+var (
+	ErrDuplicatePartner = errors.New("a partner with the same hospital name already exists in this province")
+	ErrMergeSelf        = errors.New("a partner cannot be merged into itself")
+)
+
+// hospitalPrefixes are spelled-out forms folded into their common abbreviation.
+var hospitalPrefixes = []struct{ long, short string }{
+	{"rumah sakit umum daerah", "rsud"},
+	{"rumah sakit umum", "rsu"},
+	{"rumah sakit", "rs"},
+}
+
+// NormalizeHospitalName folds case, punctuation, spacing and common prefixes so that
+// "RS. Citra  Medika" and "Rumah Sakit Citra Medika" compare equal.
+func NormalizeHospitalName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	out := strings.Join(fields, " ")
+	for _, p := range hospitalPrefixes {
+		if out == p.long || strings.HasPrefix(out, p.long+" ") {
+			out = p.short + out[len(p.long):]
+			break
+		}
+	}
+	return out
+}
+
+// DedupeKey identifies a partner for duplicate detection.
+func DedupeKey(provinceCode, hospitalName string) string {
+	return strings.ToUpper(strings.TrimSpace(provinceCode)) + "|" + NormalizeHospitalName(hospitalName)
+}
+
+// findDuplicate returns a partner in the same province whose name normalizes to the same
+// key, ignoring exceptID.
+func findDuplicate(db *gorm.DB, provinceCode, hospitalName string, exceptID uint64) (*PartnerLocation, error) {
+	var candidates []PartnerLocation
+	if err := db.Where("province_code = ? AND id <> ?", strings.TrimSpace(provinceCode), exceptID).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	key := DedupeKey(provinceCode, hospitalName)
+	for i := range candidates {
+		if DedupeKey(candidates[i].ProvinceCode, candidates[i].HospitalName) == key {
+			return &candidates[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Duplicates groups partners sharing a dedupe key. Groups are ordered by province and name.
+func (s *Service) Duplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	var partners []PartnerLocation
+	if err := s.db.WithContext(ctx).Order("id ASC").Find(&partners).Error; err != nil {
+		return nil, err
+	}
+	byKey := map[string][]PartnerLocation{}
+	for _, p := range partners {
+		key := DedupeKey(p.ProvinceCode, p.HospitalName)
+		byKey[key] = append(byKey[key], p)
+	}
+	groups := []DuplicateGroup{}
+	for key, members := range byKey {
+		if len(members) < 2 {
+			continue
+		}
+		groups = append(groups, DuplicateGroup{Key: key, ProvinceCode: members[0].ProvinceCode, Partners: members})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups, nil
+}
+
+// Merge folds sourceIDs into targetID. Reports, customers, devices, PM plans and SLA
+// policies linked to a source are moved to the target, blank target fields are filled
+// from the sources, and the sources are deleted.
+func (s *Service) Merge(ctx context.Context, targetID uint64, sourceIDs []uint64) (*MergeResult, error) {
+	result := &MergeResult{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target PartnerLocation
+		if err := tx.First(&target, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPartnerNotFound
+			}
+			return err
+		}
+		ids := make([]uint64, 0, len(sourceIDs))
+		seen := map[uint64]struct{}{}
+		for _, id := range sourceIDs {
+			if id == targetID {
+				return ErrMergeSelf
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+		var sources []PartnerLocation
+		if err := tx.Where("id IN ?", ids).Order("id ASC").Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) != len(ids) {
+			return ErrPartnerNotFound
+		}
+
+		for _, src := range sources {
+			if target.Address == "" && src.Address != "" {
+				target.Address = src.Address
+			}
+			if target.ProvinceName == "" && src.ProvinceName != "" {
+				target.ProvinceName = src.ProvinceName
+			}
+		}
+		if err := tx.Model(&target).Updates(map[string]interface{}{
+			"address":       target.Address,
+			"province_name": target.ProvinceName,
+		}).Error; err != nil {
+			return err
+		}
+
+		moved := tx.Model(&report.ServiceReport{}).Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.Reports = moved.RowsAffected
+		moved = tx.Table("customers").Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.Customers = moved.RowsAffected
+		moved = tx.Table("devices").Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.Devices = moved.RowsAffected
+		moved = tx.Table("pm_plans").Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.PMPlans = moved.RowsAffected
+		moved = tx.Table("sla_policies").Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.SLAPolicies = moved.RowsAffected
+		moved = tx.Model(&report.ReportTimesheet{}).Where("partner_location_id IN ?", ids).
+			UpdateColumn("partner_location_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.Timesheets = moved.RowsAffected
+
+		if err := tx.Delete(&PartnerLocation{}, ids).Error; err != nil {
+			return err
+		}
+		result.Partner = target
+		result.Merged = ids
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
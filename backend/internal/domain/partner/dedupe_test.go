@@ -0,0 +1,48 @@
+package partner
+
+import "testing"
+
+func TestNormalizeHospitalName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"RS Citra Medika", "rs citra medika"},
+		{"RS. Citra  Medika", "rs citra medika"},
+		{"Rumah Sakit Citra Medika", "rs citra medika"},
+		{"rumah sakit citra-medika", "rs citra medika"},
+		{"Rumah Sakit Umum Daerah Dr. Soetomo", "rsud dr soetomo"},
+		{"RSUD dr. Soetomo", "rsud dr soetomo"},
+		{"Rumah Sakit Umum Haji", "rsu haji"},
+		{"Rumah Sakit", "rs"},
+		{"Rumah Sakitan Baru", "rumah sakitan baru"},
+		{"Klinik Rumah Sakit Mata", "klinik rumah sakit mata"},
+		{"  Puskesmas   Kec. Menteng ", "puskesmas kec menteng"},
+		{"RS Siloam 2", "rs siloam 2"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHospitalName(tt.name); got != tt.want {
+			t.Errorf("NormalizeHospitalName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeKey(t *testing.T) {
+	tests := []struct {
+		provinceA, nameA string
+		provinceB, nameB string
+		same             bool
+	}{
+		{"ID-JK", "RS. Citra Medika", " id-jk ", "Rumah Sakit Citra Medika", true},
+		{"ID-JK", "RSUD Pasar Minggu", "ID-JK", "Rumah Sakit Umum Daerah Pasar Minggu", true},
+		{"ID-JK", "RS Citra Medika", "ID-JB", "RS Citra Medika", false},
+		{"ID-JK", "RSU Citra Medika", "ID-JK", "RS Citra Medika", false},
+	}
+	for _, tt := range tests {
+		a, b := DedupeKey(tt.provinceA, tt.nameA), DedupeKey(tt.provinceB, tt.nameB)
+		if (a == b) != tt.same {
+			t.Errorf("DedupeKey(%q, %q) = %q, DedupeKey(%q, %q) = %q, same = %v, want %v",
+				tt.provinceA, tt.nameA, a, tt.provinceB, tt.nameB, b, a == b, tt.same)
+		}
+	}
+}
@@ -2,9 +2,89 @@ package partner
 
 // CreatePartnerRequest describes payload to create a partner location entry.
 type CreatePartnerRequest struct {
-	ProvinceCode     string `json:"province_code" binding:"required"`
-	ProvinceName     string `json:"province_name" binding:"required"`
-	HospitalName     string `json:"hospital_name" binding:"required"`
-	Address          string `json:"address" binding:"required"`
-	MaintenanceCount int    `json:"maintenance_count" binding:"required,min=0"`
+	ProvinceCode string `json:"province_code" binding:"required"`
+	ProvinceName string `json:"province_name" binding:"required"`
+	HospitalName string `json:"hospital_name" binding:"required"`
+	Address      string `json:"address" binding:"required"`
+}
+
+// CountQuery binds the filters applied to maintenance counts.
+// Status is a comma separated list and defaults to done.
+type CountQuery struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Status string `form:"status"`
+}
+
+// ProvinceCount aggregates partners and maintenance reports of one province.
+type ProvinceCount struct {
+	ProvinceCode     string `json:"province_code"`
+	ProvinceName     string `json:"province_name"`
+	PartnerCount     int64  `json:"partner_count"`
+	MaintenanceCount int64  `json:"maintenance_count"`
+}
+
+// UpdatePartnerRequest changes partner fields; nil fields are left untouched.
+type UpdatePartnerRequest struct {
+	ProvinceCode *string `json:"province_code"`
+	ProvinceName *string `json:"province_name"`
+	HospitalName *string `json:"hospital_name"`
+	Address      *string `json:"address"`
+}
+
+// MergeRequest lists the partners folded into the target partner.
+type MergeRequest struct {
+	SourceIDs []uint64 `json:"source_ids" binding:"required,min=1"`
+}
+
+// DuplicateGroup is a set of partners that normalize to the same province and hospital name.
+type DuplicateGroup struct {
+	Key          string            `json:"key"`
+	ProvinceCode string            `json:"province_code"`
+	Partners     []PartnerLocation `json:"partners"`
+}
+
+// MergeResult reports what a merge moved onto the surviving partner.
+type MergeResult struct {
+	Partner     PartnerLocation `json:"partner"`
+	Merged      []uint64        `json:"merged_ids"`
+	Reports     int64           `json:"reports_moved"`
+	Customers   int64           `json:"customers_moved"`
+	Devices     int64           `json:"devices_moved"`
+	PMPlans     int64           `json:"pm_plans_moved"`
+	SLAPolicies int64           `json:"sla_policies_moved"`
+	Timesheets  int64           `json:"timesheets_moved"`
+}
+
+// Import row outcomes.
+const (
+	ImportCreate    = "create"
+	ImportCreated   = "created"
+	ImportDuplicate = "duplicate"
+	ImportInvalid   = "invalid"
+)
+
+// ImportRow is the outcome of one data row of an import file. Row is the 1-based line
+// number in the file, counting the header.
+type ImportRow struct {
+	Row          int      `json:"row"`
+	Status       string   `json:"status"`
+	ProvinceCode string   `json:"province_code"`
+	ProvinceName string   `json:"province_name"`
+	HospitalName string   `json:"hospital_name"`
+	Address      string   `json:"address"`
+	DuplicateOf  *uint64  `json:"duplicate_of,omitempty"`
+	DuplicateRow int      `json:"duplicate_row,omitempty"`
+	PartnerID    *uint64  `json:"partner_id,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// ImportResult summarizes an import or its dry-run preview.
+type ImportResult struct {
+	DryRun     bool        `json:"dry_run"`
+	Total      int         `json:"total"`
+	Created    int         `json:"created"`
+	Duplicates int         `json:"duplicates"`
+	Invalid    int         `json:"invalid"`
+	Rows       []ImportRow `json:"rows"`
 }
@@ -2,9 +2,14 @@ package partner
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/company/internal-service-report/internal/domain/report"
 	"github.com/company/internal-service-report/pkg/response"
 )
 
@@ -18,7 +23,12 @@ func NewHandler(svc *Service) *Handler {
 }
 
 func (h *Handler) List(c *gin.Context) {
-	partners, err := h.svc.List(c.Request.Context())
+	filter, err := bindCountFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	partners, err := h.svc.List(c.Request.Context(), filter)
 	if err != nil {
 		response.InternalError(c, err)
 		return
@@ -26,6 +36,47 @@ func (h *Handler) List(c *gin.Context) {
 	response.OK(c, partners)
 }
 
+func (h *Handler) Provinces(c *gin.Context) {
+	filter, err := bindCountFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	provinces, err := h.svc.Provinces(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, provinces)
+}
+
+func bindCountFilter(c *gin.Context) (CountFilter, error) {
+	var q CountQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		return CountFilter{}, err
+	}
+	from, err := report.ParseDateParam(q.From, false)
+	if err != nil {
+		return CountFilter{}, fmt.Errorf("invalid from date: %w", err)
+	}
+	to, err := report.ParseDateParam(q.To, true)
+	if err != nil {
+		return CountFilter{}, fmt.Errorf("invalid to date: %w", err)
+	}
+	filter := CountFilter{From: from, To: to}
+	for _, status := range strings.Split(q.Status, ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+		if !report.IsValidStatus(status) {
+			return CountFilter{}, fmt.Errorf("unknown status %q", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	return filter, nil
+}
+
 func (h *Handler) Create(c *gin.Context) {
 	var req CreatePartnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -34,7 +85,7 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 	partner, err := h.svc.Create(c.Request.Context(), req)
 	if err != nil {
-		response.InternalError(c, err)
+		writeError(c, err)
 		return
 	}
 	response.Created(c, partner)
@@ -53,8 +104,117 @@ func (h *Handler) Delete(c *gin.Context) {
 			response.NotFound(c, "partner not found")
 			return
 		}
+		if errors.Is(err, ErrPartnerInUse) {
+			response.Conflict(c, err.Error())
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
 	response.NoContent(c)
 }
+
+func (h *Handler) Update(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req UpdatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	partner, err := h.svc.Update(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, partner)
+}
+
+func (h *Handler) Duplicates(c *gin.Context) {
+	groups, err := h.svc.Duplicates(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, groups)
+}
+
+func (h *Handler) Merge(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	result, err := h.svc.Merge(c.Request.Context(), uri.ID, req.SourceIDs)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, result)
+}
+
+// Import accepts a multipart "file" field. Pass dry_run=true to preview the outcome.
+func (h *Handler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportSize+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if file.Size > MaxImportSize {
+		response.BadRequest(c, fmt.Errorf("import file is larger than %d MB", MaxImportSize>>20))
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	defer f.Close()
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	result, err := h.svc.Import(c.Request.Context(), file.Filename, f, dryRun)
+	if err != nil {
+		var missing *MissingColumnsError
+		switch {
+		case errors.As(err, &missing):
+			response.UnprocessableEntity(c, err.Error())
+		case errors.Is(err, ErrUnsupportedFile), errors.Is(err, ErrImportEmpty):
+			response.UnprocessableEntity(c, err.Error())
+		default:
+			response.InternalError(c, err)
+		}
+		return
+	}
+	if dryRun {
+		response.OK(c, result)
+		return
+	}
+	response.Created(c, result)
+}
+
+func writeError(c *gin.Context, err error) {
+	var dup *DuplicateError
+	switch {
+	case errors.As(err, &dup):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "existing": dup.Existing})
+	case errors.Is(err, ErrPartnerNotFound):
+		response.NotFound(c, "partner not found")
+	case errors.Is(err, ErrPartnerIncomplete), errors.Is(err, ErrMergeSelf):
+		response.UnprocessableEntity(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,192 @@
+package partner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/pkg/tabular"
+)
+
+// MaxImportSize caps the size of an uploaded import file.
+const MaxImportSize = 10 << 20
+
+var (
+	ErrUnsupportedFile = tabular.ErrUnsupportedFile
+	ErrImportEmpty     = errors.New("import file has no data rows")
+)
+
+// MissingColumnsError lists required columns absent from the header row.
+type MissingColumnsError struct {
+	Columns []string
+}
+
+func (e *MissingColumnsError) Error() string {
+	return "import file is missing columns: " + strings.Join(e.Columns, ", ")
+}
+
+// importColumns maps accepted header spellings to partner fields.
+var importColumns = map[string]string{
+	"province_code": "province_code",
+	"kode_provinsi": "province_code",
+	"kode":          "province_code",
+	"province_name": "province_name",
+	"province":      "province_name",
+	"provinsi":      "province_name",
+	"hospital_name": "hospital_name",
+	"hospital":      "hospital_name",
+	"rumah_sakit":   "hospital_name",
+	"nama_rs":       "hospital_name",
+	"address":       "address",
+	"alamat":        "address",
+}
+
+var requiredImportColumns = []string{"province_code", "province_name", "hospital_name", "address"}
+
+// Import reads partners from a CSV or XLSX file. Every row is validated and checked for
+// duplicates against existing partners and earlier rows of the same file. With dryRun the
+// outcome is only previewed; otherwise valid rows are created in one transaction and
+// duplicate or invalid rows are skipped.
+func (s *Service) Import(ctx context.Context, filename string, r io.Reader, dryRun bool) (*ImportResult, error) {
+	records, err := tabular.Read(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, ErrImportEmpty
+	}
+	columns, err := mapImportHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []PartnerLocation
+	if err := s.db.WithContext(ctx).Select("id", "province_code", "hospital_name").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]uint64, len(existing))
+	for _, p := range existing {
+		known[DedupeKey(p.ProvinceCode, p.HospitalName)] = p.ID
+	}
+	inFile := map[string]int{}
+
+	result := &ImportResult{DryRun: dryRun, Rows: []ImportRow{}}
+	for i, record := range records[1:] {
+		if tabular.IsBlank(record) {
+			continue
+		}
+		row := ImportRow{Row: i + 2}
+		for field, idx := range columns {
+			value := ""
+			if idx < len(record) {
+				value = strings.Join(strings.Fields(record[idx]), " ")
+			}
+			switch field {
+			case "province_code":
+				row.ProvinceCode = strings.ToUpper(value)
+			case "province_name":
+				row.ProvinceName = value
+			case "hospital_name":
+				row.HospitalName = value
+			case "address":
+				row.Address = value
+			}
+		}
+		row.Errors = validateImportRow(row)
+		key := DedupeKey(row.ProvinceCode, row.HospitalName)
+		switch {
+		case len(row.Errors) > 0:
+			row.Status = ImportInvalid
+			result.Invalid++
+		case known[key] != 0:
+			id := known[key]
+			row.Status = ImportDuplicate
+			row.DuplicateOf = &id
+			result.Duplicates++
+		case inFile[key] != 0:
+			row.Status = ImportDuplicate
+			row.DuplicateRow = inFile[key]
+			result.Duplicates++
+		default:
+			row.Status = ImportCreate
+			inFile[key] = row.Row
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total = len(result.Rows)
+	if result.Total == 0 {
+		return nil, ErrImportEmpty
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range result.Rows {
+			row := &result.Rows[i]
+			if row.Status != ImportCreate {
+				continue
+			}
+			partner := PartnerLocation{
+				ProvinceCode: row.ProvinceCode,
+				ProvinceName: row.ProvinceName,
+				HospitalName: row.HospitalName,
+				Address:      row.Address,
+			}
+			if err := tx.Create(&partner).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			row.Status = ImportCreated
+			row.PartnerID = &partner.ID
+			result.Created++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func validateImportRow(row ImportRow) []string {
+	var errs []string
+	check := func(field, value string, max int) {
+		switch {
+		case value == "":
+			errs = append(errs, field+" is required")
+		case utf8.RuneCountInString(value) > max:
+			errs = append(errs, fmt.Sprintf("%s is longer than %d characters", field, max))
+		}
+	}
+	check("province_code", row.ProvinceCode, 10)
+	check("province_name", row.ProvinceName, 120)
+	check("hospital_name", row.HospitalName, 150)
+	check("address", row.Address, 255)
+	return errs
+}
+
+func mapImportHeader(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range header {
+		if field, ok := importColumns[tabular.HeaderKey(name)]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	var missing []string
+	for _, field := range requiredImportColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingColumnsError{Columns: missing}
+	}
+	return columns, nil
+}
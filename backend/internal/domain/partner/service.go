@@ -3,11 +3,31 @@ package partner
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+var (
+	ErrPartnerNotFound   = errors.New("partner not found")
+	ErrPartnerInUse      = errors.New("partner is still in use")
+	ErrPartnerIncomplete = errors.New("province code, province name and hospital name must not be empty")
 )
 
-var ErrPartnerNotFound = errors.New("partner not found")
+// periodColumn is the report date used for maintenance counts: completion for finished
+// reports, opening for the rest.
+const periodColumn = "COALESCE(service_reports.completed_at, service_reports.opened_at)"
+
+// CountFilter limits which reports count as maintenance visits.
+type CountFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Statuses []string
+}
 
 // Service encapsulates business logic for partner locations.
 type Service struct {
@@ -18,7 +38,8 @@ func NewService(db *gorm.DB) *Service {
 	return &Service{db: db}
 }
 
-func (s *Service) List(ctx context.Context) ([]PartnerLocation, error) {
+// List returns all partners with MaintenanceCount computed from linked reports.
+func (s *Service) List(ctx context.Context, filter CountFilter) ([]PartnerLocation, error) {
 	var partners []PartnerLocation
 	if err := s.db.WithContext(ctx).
 		Order("province_name ASC").
@@ -26,16 +47,116 @@ func (s *Service) List(ctx context.Context) ([]PartnerLocation, error) {
 		Find(&partners).Error; err != nil {
 		return nil, err
 	}
+
+	var counts []struct {
+		PartnerLocationID uint64
+		Total             int64
+	}
+	query := s.db.WithContext(ctx).Model(&report.ServiceReport{}).
+		Select("partner_location_id, COUNT(*) AS total").
+		Where("partner_location_id IS NOT NULL")
+	if err := applyCountFilter(query, filter).
+		Group("partner_location_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byPartner := make(map[uint64]int64, len(counts))
+	for _, c := range counts {
+		byPartner[c.PartnerLocationID] = c.Total
+	}
+	for i := range partners {
+		partners[i].MaintenanceCount = byPartner[partners[i].ID]
+	}
 	return partners, nil
 }
 
+// Provinces aggregates partner and maintenance counts per province for the coverage map.
+func (s *Service) Provinces(ctx context.Context, filter CountFilter) ([]ProvinceCount, error) {
+	reports := applyCountFilter(s.db.Model(&report.ServiceReport{}).
+		Select("partner_location_id, COUNT(*) AS total").
+		Where("partner_location_id IS NOT NULL"), filter).
+		Group("partner_location_id")
+
+	var rows []ProvinceCount
+	if err := s.db.WithContext(ctx).
+		Table("partner_locations").
+		Select("partner_locations.province_code, MAX(partner_locations.province_name) AS province_name, "+
+			"COUNT(*) AS partner_count, COALESCE(SUM(counts.total), 0) AS maintenance_count").
+		Joins("LEFT JOIN (?) AS counts ON counts.partner_location_id = partner_locations.id", reports).
+		Group("partner_locations.province_code").
+		Order("province_name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func applyCountFilter(query *gorm.DB, filter CountFilter) *gorm.DB {
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{report.StatusDone}
+	}
+	query = query.Where("service_reports.status IN ?", statuses)
+	if filter.From != nil {
+		query = query.Where(periodColumn+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(periodColumn+" < ?", *filter.To)
+	}
+	return query
+}
+
+// LinkReports attaches reports without a partner to one, using the report's customer or
+// device, and finally an exact match of the customer name on a unique hospital name.
+func (s *Service) LinkReports(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	statements := []string{
+		`UPDATE service_reports
+			JOIN customers ON customers.id = service_reports.customer_id
+			SET service_reports.partner_location_id = customers.partner_location_id
+			WHERE service_reports.partner_location_id IS NULL`,
+		`UPDATE service_reports
+			JOIN devices ON devices.id = service_reports.device_id
+			SET service_reports.partner_location_id = devices.partner_location_id
+			WHERE service_reports.partner_location_id IS NULL AND devices.partner_location_id IS NOT NULL`,
+		`UPDATE service_reports
+			JOIN (
+				SELECT hospital_name, MIN(id) AS id FROM partner_locations
+				GROUP BY hospital_name HAVING COUNT(*) = 1
+			) AS hospitals ON hospitals.hospital_name = service_reports.customer_name
+			SET service_reports.partner_location_id = hospitals.id
+			WHERE service_reports.partner_location_id IS NULL`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DuplicateError reports the existing partner that a create or update would duplicate.
+type DuplicateError struct {
+	Existing PartnerLocation
+}
+
+func (e *DuplicateError) Error() string { return ErrDuplicatePartner.Error() }
+
+func (e *DuplicateError) Unwrap() error { return ErrDuplicatePartner }
+
 func (s *Service) Create(ctx context.Context, req CreatePartnerRequest) (*PartnerLocation, error) {
 	partner := &PartnerLocation{
-		ProvinceCode:     req.ProvinceCode,
-		ProvinceName:     req.ProvinceName,
-		HospitalName:     req.HospitalName,
-		Address:          req.Address,
-		MaintenanceCount: req.MaintenanceCount,
+		ProvinceCode: strings.ToUpper(strings.TrimSpace(req.ProvinceCode)),
+		ProvinceName: strings.TrimSpace(req.ProvinceName),
+		HospitalName: strings.TrimSpace(req.HospitalName),
+		Address:      strings.TrimSpace(req.Address),
+	}
+	dup, err := findDuplicate(s.db.WithContext(ctx), partner.ProvinceCode, partner.HospitalName, 0)
+	if err != nil {
+		return nil, err
+	}
+	if dup != nil {
+		return nil, &DuplicateError{Existing: *dup}
 	}
 	if err := s.db.WithContext(ctx).Create(partner).Error; err != nil {
 		return nil, err
@@ -43,13 +164,92 @@ func (s *Service) Create(ctx context.Context, req CreatePartnerRequest) (*Partne
 	return partner, nil
 }
 
-func (s *Service) Delete(ctx context.Context, id uint64) error {
-	result := s.db.WithContext(ctx).Delete(&PartnerLocation{}, id)
-	if result.Error != nil {
-		return result.Error
+func (s *Service) Update(ctx context.Context, id uint64, req UpdatePartnerRequest) (*PartnerLocation, error) {
+	var partner PartnerLocation
+	if err := s.db.WithContext(ctx).First(&partner, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPartnerNotFound
+		}
+		return nil, err
 	}
-	if result.RowsAffected == 0 {
-		return ErrPartnerNotFound
+	if req.ProvinceCode != nil {
+		partner.ProvinceCode = strings.ToUpper(strings.TrimSpace(*req.ProvinceCode))
 	}
-	return nil
+	if req.ProvinceName != nil {
+		partner.ProvinceName = strings.TrimSpace(*req.ProvinceName)
+	}
+	if req.HospitalName != nil {
+		partner.HospitalName = strings.TrimSpace(*req.HospitalName)
+	}
+	if req.Address != nil {
+		partner.Address = strings.TrimSpace(*req.Address)
+	}
+	if partner.ProvinceCode == "" || partner.ProvinceName == "" || partner.HospitalName == "" {
+		return nil, ErrPartnerIncomplete
+	}
+	dup, err := findDuplicate(s.db.WithContext(ctx), partner.ProvinceCode, partner.HospitalName, partner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if dup != nil {
+		return nil, &DuplicateError{Existing: *dup}
+	}
+	if err := s.db.WithContext(ctx).Model(&partner).Updates(map[string]interface{}{
+		"province_code": partner.ProvinceCode,
+		"province_name": partner.ProvinceName,
+		"hospital_name": partner.HospitalName,
+		"address":       partner.Address,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+// Delete removes a partner without customers, site-wide PM plans or SLA policies. Reports,
+// their timesheets, devices and device PM plans linked to it are unlinked.
+func (s *Service) Delete(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var customers int64
+		if err := tx.Table("customers").Where("partner_location_id = ?", id).Count(&customers).Error; err != nil {
+			return err
+		}
+		if customers > 0 {
+			return fmt.Errorf("%w by %d customers", ErrPartnerInUse, customers)
+		}
+		var plans int64
+		if err := tx.Table("pm_plans").Where("partner_location_id = ? AND device_id IS NULL", id).Count(&plans).Error; err != nil {
+			return err
+		}
+		if plans > 0 {
+			return fmt.Errorf("%w by %d site pm plans", ErrPartnerInUse, plans)
+		}
+		var policies int64
+		if err := tx.Table("sla_policies").Where("partner_location_id = ?", id).Count(&policies).Error; err != nil {
+			return err
+		}
+		if policies > 0 {
+			return fmt.Errorf("%w by %d sla policies", ErrPartnerInUse, policies)
+		}
+		result := tx.Delete(&PartnerLocation{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPartnerNotFound
+		}
+		if err := tx.Model(&report.ServiceReport{}).Where("partner_location_id = ?", id).
+			UpdateColumn("partner_location_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("devices").Where("partner_location_id = ?", id).
+			UpdateColumn("partner_location_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("pm_plans").Where("partner_location_id = ?", id).
+			UpdateColumn("partner_location_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&report.ReportTimesheet{}).Where("partner_location_id = ?", id).
+			UpdateColumn("partner_location_id", nil).Error
+	})
 }
@@ -0,0 +1,273 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatZIP is the export format of report archives.
+const FormatZIP = "zip"
+
+const archiveManifestName = "manifest.json"
+
+// maxArchiveDays caps the date range of one archive at a quarter, so a single job cannot
+// copy every upload on the server.
+const maxArchiveDays = 92
+
+var (
+	ErrArchiveRange        = errors.New("an archive needs both a from and a to date")
+	ErrArchiveRangeTooLong = fmt.Errorf("an archive covers at most %d days", maxArchiveDays)
+)
+
+// checkArchiveRange requires a from and a to date no more than maxArchiveDays apart.
+func checkArchiveRange(filter ListFilter) error {
+	if filter.From == nil || filter.To == nil || filter.To.Before(*filter.From) {
+		return ErrArchiveRange
+	}
+	if filter.To.Sub(*filter.From) > maxArchiveDays*24*time.Hour {
+		return ErrArchiveRangeTooLong
+	}
+	return nil
+}
+
+// DocumentRenderer renders the printable document of a report, added to archives as
+// report.pdf.
+type DocumentRenderer interface {
+	Render(r *ServiceReport) ([]byte, error)
+}
+
+// UseDocumentRenderer sets the renderer of the report.pdf in archives. Without one,
+// archives hold only the data and the uploaded files.
+func (s *Service) UseDocumentRenderer(documents DocumentRenderer) {
+	s.documents = documents
+}
+
+// ArchiveManifest is written last into every archive. It lists each file with its size
+// and SHA-256 checksum, and the files that could not be included.
+type ArchiveManifest struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Reports     []ArchiveReport  `json:"reports"`
+	Files       []ArchiveFile    `json:"files"`
+	Missing     []ArchiveMissing `json:"missing,omitempty"`
+}
+
+// ArchiveReport names the folder of a report inside the archive; single report archives
+// use the root.
+type ArchiveReport struct {
+	ID         uint64 `json:"id"`
+	DispatchNo string `json:"dispatch_no"`
+	Status     string `json:"status"`
+	Folder     string `json:"folder"`
+}
+
+type ArchiveFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type ArchiveMissing struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type archiveWriter struct {
+	zip      *zip.Writer
+	manifest ArchiveManifest
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{zip: zip.NewWriter(w), manifest: ArchiveManifest{GeneratedAt: time.Now()}}
+}
+
+// add stores one file and records its checksum.
+func (a *archiveWriter) add(name string, modified time.Time, r io.Reader) error {
+	entry, err := a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(entry, hash), r)
+	if err != nil {
+		return err
+	}
+	a.manifest.Files = append(a.manifest.Files, ArchiveFile{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	return nil
+}
+
+func (a *archiveWriter) addJSON(name string, modified time.Time, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.add(name, modified, bytes.NewReader(data))
+}
+
+// addFile copies a file from disk. A file that is gone is listed as missing rather than
+// failing the archive.
+func (a *archiveWriter) addFile(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			a.missing(name, "file not found")
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return a.add(name, info.ModTime(), f)
+}
+
+func (a *archiveWriter) missing(name, reason string) {
+	a.manifest.Missing = append(a.manifest.Missing, ArchiveMissing{Path: name, Reason: reason})
+}
+
+func (a *archiveWriter) close() error {
+	if err := a.addJSON(archiveManifestName, a.manifest.GeneratedAt, a.manifest); err != nil {
+		return err
+	}
+	return a.zip.Close()
+}
+
+// ArchiveFolder names the folder and file of a report archive after its dispatch
+// number, e.g. SR-2026-03-00042.
+func ArchiveFolder(r *ServiceReport) string {
+	name := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			return c
+		}
+		return '-'
+	}, r.DispatchNo)
+	if name == "" {
+		return fmt.Sprintf("report-%d", r.ID)
+	}
+	return name
+}
+
+// WriteArchive streams a ZIP of one report loaded with GetByID: the report as JSON, its
+// status logs, the rendered report, every attachment, the payload images stored under
+// uploads/images/<id>/ and a manifest of SHA-256 checksums.
+func (s *Service) WriteArchive(ctx context.Context, r *ServiceReport, w io.Writer) error {
+	a := newArchiveWriter(w)
+	if err := s.archiveReport(ctx, a, r, ""); err != nil {
+		return err
+	}
+	return a.close()
+}
+
+// WriteArchiveRange streams one archive holding a folder per matching report and
+// returns the number of reports written.
+func (s *Service) WriteArchiveRange(ctx context.Context, filter ListFilter, w io.Writer) (int64, error) {
+	if err := checkArchiveRange(filter); err != nil {
+		return 0, err
+	}
+	var ids []uint64
+	if err := s.exportQuery(ctx, filter).Order("id").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	a := newArchiveWriter(w)
+	folders := map[string]bool{}
+	for _, id := range ids {
+		r, err := s.GetByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		folder := ArchiveFolder(r)
+		if folders[folder] {
+			folder = fmt.Sprintf("%s-%d", folder, r.ID)
+		}
+		folders[folder] = true
+		if err := s.archiveReport(ctx, a, r, folder); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), a.close()
+}
+
+func (s *Service) archiveReport(ctx context.Context, a *archiveWriter, r *ServiceReport, folder string) error {
+	a.manifest.Reports = append(a.manifest.Reports, ArchiveReport{ID: r.ID, DispatchNo: r.DispatchNo, Status: r.Status, Folder: folder})
+	prefix := ""
+	if folder != "" {
+		prefix = folder + "/"
+	}
+	if err := a.addJSON(prefix+"report.json", r.UpdatedAt, r); err != nil {
+		return err
+	}
+	logs := r.StatusLogs
+	if logs == nil {
+		logs = []StatusLog{}
+	}
+	if err := a.addJSON(prefix+"status-logs.json", r.UpdatedAt, logs); err != nil {
+		return err
+	}
+	if s.documents != nil {
+		if doc, err := s.documents.Render(r); err != nil {
+			a.missing(prefix+"report.pdf", err.Error())
+		} else if err := a.add(prefix+"report.pdf", r.UpdatedAt, bytes.NewReader(doc)); err != nil {
+			return err
+		}
+	}
+
+	attachments := append([]ReportAttachment(nil), r.Attachments...)
+	sort.SliceStable(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+	for _, att := range attachments {
+		name := fmt.Sprintf("%sattachments/%d-%s", prefix, att.ID, sanitizeFilename(filepath.Base(att.FileName)))
+		if err := a.addFile(name, att.FilePath); err != nil {
+			return err
+		}
+	}
+
+	root := filepath.Join(s.uploadDir, "images", strconv.FormatUint(r.ID, 10))
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return a.addFile(prefix+"images/"+filepath.ToSlash(rel), path)
+	})
+}
+
+// StartArchive records a background job that archives every report in the date range of
+// filter. The job is downloaded like an export.
+func (s *Service) StartArchive(ctx context.Context, adminID uint64, filter ListFilter) (*ExportJob, error) {
+	if err := checkArchiveRange(filter); err != nil {
+		return nil, err
+	}
+	return s.startExportJob(ctx, adminID, ExportRequest{Filter: filter, Format: FormatZIP})
+}
@@ -0,0 +1,34 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckArchiveRange(t *testing.T) {
+	day := func(s string) *time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+	tests := []struct {
+		name     string
+		from, to *time.Time
+		want     error
+	}{
+		{"one day", day("2026-03-01"), day("2026-03-02"), nil},
+		{"a quarter", day("2026-07-01"), day("2026-10-01"), nil},
+		{"more than a quarter", day("2026-01-01"), day("2026-04-05"), ErrArchiveRangeTooLong},
+		{"no from", nil, day("2026-03-02"), ErrArchiveRange},
+		{"no to", day("2026-03-01"), nil, ErrArchiveRange},
+		{"reversed", day("2026-03-02"), day("2026-03-01"), ErrArchiveRange},
+	}
+	for _, tt := range tests {
+		if err := checkArchiveRange(ListFilter{From: tt.from, To: tt.to}); !errors.Is(err, tt.want) {
+			t.Errorf("%s: checkArchiveRange = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
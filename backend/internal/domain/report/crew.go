@@ -0,0 +1,205 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// assignedTo limits a report query to reports where teknisiID is the lead or an active crew member.
+func (s *Service) assignedTo(teknisiID uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		crew := s.db.Model(&ReportAssignment{}).
+			Select("report_id").
+			Where("teknisi_id = ? AND unassigned_at IS NULL", teknisiID)
+		return db.Where("(service_reports.teknisi_id = ? OR service_reports.id IN (?))", teknisiID, crew)
+	}
+}
+
+func (s *Service) isCrewMember(ctx context.Context, report *ServiceReport, teknisiID uint64) (bool, error) {
+	if report.TeknisiID != nil && *report.TeknisiID == teknisiID {
+		return true, nil
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&ReportAssignment{}).
+		Where("report_id = ? AND teknisi_id = ? AND unassigned_at IS NULL", report.ID, teknisiID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// activeCrew preloads only the current crew rows.
+func activeCrew(db *gorm.DB) *gorm.DB {
+	return db.Where("unassigned_at IS NULL").Order("role ASC, assigned_at ASC")
+}
+
+// setLead moves the lead role to teknisiID, closing the previous lead row with reason.
+func (s *Service) setLead(tx *gorm.DB, report *ServiceReport, teknisiID, adminID uint64, reason string) (bool, error) {
+	var active []ReportAssignment
+	if err := tx.Where("report_id = ? AND unassigned_at IS NULL", report.ID).Find(&active).Error; err != nil {
+		return false, err
+	}
+	for _, a := range active {
+		if a.Role == CrewLead && a.TeknisiID == teknisiID {
+			return false, nil
+		}
+	}
+
+	now := time.Now()
+	for _, a := range active {
+		// The previous lead leaves the crew; a helper promoted to lead drops the helper row.
+		if a.Role == CrewLead || a.TeknisiID == teknisiID {
+			if err := closeAssignment(tx, a.ID, adminID, reason, now); err != nil {
+				return false, err
+			}
+		}
+	}
+	lead := ReportAssignment{
+		ReportID:   report.ID,
+		TeknisiID:  teknisiID,
+		Role:       CrewLead,
+		AssignedBy: adminID,
+		Reason:     reason,
+	}
+	if err := tx.Create(&lead).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setHelpers replaces the active helper crew with helperIDs and returns who was added and removed.
+func (s *Service) setHelpers(tx *gorm.DB, report *ServiceReport, helperIDs []uint64, adminID uint64, reason string) (added, removed []uint64, err error) {
+	var active []ReportAssignment
+	if err := tx.Where("report_id = ? AND role = ? AND unassigned_at IS NULL", report.ID, CrewHelper).Find(&active).Error; err != nil {
+		return nil, nil, err
+	}
+
+	want := map[uint64]struct{}{}
+	for _, id := range helperIDs {
+		if report.TeknisiID != nil && *report.TeknisiID == id {
+			continue
+		}
+		want[id] = struct{}{}
+	}
+
+	now := time.Now()
+	have := map[uint64]struct{}{}
+	for _, a := range active {
+		if _, keep := want[a.TeknisiID]; keep {
+			have[a.TeknisiID] = struct{}{}
+			continue
+		}
+		if err := closeAssignment(tx, a.ID, adminID, reason, now); err != nil {
+			return nil, nil, err
+		}
+		removed = append(removed, a.TeknisiID)
+	}
+	for _, id := range helperIDs {
+		if _, ok := want[id]; !ok {
+			continue
+		}
+		if _, ok := have[id]; ok {
+			continue
+		}
+		helper := ReportAssignment{
+			ReportID:   report.ID,
+			TeknisiID:  id,
+			Role:       CrewHelper,
+			AssignedBy: adminID,
+			Reason:     reason,
+		}
+		if err := tx.Create(&helper).Error; err != nil {
+			return nil, nil, err
+		}
+		have[id] = struct{}{}
+		added = append(added, id)
+	}
+	return added, removed, nil
+}
+
+func closeAssignment(tx *gorm.DB, id, adminID uint64, reason string, at time.Time) error {
+	return tx.Model(&ReportAssignment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"unassigned_at":   at,
+		"unassigned_by":   adminID,
+		"unassign_reason": reason,
+	}).Error
+}
+
+// ListAssignments returns the full assignment history of a report, oldest first.
+func (s *Service) ListAssignments(ctx context.Context, reportID uint64) ([]AssignmentEntry, error) {
+	if err := s.db.WithContext(ctx).Select("id").First(&ServiceReport{}, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	var rows []ReportAssignment
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("assigned_at ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(rows)*2)
+	for _, r := range rows {
+		ids = append(ids, r.TeknisiID, r.AssignedBy)
+	}
+	names, err := s.userNames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AssignmentEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, AssignmentEntry{
+			ReportAssignment: r,
+			TeknisiName:      names[r.TeknisiID],
+			AssignedByName:   names[r.AssignedBy],
+		})
+	}
+	return entries, nil
+}
+
+// ErrTeknisiOverloaded is returned when a technician already holds the maximum number of open jobs.
+var ErrTeknisiOverloaded = errors.New("technician has reached the maximum number of open jobs")
+
+// activeStatuses are the states that count towards a technician's workload.
+var activeStatuses = []string{StatusOpen, StatusProgress, StatusOnHold, StatusWaitingParts, StatusReopened}
+
+// validateCrew checks every technician newly joining the report crew.
+func (s *Service) validateCrew(ctx context.Context, tx *gorm.DB, report *ServiceReport, adminID uint64, req AssignRequest) error {
+	ids := append([]uint64{req.TeknisiID}, req.HelperIDs...)
+	seen := map[uint64]struct{}{}
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		member, err := s.isCrewMember(ctx, report, id)
+		if err != nil {
+			return err
+		}
+		if member {
+			continue
+		}
+		if s.assignees != nil {
+			if err := s.assignees.ValidateAssignee(ctx, adminID, id); err != nil {
+				return err
+			}
+		}
+		if s.maxOpenJobs > 0 {
+			var open int64
+			if err := tx.Model(&ServiceReport{}).
+				Scopes(s.assignedTo(id)).
+				Where("id <> ? AND status IN ?", report.ID, activeStatuses).
+				Count(&open).Error; err != nil {
+				return err
+			}
+			if open >= int64(s.maxOpenJobs) {
+				return ErrTeknisiOverloaded
+			}
+		}
+	}
+	return nil
+}
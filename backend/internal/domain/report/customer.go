@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// resolveCustomer returns the registry snapshot picked by the request, or nil when the
+// request carries free-text customer details instead.
+func (s *Service) resolveCustomer(ctx context.Context, req CreateReportRequest) (*CustomerSnapshot, error) {
+	if req.CustomerID == nil && req.ContactID == nil {
+		c := req.Customer
+		if strings.TrimSpace(c.Name) == "" || strings.TrimSpace(c.Address) == "" || strings.TrimSpace(c.Contact) == "" {
+			return nil, ErrCustomerDetailsMissing
+		}
+		return nil, nil
+	}
+	if s.customers == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return s.customers.ResolveCustomer(s.db.WithContext(ctx), req.CustomerID, req.ContactID)
+}
+
+// customerPayloadFields maps form payload keys to snapshot values.
+func customerPayloadFields(c *CustomerSnapshot) map[string]string {
+	return map[string]string{
+		"customerName":   c.Name,
+		"customerPerson": c.Person,
+		"department":     c.Department,
+		"address":        c.Address,
+		"phone":          c.Phone,
+		"email":          c.Email,
+	}
+}
+
+// applyCustomerSnapshot writes the registry snapshot over the customer fields of the form
+// payload, so the payload and the report columns describe the same customer.
+func applyCustomerSnapshot(payload []byte, c *CustomerSnapshot) ([]byte, error) {
+	root := map[string]any{}
+	if len(bytes.TrimSpace(payload)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(payload))
+		dec.UseNumber()
+		if err := dec.Decode(&root); err != nil {
+			return nil, &PayloadError{Kind: payloadForm, Fields: []FieldError{{Field: "/", Message: "must be a JSON object"}}}
+		}
+	}
+	for key, value := range customerPayloadFields(c) {
+		root[key] = value
+	}
+	return json.Marshal(root)
+}
+
+// customerContactLine is the contact column snapshot: the person and phone, or the customer phone.
+func customerContactLine(c *CustomerSnapshot) string {
+	parts := make([]string, 0, 2)
+	for _, v := range []string{c.Person, c.Phone} {
+		if v = strings.TrimSpace(v); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyCustomerSnapshot(t *testing.T) {
+	snapshot := &CustomerSnapshot{
+		Name:       "RSUD Dr. Soetomo",
+		Person:     "dr. Rina",
+		Department: "Radiologi",
+		Address:    "Jl. Mayjen Prof. Dr. Moestopo 6-8, Surabaya",
+		Phone:      "031-5501078",
+	}
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"empty payload", ``},
+		{"blank fields", `{"customerName":"","address":" ","deviceRows":[]}`},
+		{"typed fields are replaced", `{"customerName":"RS Soetomo","department":"IGD","email":"old@example.com","deviceRows":[]}`},
+	}
+	for _, tt := range tests {
+		out, err := applyCustomerSnapshot([]byte(tt.payload), snapshot)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for key, want := range customerPayloadFields(snapshot) {
+			if got[key] != want {
+				t.Errorf("%s: %s = %v, want %q", tt.name, key, got[key], want)
+			}
+		}
+	}
+}
+
+func TestApplyCustomerSnapshotKeepsOtherFields(t *testing.T) {
+	out, err := applyCustomerSnapshot([]byte(`{"customerName":"x","complaintNo":12345678901234567890}`), &CustomerSnapshot{Name: "RS A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"address":"","complaintNo":12345678901234567890,"customerName":"RS A","customerPerson":"","department":"","email":"","phone":""}`; string(out) != want {
+		t.Errorf("payload = %s, want %s", out, want)
+	}
+	if _, err := applyCustomerSnapshot([]byte(`[1]`), &CustomerSnapshot{Name: "RS A"}); err == nil {
+		t.Error("non-object payload was accepted")
+	}
+}
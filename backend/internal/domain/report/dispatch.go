@@ -0,0 +1,286 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultDispatchPattern numbers reports per year, e.g. SR/2026/03/00042.
+const (
+	DefaultDispatchPattern = "SR/{YYYY}/{MM}/{seq:00000}"
+	DefaultDispatchCounter = "year"
+)
+
+// defaultBranch fills {BRANCH} for reports whose partner has no branch.
+const defaultBranch = "HQ"
+
+// maxDispatchLen matches the size of the dispatch_no column.
+const maxDispatchLen = 64
+
+var (
+	ErrDispatchPattern = errors.New(`dispatch pattern must contain one {seq} and only {YYYY}, {YY}, {MM}, {DD}, {BRANCH} or {seq:000} tokens`)
+	ErrDispatchCounter = errors.New("dispatch counter must list year, month, day or branch, each used by the pattern")
+	ErrDispatchTooLong = errors.New("dispatch number exceeds 64 characters")
+)
+
+// BranchResolver names the branch that serves a partner, for {BRANCH} in dispatch numbers.
+type BranchResolver interface {
+	BranchCode(ctx context.Context, partnerID *uint64) (string, error)
+}
+
+// DispatchSequence holds the last number issued for one counter scope.
+type DispatchSequence struct {
+	Scope     string    `gorm:"primaryKey;size:160" json:"scope"`
+	Value     uint64    `json:"value"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+var dispatchToken = regexp.MustCompile(`\{([A-Za-z]+)(?::(0+))?\}`)
+
+// DispatchPattern renders dispatch numbers such as KMS/{YYYY}/{MM}/{seq:0000}. The counter
+// lists the parts of the date and the branch that start a new sequence; "none" never
+// resets.
+type DispatchPattern struct {
+	pattern string
+	counter []string
+	width   int
+}
+
+// ParseDispatchPattern checks a pattern and its counter scope. Every counter part must be
+// printed by the pattern, otherwise numbers would repeat when the counter restarts.
+func ParseDispatchPattern(pattern, counter string) (*DispatchPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if len(pattern) > maxDispatchLen {
+		return nil, ErrDispatchTooLong
+	}
+	p := &DispatchPattern{pattern: pattern}
+	seqs := 0
+	used := map[string]bool{}
+	for _, m := range dispatchToken.FindAllStringSubmatch(pattern, -1) {
+		switch m[1] {
+		case "seq":
+			seqs++
+			p.width = len(m[2])
+		case "YYYY", "YY":
+			used["year"] = true
+		case "MM":
+			used["month"] = true
+		case "DD":
+			used["day"] = true
+		case "BRANCH":
+			used["branch"] = true
+		default:
+			return nil, ErrDispatchPattern
+		}
+		if m[1] != "seq" && m[2] != "" {
+			return nil, ErrDispatchPattern
+		}
+	}
+	if seqs != 1 || strings.ContainsAny(dispatchToken.ReplaceAllString(pattern, ""), "{}") {
+		return nil, ErrDispatchPattern
+	}
+	for _, part := range strings.Split(counter, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" || part == "none" {
+			continue
+		}
+		if !used[part] {
+			return nil, ErrDispatchCounter
+		}
+		p.counter = append(p.counter, part)
+	}
+	// A monthly counter needs the year too, or January would continue last January.
+	if p.resets("month") || p.resets("day") {
+		if !used["year"] {
+			return nil, ErrDispatchCounter
+		}
+		if !p.resets("year") {
+			p.counter = append([]string{"year"}, p.counter...)
+		}
+	}
+	if p.resets("day") && !p.resets("month") {
+		if !used["month"] {
+			return nil, ErrDispatchCounter
+		}
+		p.counter = append(p.counter, "month")
+	}
+	return p, nil
+}
+
+func (p *DispatchPattern) resets(part string) bool {
+	for _, c := range p.counter {
+		if c == part {
+			return true
+		}
+	}
+	return false
+}
+
+// usesBranch reports whether rendering needs the partner's branch.
+func (p *DispatchPattern) usesBranch() bool {
+	return strings.Contains(p.pattern, "{BRANCH}")
+}
+
+// scope returns the counter key for a report opened at t in branch. The key follows the
+// counter setting, so a scope that is new after the setting or pattern changed starts from
+// the numbers already issued; see nextDispatchNo.
+func (p *DispatchPattern) scope(t time.Time, branch string) string {
+	parts := []string{p.pattern}
+	for _, c := range []string{"year", "month", "day", "branch"} {
+		if !p.resets(c) {
+			continue
+		}
+		switch c {
+		case "year":
+			parts = append(parts, t.Format("2006"))
+		case "month":
+			parts = append(parts, t.Format("01"))
+		case "day":
+			parts = append(parts, t.Format("02"))
+		case "branch":
+			parts = append(parts, branch)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// render prints the dispatch number for a report opened at t.
+func (p *DispatchPattern) render(t time.Time, branch string, seq uint64) string {
+	return p.renderPart(p.pattern, t, branch, seq)
+}
+
+// affixes renders the text around {seq} for a report opened at t in branch.
+func (p *DispatchPattern) affixes(t time.Time, branch string) (prefix, suffix string) {
+	for _, m := range dispatchToken.FindAllStringSubmatchIndex(p.pattern, -1) {
+		if p.pattern[m[2]:m[3]] == "seq" {
+			return p.renderPart(p.pattern[:m[0]], t, branch, 0), p.renderPart(p.pattern[m[1]:], t, branch, 0)
+		}
+	}
+	return p.render(t, branch, 0), ""
+}
+
+// seqOf returns the sequence printed in number when it was rendered for t and branch.
+func (p *DispatchPattern) seqOf(number string, t time.Time, branch string) (uint64, bool) {
+	prefix, suffix := p.affixes(t, branch)
+	if len(number) <= len(prefix)+len(suffix) || !strings.HasPrefix(number, prefix) || !strings.HasSuffix(number, suffix) {
+		return 0, false
+	}
+	digits := number[len(prefix) : len(number)-len(suffix)]
+	if strings.Trim(digits, "0123456789") != "" {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(digits, 10, 64)
+	return seq, err == nil
+}
+
+// highestSeq returns the highest sequence among numbers rendered for t and branch.
+func (p *DispatchPattern) highestSeq(numbers []string, t time.Time, branch string) uint64 {
+	var highest uint64
+	for _, number := range numbers {
+		if seq, ok := p.seqOf(number, t, branch); ok && seq > highest {
+			highest = seq
+		}
+	}
+	return highest
+}
+
+func (p *DispatchPattern) renderPart(part string, t time.Time, branch string, seq uint64) string {
+	return dispatchToken.ReplaceAllStringFunc(part, func(token string) string {
+		m := dispatchToken.FindStringSubmatch(token)
+		switch m[1] {
+		case "YYYY":
+			return t.Format("2006")
+		case "YY":
+			return t.Format("06")
+		case "MM":
+			return t.Format("01")
+		case "DD":
+			return t.Format("02")
+		case "BRANCH":
+			return branch
+		default:
+			return fmt.Sprintf("%0*d", p.width, seq)
+		}
+	})
+}
+
+// UseDispatchPattern replaces the default dispatch numbering.
+func (s *Service) UseDispatchPattern(p *DispatchPattern) {
+	s.dispatch = p
+}
+
+// UseBranchResolver supplies the branch code printed by {BRANCH}.
+func (s *Service) UseBranchResolver(r BranchResolver) {
+	s.branches = r
+}
+
+// dispatchBranch returns the branch code for a partner, or the default branch.
+func (s *Service) dispatchBranch(ctx context.Context, partnerID *uint64) (string, error) {
+	if s.dispatch == nil || !s.dispatch.usesBranch() || s.branches == nil {
+		return defaultBranch, nil
+	}
+	code, err := s.branches.BranchCode(ctx, partnerID)
+	if err != nil {
+		return "", err
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return defaultBranch, nil
+	}
+	return code, nil
+}
+
+// nextDispatchNo takes the next number of its scope inside tx. The counter row stays locked
+// until tx ends, so concurrent reports wait their turn and a rolled back create gives its
+// number back, leaving no gaps. A new scope continues after the highest number already
+// issued with the same rendering, so changing the counter or the pattern never reissues one.
+func (s *Service) nextDispatchNo(tx *gorm.DB, t time.Time, branch string) (string, error) {
+	p := s.dispatch
+	scope := p.scope(t, branch)
+	var count int64
+	if err := tx.Model(&DispatchSequence{}).Where("scope = ?", scope).Count(&count).Error; err != nil {
+		return "", err
+	}
+	seq := DispatchSequence{Scope: scope, Value: 1}
+	if count == 0 {
+		issued, err := s.highestDispatchSeq(tx, t, branch)
+		if err != nil {
+			return "", err
+		}
+		seq.Value = issued + 1
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("value + 1")}),
+	}).Create(&seq).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("scope = ?", scope).First(&seq).Error; err != nil {
+		return "", err
+	}
+	number := p.render(t, branch, seq.Value)
+	if len(number) > maxDispatchLen {
+		return "", ErrDispatchTooLong
+	}
+	return number, nil
+}
+
+// highestDispatchSeq returns the highest sequence among the dispatch numbers already issued
+// that render like a number for t and branch, or 0 when there are none.
+func (s *Service) highestDispatchSeq(tx *gorm.DB, t time.Time, branch string) (uint64, error) {
+	prefix, suffix := s.dispatch.affixes(t, branch)
+	var numbers []string
+	if err := tx.Model(&ServiceReport{}).
+		Where("dispatch_no LIKE ?", likeEscaper.Replace(prefix)+"%"+likeEscaper.Replace(suffix)).
+		Pluck("dispatch_no", &numbers).Error; err != nil {
+		return 0, err
+	}
+	return s.dispatch.highestSeq(numbers, t, branch), nil
+}
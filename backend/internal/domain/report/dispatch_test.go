@@ -0,0 +1,149 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDispatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, counter string
+		want             error
+		resets           []string
+	}{
+		{DefaultDispatchPattern, DefaultDispatchCounter, nil, []string{"year"}},
+		{"KMS/{YYYY}/{MM}/{seq:0000}", "month", nil, []string{"year", "month"}},
+		{"KMS/{YY}{MM}{DD}-{seq:000}", "day", nil, []string{"year", "day", "month"}},
+		{"{BRANCH}/{YYYY}/{seq:0000}", "branch, year", nil, []string{"branch", "year"}},
+		{"SR-{seq}", "none", nil, nil},
+		{"SR-{seq}", "", nil, nil},
+		{"SR/{YYYY}", "year", ErrDispatchPattern, nil},
+		{"SR/{seq}/{seq}", "", ErrDispatchPattern, nil},
+		{"SR/{YEAR}/{seq}", "", ErrDispatchPattern, nil},
+		{"SR/{YYYY:00}/{seq}", "", ErrDispatchPattern, nil},
+		{"SR/{YYYY/{seq}", "", ErrDispatchPattern, nil},
+		{"SR/{MM}/{seq}", "month", ErrDispatchCounter, nil},
+		{"SR/{YYYY}/{DD}/{seq}", "day", ErrDispatchCounter, nil},
+		{"SR/{YYYY}/{seq}", "branch", ErrDispatchCounter, nil},
+		{"SR/{YYYY}/{seq}", "week", ErrDispatchCounter, nil},
+		{"SR/" + strings.Repeat("X", maxDispatchLen) + "/{seq}", "", ErrDispatchTooLong, nil},
+	}
+	for _, tt := range tests {
+		p, err := ParseDispatchPattern(tt.pattern, tt.counter)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("ParseDispatchPattern(%q, %q) error = %v, want %v", tt.pattern, tt.counter, err, tt.want)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if strings.Join(p.counter, ",") != strings.Join(tt.resets, ",") {
+			t.Errorf("ParseDispatchPattern(%q, %q) counter = %v, want %v", tt.pattern, tt.counter, p.counter, tt.resets)
+		}
+	}
+}
+
+func TestDispatchScope(t *testing.T) {
+	march := time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+	april := time.Date(2026, 4, 2, 10, 0, 0, 0, time.Local)
+	nextYear := time.Date(2027, 3, 14, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		pattern, counter string
+		a, b             time.Time
+		branchA, branchB string
+		same             bool
+	}{
+		{DefaultDispatchPattern, "year", march, april, "HQ", "HQ", true},
+		{DefaultDispatchPattern, "year", march, nextYear, "HQ", "HQ", false},
+		{DefaultDispatchPattern, "month", march, april, "HQ", "HQ", false},
+		{DefaultDispatchPattern, "month", march, nextYear, "HQ", "HQ", false},
+		{"SR/{YYYY}/{MM}/{DD}/{seq}", "day", march, march.Add(4 * time.Hour), "HQ", "HQ", true},
+		{"{BRANCH}/{YYYY}/{seq}", "branch", march, march, "SBY", "JKT", false},
+		{"{BRANCH}/{YYYY}/{seq}", "year", march, march, "SBY", "JKT", true},
+		{"SR-{seq}", "none", march, nextYear, "HQ", "HQ", true},
+	}
+	for _, tt := range tests {
+		p, err := ParseDispatchPattern(tt.pattern, tt.counter)
+		if err != nil {
+			t.Fatalf("ParseDispatchPattern(%q, %q): %v", tt.pattern, tt.counter, err)
+		}
+		a, b := p.scope(tt.a, tt.branchA), p.scope(tt.b, tt.branchB)
+		if (a == b) != tt.same {
+			t.Errorf("%q counted by %s: scopes %q and %q, want same = %v", tt.pattern, tt.counter, a, b, tt.same)
+		}
+	}
+}
+
+func TestDispatchRender(t *testing.T) {
+	at := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		pattern string
+		seq     uint64
+		want    string
+	}{
+		{DefaultDispatchPattern, 42, "SR/2026/03/00042"},
+		{"KMS/{YY}{MM}{DD}-{seq:0000}", 7, "KMS/260304-0007"},
+		{"KMS/{seq:0000}", 123456, "KMS/123456"},
+		{"{BRANCH}/{YYYY}/{seq}", 9, "SBY/2026/9"},
+	}
+	for _, tt := range tests {
+		p, err := ParseDispatchPattern(tt.pattern, "none")
+		if err != nil {
+			t.Fatalf("ParseDispatchPattern(%q): %v", tt.pattern, err)
+		}
+		if got := p.render(at, "SBY", tt.seq); got != tt.want {
+			t.Errorf("render(%q, %d) = %q, want %q", tt.pattern, tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestDispatchSeqOf(t *testing.T) {
+	march := time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		pattern, number string
+		want            uint64
+		ok              bool
+	}{
+		{DefaultDispatchPattern, "SR/2026/03/00042", 42, true},
+		{DefaultDispatchPattern, "SR/2026/03/123456", 123456, true},
+		{DefaultDispatchPattern, "SR/2026/04/00042", 0, false},
+		{DefaultDispatchPattern, "SR/2026/03/", 0, false},
+		{DefaultDispatchPattern, "SR/2026/03/00042-A", 0, false},
+		{"{seq:0000}/{BRANCH}/{YY}", "0007/SBY/26", 7, true},
+		{"{seq:0000}/{BRANCH}/{YY}", "0007/JKT/26", 0, false},
+		{"SR_{seq}", "SR_12", 12, true},
+		{"SR_{seq}", "SRX12", 0, false},
+	}
+	for _, tt := range tests {
+		p, err := ParseDispatchPattern(tt.pattern, "none")
+		if err != nil {
+			t.Fatalf("ParseDispatchPattern(%q): %v", tt.pattern, err)
+		}
+		got, ok := p.seqOf(tt.number, march, "SBY")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("seqOf(%q, %q) = %d %v, want %d %v", tt.pattern, tt.number, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestDispatchCounterSwitch numbers a March report after a yearly counter has been switched
+// to a monthly one: the new monthly scope continues after the numbers the yearly one issued.
+func TestDispatchCounterSwitch(t *testing.T) {
+	march := time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+	yearly, _ := ParseDispatchPattern(DefaultDispatchPattern, "year")
+	monthly, _ := ParseDispatchPattern(DefaultDispatchPattern, "month")
+	if yearly.scope(march, "HQ") == monthly.scope(march, "HQ") {
+		t.Fatal("yearly and monthly counters share a scope")
+	}
+	issued := []string{
+		yearly.render(time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local), "HQ", 17),
+		yearly.render(march, "HQ", 18),
+		yearly.render(march, "HQ", 19),
+	}
+	highest := monthly.highestSeq(issued, march, "HQ")
+	if next := monthly.render(march, "HQ", highest+1); next != "SR/2026/03/00020" {
+		t.Errorf("first monthly number = %s, want SR/2026/03/00020", next)
+	}
+}
@@ -1,42 +1,237 @@
 package report
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
 
-// CustomerInfo holds customer detail section.
+	"gorm.io/datatypes"
+)
+
+// CustomerInfo holds customer detail section. The fields are required unless the
+// request picks a registered customer or contact.
 type CustomerInfo struct {
-	Name    string `json:"name" binding:"required"`
-	Address string `json:"address" binding:"required"`
-	Contact string `json:"contact" binding:"required"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Contact string `json:"contact"`
 }
 
-// DeviceInfo holds device detail section.
+// DeviceInfo holds device detail section. A registered device can be picked by ID;
+// otherwise the name and serial are matched against the device registry.
 type DeviceInfo struct {
-	Name     string `json:"name" binding:"required"`
-	Serial   string `json:"serial" binding:"required"`
-	Location string `json:"location" binding:"required"`
+	ID                *uint64 `json:"id"`
+	Manufacturer      string  `json:"manufacturer"`
+	Model             string  `json:"model"`
+	Name              string  `json:"name" binding:"required_without=ID"`
+	Serial            string  `json:"serial" binding:"required_without=ID"`
+	Location          string  `json:"location" binding:"required_without=ID"`
+	PartnerLocationID *uint64 `json:"partner_location_id"`
 }
 
 // CreateReportRequest payload for admin when creating new report.
 type CreateReportRequest struct {
-	Customer    CustomerInfo    `json:"customer" binding:"required"`
-	Device      DeviceInfo      `json:"device" binding:"required"`
-	Complaint   string          `json:"complaint" binding:"required"`
-	FormPayload json.RawMessage `json:"form_payload" binding:"required"`
+	Customer      CustomerInfo    `json:"customer"`
+	CustomerID    *uint64         `json:"customer_id"`
+	ContactID     *uint64         `json:"contact_id"`
+	PartnerID     *uint64         `json:"partner_location_id"`
+	Device        DeviceInfo      `json:"device" binding:"required"`
+	Complaint     string          `json:"complaint" binding:"required"`
+	Priority      string          `json:"priority" binding:"omitempty,oneof=low normal high critical"`
+	FormPayload   json.RawMessage `json:"form_payload" binding:"required"`
+	SchemaVersion int             `json:"schema_version"`
 }
 
-// AssignRequest assigns technician to report.
+// AssignRequest assigns the lead technician and optionally the helper crew.
+// HelperIDs left out keeps the current helpers; an empty list removes them all.
 type AssignRequest struct {
-	TeknisiID uint64 `json:"teknisi_id" binding:"required"`
+	TeknisiID uint64   `json:"teknisi_id" binding:"required"`
+	HelperIDs []uint64 `json:"helper_ids"`
+	Reason    string   `json:"reason"`
 }
 
 // ProgressRequest used by technician to update job status.
 type ProgressRequest struct {
-	Status      string `json:"status" binding:"required,oneof=progress done"`
+	Status      string `json:"status" binding:"required,oneof=progress on_hold waiting_parts review"`
 	JobSummary  string `json:"job_summary" binding:"required"`
 	ActionTaken string `json:"action_taken" binding:"required"`
 }
 
+// StatusRequest used by admin to move a report through its lifecycle.
+type StatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=progress on_hold waiting_parts cancelled reopened"`
+	Note   string `json:"note"`
+}
+
+// ApproveRequest used by admin to sign off a report under review.
+type ApproveRequest struct {
+	Comment string `json:"comment"`
+}
+
+// RejectRequest used by admin to send a report back to the technician.
+type RejectRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
 // TechnicianFormRequest stores technician side form payload.
 type TechnicianFormRequest struct {
-	Payload json.RawMessage `json:"payload" binding:"required"`
+	Payload       json.RawMessage `json:"payload" binding:"required"`
+	SchemaVersion int             `json:"schema_version"`
+}
+
+// TimelineEntry is a single item in the report activity feed.
+type TimelineEntry struct {
+	Type        string         `json:"type"`
+	At          time.Time      `json:"at"`
+	ActorID     uint64         `json:"actor_id"`
+	ActorName   string         `json:"actor_name"`
+	FromStatus  string         `json:"from_status,omitempty"`
+	ToStatus    string         `json:"to_status,omitempty"`
+	TeknisiID   *uint64        `json:"teknisi_id,omitempty"`
+	TeknisiName string         `json:"teknisi_name,omitempty"`
+	Note        string         `json:"note,omitempty"`
+	Data        datatypes.JSON `json:"data,omitempty"`
+}
+
+// AssignmentEntry is a ReportAssignment with technician and admin names resolved.
+type AssignmentEntry struct {
+	ReportAssignment
+	TeknisiName    string `json:"teknisi_name"`
+	AssignedByName string `json:"assigned_by_name"`
+}
+
+// ListQuery binds query parameters shared by the admin and technician report listings.
+type ListQuery struct {
+	Status    string `form:"status"`
+	TeknisiID uint64 `form:"teknisi_id"`
+	PartnerID uint64 `form:"partner_id"`
+	Priority  string `form:"priority" binding:"omitempty,oneof=low normal high critical"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Customer  string `form:"customer"`
+	Serial    string `form:"serial"`
+	Device    string `form:"device"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=opened_at updated_at completed_at"`
+	Order     string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" binding:"omitempty,min=1,max=200"`
+}
+
+// PageMeta describes the page returned by a list endpoint.
+type PageMeta struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// SearchQuery binds the report search endpoint parameters.
+type SearchQuery struct {
+	Q        string `form:"q" binding:"required"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=200"`
+}
+
+// SearchHit is a report matching a search query with a highlighted snippet.
+type SearchHit struct {
+	ID           uint64    `json:"id"`
+	DispatchNo   string    `json:"dispatch_no"`
+	Status       string    `json:"status"`
+	CustomerName string    `json:"customer_name"`
+	DeviceName   string    `json:"device_name"`
+	SerialNumber string    `json:"serial_number"`
+	OpenedAt     time.Time `json:"opened_at"`
+	Score        float64   `json:"score"`
+	Snippet      string    `json:"snippet"`
+}
+
+// ReportPartsView groups the normalized payload rows of one report.
+type ReportPartsView struct {
+	Spareparts []ReportSparePart `json:"spareparts"`
+	Tools      []ReportTool      `json:"tools"`
+	Devices    []ReportDevice    `json:"devices"`
+}
+
+// PartsUsageQuery binds the parts usage endpoint parameters.
+type PartsUsageQuery struct {
+	From    string `form:"from"`
+	To      string `form:"to"`
+	PartNo  string `form:"part_no"`
+	Serial  string `form:"serial"`
+	Status  string `form:"status"`
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=part device month"`
+}
+
+// PartsUsageRow is one aggregate of spare part consumption.
+type PartsUsageRow struct {
+	PartNo       string  `json:"part_no"`
+	Description  string  `json:"description"`
+	TotalQty     float64 `json:"total_qty"`
+	ReportCount  int64   `json:"report_count"`
+	SerialNumber string  `json:"serial_number,omitempty"`
+	DeviceName   string  `json:"device_name,omitempty"`
+	Month        string  `json:"month,omitempty"`
+}
+
+// TimesheetTotalsQuery binds the timesheet totals endpoint parameters.
+type TimesheetTotalsQuery struct {
+	From      string  `form:"from"`
+	To        string  `form:"to"`
+	TeknisiID *uint64 `form:"teknisi_id"`
+	PartnerID *uint64 `form:"partner_id"`
+	GroupBy   string  `form:"group_by" binding:"omitempty,oneof=teknisi partner month"`
+}
+
+// TimesheetTotalsRow is one aggregate of technician time.
+type TimesheetTotalsRow struct {
+	TeknisiID         *uint64 `json:"teknisi_id,omitempty"`
+	TeknisiName       string  `json:"teknisi_name,omitempty"`
+	PartnerLocationID *uint64 `json:"partner_location_id,omitempty"`
+	PartnerName       string  `json:"partner_name,omitempty"`
+	Month             string  `json:"month,omitempty"`
+	ReportCount       int64   `json:"report_count"`
+	TravelMinutes     int64   `json:"travel_minutes"`
+	WaitingMinutes    int64   `json:"waiting_minutes"`
+	WorkMinutes       int64   `json:"work_minutes"`
+	ReturnMinutes     int64   `json:"return_minutes"`
+	TravelHours       float64 `json:"travel_hours" gorm:"-"`
+	WaitingHours      float64 `json:"waiting_hours" gorm:"-"`
+	WorkHours         float64 `json:"work_hours" gorm:"-"`
+	ReturnHours       float64 `json:"return_hours" gorm:"-"`
+	TotalHours        float64 `json:"total_hours" gorm:"-"`
+}
+
+// ExportQuery binds the export parameters on top of the list filters. Columns is a comma
+// separated list of column keys.
+type ExportQuery struct {
+	Format  string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+	Columns string `form:"columns"`
+	Async   bool   `form:"async"`
+}
+
+// ExportColumnInfo describes a selectable export column.
+type ExportColumnInfo struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// ExportJobResponse is an export job with its download link once finished.
+type ExportJobResponse struct {
+	*ExportJob
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// StageMetric is the time from report creation to one turnaround stage. Minutes stay
+// empty while the stage has not been reached.
+type StageMetric struct {
+	Stage           string     `json:"stage"`
+	EndedAt         *time.Time `json:"ended_at"`
+	ElapsedMinutes  *int64     `json:"elapsed_minutes"`
+	BusinessMinutes *int64     `json:"business_minutes"`
+}
+
+// ReportMetrics lists the turnaround stages of a report.
+type ReportMetrics struct {
+	ReportID      uint64        `json:"report_id"`
+	OpenedAt      time.Time     `json:"opened_at"`
+	BusinessClock bool          `json:"business_clock"`
+	Stages        []StageMetric `json:"stages"`
 }
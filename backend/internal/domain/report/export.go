@@ -0,0 +1,586 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/pkg/schedule"
+	"github.com/company/internal-service-report/pkg/tabular"
+)
+
+// Export job states.
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportDone    = "done"
+	ExportFailed  = "failed"
+)
+
+const (
+	exportBatchSize = 500
+	// exportRetention is how long finished export files stay downloadable.
+	exportRetention = 7 * 24 * time.Hour
+	// ExportExpiryInterval is how often expired export files are removed.
+	ExportExpiryInterval = time.Hour
+)
+
+var (
+	ErrExportColumn      = errors.New("unknown export column")
+	ErrExportNotFound    = errors.New("export not found")
+	ErrExportNotReady    = errors.New("export is not ready")
+	ErrExportUnavailable = errors.New("export file is no longer available")
+)
+
+// ExportJob is a report export generated in the background for large result sets.
+type ExportJob struct {
+	ID          uint64         `gorm:"primaryKey" json:"id"`
+	RequestedBy uint64         `gorm:"index" json:"requested_by"`
+	Format      string         `gorm:"size:8" json:"format"`
+	Columns     string         `gorm:"type:text" json:"columns"`
+	Filter      datatypes.JSON `gorm:"type:json" json:"filter"`
+	Status      string         `gorm:"size:16;index" json:"status"`
+	RowCount    int64          `json:"row_count"`
+	FilePath    string         `gorm:"size:255" json:"-"`
+	FileName    string         `gorm:"size:255" json:"file_name"`
+	Error       string         `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+}
+
+// ExportRequest selects the reports, columns and file format of an export.
+type ExportRequest struct {
+	Filter  ListFilter
+	Columns []string
+	Format  string
+}
+
+// exportRow is a report with the related rows its columns may print.
+type exportRow struct {
+	report      *ServiceReport
+	names       map[uint64]string
+	partners    map[uint64]string
+	parts       []ReportSparePart
+	tools       []ReportTool
+	sheet       *ReportTimesheet
+	teknisiData interface{}
+	formData    interface{}
+}
+
+type exportColumn struct {
+	Key     string
+	Label   string
+	numeric bool
+	value   func(r *exportRow) string
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func exportName(names map[uint64]string, id *uint64) string {
+	if id == nil {
+		return ""
+	}
+	return names[*id]
+}
+
+func exportHours(minutes func(s *ReportTimesheet) int64) func(r *exportRow) string {
+	return func(r *exportRow) string {
+		if r.sheet == nil {
+			return ""
+		}
+		return strconv.FormatFloat(minutesToHours(minutes(r.sheet)), 'f', -1, 64)
+	}
+}
+
+func exportSheetTime(at func(s *ReportTimesheet) *time.Time) func(r *exportRow) string {
+	return func(r *exportRow) string {
+		if r.sheet == nil {
+			return ""
+		}
+		return exportTime(at(r.sheet))
+	}
+}
+
+// exportColumns lists the fixed export columns in their default order. Payload values are
+// selected with payload.<path> for the technician form and form.<path> for the dispatch
+// form, e.g. payload.deviceRows.serialNo.
+var exportColumns = []exportColumn{
+	{Key: "id", Label: "ID", value: func(r *exportRow) string { return strconv.FormatUint(r.report.ID, 10) }},
+	{Key: "dispatch_no", Label: "Dispatch No", value: func(r *exportRow) string { return r.report.DispatchNo }},
+	{Key: "status", Label: "Status", value: func(r *exportRow) string { return r.report.Status }},
+	{Key: "priority", Label: "Priority", value: func(r *exportRow) string { return r.report.Priority }},
+	{Key: "opened_at", Label: "Opened At", value: func(r *exportRow) string { return exportTime(&r.report.OpenedAt) }},
+	{Key: "completed_at", Label: "Completed At", value: func(r *exportRow) string { return exportTime(r.report.CompletedAt) }},
+	{Key: "customer_name", Label: "Customer", value: func(r *exportRow) string { return r.report.CustomerName }},
+	{Key: "customer_address", Label: "Customer Address", value: func(r *exportRow) string { return r.report.CustomerAddress }},
+	{Key: "customer_contact", Label: "Customer Contact", value: func(r *exportRow) string { return r.report.CustomerContact }},
+	{Key: "partner_name", Label: "Partner", value: func(r *exportRow) string { return exportName(r.partners, r.report.PartnerLocationID) }},
+	{Key: "device_name", Label: "Device", value: func(r *exportRow) string { return r.report.DeviceName }},
+	{Key: "serial_number", Label: "Serial Number", value: func(r *exportRow) string { return r.report.SerialNumber }},
+	{Key: "device_location", Label: "Device Location", value: func(r *exportRow) string { return r.report.DeviceLocation }},
+	{Key: "complaint", Label: "Complaint", value: func(r *exportRow) string { return r.report.Complaint }},
+	{Key: "technician_name", Label: "Technician", value: func(r *exportRow) string { return exportName(r.names, r.report.TeknisiID) }},
+	{Key: "admin_name", Label: "Admin", value: func(r *exportRow) string { return r.names[r.report.AdminID] }},
+	{Key: "approved_by_name", Label: "Approved By", value: func(r *exportRow) string { return exportName(r.names, r.report.ApprovedBy) }},
+	{Key: "spare_parts", Label: "Spare Parts", value: func(r *exportRow) string {
+		items := make([]string, 0, len(r.parts))
+		for _, p := range r.parts {
+			qty := p.QtyText
+			if qty == "" {
+				qty = strconv.FormatFloat(p.Qty, 'f', -1, 64)
+			}
+			items = append(items, strings.TrimSpace(fmt.Sprintf("%s %s x%s", p.PartNo, p.Description, qty)))
+		}
+		return strings.Join(items, "; ")
+	}},
+	{Key: "spare_parts_qty", Label: "Spare Parts Qty", numeric: true, value: func(r *exportRow) string {
+		total := 0.0
+		for _, p := range r.parts {
+			total += p.Qty
+		}
+		return strconv.FormatFloat(total, 'f', -1, 64)
+	}},
+	{Key: "tools", Label: "Tools", value: func(r *exportRow) string {
+		items := make([]string, 0, len(r.tools))
+		for _, t := range r.tools {
+			items = append(items, strings.TrimSpace(t.Code+" "+t.Description))
+		}
+		return strings.Join(items, "; ")
+	}},
+	{Key: "travel_start", Label: "Travel Start", value: exportSheetTime(func(s *ReportTimesheet) *time.Time { return s.TravelStart })},
+	{Key: "travel_finish", Label: "Travel Finish", value: exportSheetTime(func(s *ReportTimesheet) *time.Time { return s.TravelFinish })},
+	{Key: "work_start", Label: "Work Start", value: exportSheetTime(func(s *ReportTimesheet) *time.Time { return s.WorkStart })},
+	{Key: "work_finish", Label: "Work Finish", value: exportSheetTime(func(s *ReportTimesheet) *time.Time { return s.WorkFinish })},
+	{Key: "return_finish", Label: "Return Finish", value: exportSheetTime(func(s *ReportTimesheet) *time.Time { return s.ReturnFinish })},
+	{Key: "travel_hours", Label: "Travel Hours", numeric: true, value: exportHours(func(s *ReportTimesheet) int64 { return s.TravelMinutes })},
+	{Key: "waiting_hours", Label: "Waiting Hours", numeric: true, value: exportHours(func(s *ReportTimesheet) int64 { return s.WaitingMinutes })},
+	{Key: "work_hours", Label: "Work Hours", numeric: true, value: exportHours(func(s *ReportTimesheet) int64 { return s.WorkMinutes })},
+	{Key: "return_hours", Label: "Return Hours", numeric: true, value: exportHours(func(s *ReportTimesheet) int64 { return s.ReturnMinutes })},
+}
+
+// defaultExportColumns is used when no columns are selected.
+var defaultExportColumns = []string{
+	"dispatch_no", "status", "priority", "opened_at", "completed_at", "customer_name",
+	"partner_name", "device_name", "serial_number", "technician_name",
+}
+
+// ExportColumns returns the fixed columns an export can select.
+func ExportColumns() []ExportColumnInfo {
+	out := make([]ExportColumnInfo, len(exportColumns))
+	for i, c := range exportColumns {
+		out[i] = ExportColumnInfo{Key: c.Key, Label: c.Label}
+	}
+	return out
+}
+
+// resolveExportColumns maps selected keys to columns, in the order given.
+func resolveExportColumns(keys []string) ([]exportColumn, error) {
+	if len(keys) == 0 {
+		keys = defaultExportColumns
+	}
+	byKey := make(map[string]exportColumn, len(exportColumns))
+	for _, c := range exportColumns {
+		byKey[c.Key] = c
+	}
+	columns := make([]exportColumn, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if c, ok := byKey[key]; ok {
+			columns = append(columns, c)
+			continue
+		}
+		if path, ok := strings.CutPrefix(key, "payload."); ok && path != "" {
+			columns = append(columns, payloadColumn(key, path, func(r *exportRow) interface{} { return r.teknisiData }))
+			continue
+		}
+		if path, ok := strings.CutPrefix(key, "form."); ok && path != "" {
+			columns = append(columns, payloadColumn(key, path, func(r *exportRow) interface{} { return r.formData }))
+			continue
+		}
+		return nil, fmt.Errorf("%w %q", ErrExportColumn, key)
+	}
+	return columns, nil
+}
+
+// payloadColumn prints the values found at a dotted path. Arrays are walked element by
+// element unless the path names an index, and multiple values are joined with "; ".
+func payloadColumn(key, path string, data func(r *exportRow) interface{}) exportColumn {
+	segments := strings.Split(path, ".")
+	return exportColumn{Key: key, Label: key, value: func(r *exportRow) string {
+		values := lookupPayload(data(r), segments)
+		out := make([]string, 0, len(values))
+		for _, v := range values {
+			if text := payloadValueText(v); text != "" {
+				out = append(out, text)
+			}
+		}
+		return strings.Join(out, "; ")
+	}}
+}
+
+func lookupPayload(v interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{v}
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		child, ok := t[path[0]]
+		if !ok {
+			return nil
+		}
+		return lookupPayload(child, path[1:])
+	case []interface{}:
+		if i, err := strconv.Atoi(path[0]); err == nil {
+			if i < 0 || i >= len(t) {
+				return nil
+			}
+			return lookupPayload(t[i], path[1:])
+		}
+		var out []interface{}
+		for _, item := range t {
+			out = append(out, lookupPayload(item, path)...)
+		}
+		return out
+	}
+	return nil
+}
+
+func payloadValueText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			if text := payloadValueText(item); text != "" {
+				out = append(out, text)
+			}
+		}
+		return strings.Join(out, "; ")
+	default:
+		raw, _ := json.Marshal(t)
+		return string(raw)
+	}
+}
+
+// UseExportStore sets where background exports are written and the number of matching
+// reports above which an export runs in the background. The directory must not be served
+// publicly; files are downloaded through the export endpoints.
+func (s *Service) UseExportStore(dir string, asyncRows int) {
+	s.exportDir = dir
+	s.exportAsyncRows = asyncRows
+}
+
+// ExportInBackground reports whether an export of count reports should become a job.
+func (s *Service) ExportInBackground(count int64) bool {
+	return s.exportAsyncRows > 0 && count > int64(s.exportAsyncRows)
+}
+
+func (s *Service) exportQuery(ctx context.Context, filter ListFilter) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&ServiceReport{})
+	if filter.TeknisiID != nil {
+		query = query.Scopes(s.assignedTo(*filter.TeknisiID))
+	}
+	return applyListFilter(query, filter)
+}
+
+// CountExport returns the number of reports an export would contain.
+func (s *Service) CountExport(ctx context.Context, filter ListFilter) (int64, error) {
+	var count int64
+	err := s.exportQuery(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// ValidateExport checks the format and columns of an export request.
+func ValidateExport(req ExportRequest) error {
+	if req.Format != tabular.FormatCSV && req.Format != tabular.FormatXLSX {
+		return tabular.ErrUnsupportedFile
+	}
+	_, err := resolveExportColumns(req.Columns)
+	return err
+}
+
+// WriteExport streams the matching reports to w in report ID order and returns the number
+// of rows written.
+func (s *Service) WriteExport(ctx context.Context, req ExportRequest, w io.Writer) (int64, error) {
+	columns, err := resolveExportColumns(req.Columns)
+	if err != nil {
+		return 0, err
+	}
+	out, err := tabular.NewWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	header := make([]string, len(columns))
+	needPayload := false
+	for i, c := range columns {
+		header[i] = c.Label
+		needPayload = needPayload || strings.Contains(c.Key, ".")
+		if c.numeric {
+			out.Numeric(i)
+		}
+	}
+	if err := out.Write(header); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	var reports []ServiceReport
+	err = s.exportQuery(ctx, req.Filter).FindInBatches(&reports, exportBatchSize, func(_ *gorm.DB, _ int) error {
+		rows, err := s.loadExportRows(ctx, reports, needPayload)
+		if err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		for i := range rows {
+			for j, c := range columns {
+				record[j] = c.value(&rows[i])
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+			count++
+		}
+		return ctx.Err()
+	}).Error
+	if err != nil {
+		return count, err
+	}
+	return count, out.Close()
+}
+
+// loadExportRows fetches names, spare parts, tools and timesheets for a batch of reports.
+func (s *Service) loadExportRows(ctx context.Context, reports []ServiceReport, withPayload bool) ([]exportRow, error) {
+	ids := make([]uint64, len(reports))
+	var userIDs, partnerIDs []uint64
+	for i, r := range reports {
+		ids[i] = r.ID
+		userIDs = append(userIDs, r.AdminID)
+		if r.TeknisiID != nil {
+			userIDs = append(userIDs, *r.TeknisiID)
+		}
+		if r.ApprovedBy != nil {
+			userIDs = append(userIDs, *r.ApprovedBy)
+		}
+		if r.PartnerLocationID != nil {
+			partnerIDs = append(partnerIDs, *r.PartnerLocationID)
+		}
+	}
+	names, err := s.userNames(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	partners := map[uint64]string{}
+	if len(partnerIDs) > 0 {
+		var locations []struct {
+			ID           uint64
+			HospitalName string
+		}
+		if err := s.db.WithContext(ctx).Table("partner_locations").Select("id", "hospital_name").
+			Where("id IN ?", partnerIDs).Scan(&locations).Error; err != nil {
+			return nil, err
+		}
+		for _, l := range locations {
+			partners[l.ID] = l.HospitalName
+		}
+	}
+	var parts []ReportSparePart
+	if err := s.db.WithContext(ctx).Where("report_id IN ?", ids).Order("report_id, position").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	var tools []ReportTool
+	if err := s.db.WithContext(ctx).Where("report_id IN ?", ids).Order("report_id, position").Find(&tools).Error; err != nil {
+		return nil, err
+	}
+	var sheets []ReportTimesheet
+	if err := s.db.WithContext(ctx).Where("report_id IN ?", ids).Find(&sheets).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]exportRow, len(reports))
+	index := make(map[uint64]*exportRow, len(reports))
+	for i := range reports {
+		rows[i] = exportRow{report: &reports[i], names: names, partners: partners}
+		index[reports[i].ID] = &rows[i]
+		if withPayload {
+			_ = json.Unmarshal(reports[i].TeknisiPayload, &rows[i].teknisiData)
+			_ = json.Unmarshal(reports[i].FormPayload, &rows[i].formData)
+		}
+	}
+	for _, p := range parts {
+		index[p.ReportID].parts = append(index[p.ReportID].parts, p)
+	}
+	for _, t := range tools {
+		index[t.ReportID].tools = append(index[t.ReportID].tools, t)
+	}
+	for i := range sheets {
+		index[sheets[i].ReportID].sheet = &sheets[i]
+	}
+	return rows, nil
+}
+
+// ExportFileName names an export file after its creation time.
+func ExportFileName(format string, at time.Time) string {
+	return fmt.Sprintf("reports-%s.%s", at.Format("20060102-150405"), format)
+}
+
+// StartExport records an export job and writes it in the background.
+func (s *Service) StartExport(ctx context.Context, adminID uint64, req ExportRequest) (*ExportJob, error) {
+	if err := ValidateExport(req); err != nil {
+		return nil, err
+	}
+	return s.startExportJob(ctx, adminID, req)
+}
+
+func (s *Service) startExportJob(ctx context.Context, adminID uint64, req ExportRequest) (*ExportJob, error) {
+	filter, err := json.Marshal(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	job := &ExportJob{
+		RequestedBy: adminID,
+		Format:      req.Format,
+		Columns:     strings.Join(req.Columns, ","),
+		Filter:      datatypes.JSON(filter),
+		Status:      ExportPending,
+		FileName:    ExportFileName(req.Format, time.Now()),
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, err
+	}
+	go s.runExport(job.ID, req)
+	return job, nil
+}
+
+func (s *Service) runExport(jobID uint64, req ExportRequest) {
+	ctx := context.Background()
+	jobs := s.db.WithContext(ctx).Model(&ExportJob{}).Where("id = ?", jobID)
+	if err := jobs.Session(&gorm.Session{}).Update("status", ExportRunning).Error; err != nil {
+		log.Printf("report export %d: %v", jobID, err)
+		return
+	}
+	path := filepath.Join(s.exportDir, fmt.Sprintf("export-%d-%s.%s", jobID, randomSuffix(), req.Format))
+	count, err := s.writeExportFile(ctx, req, path)
+	now := time.Now()
+	updates := map[string]interface{}{"finished_at": now, "row_count": count}
+	if err != nil {
+		log.Printf("report export %d: %v", jobID, err)
+		_ = os.Remove(path)
+		updates["status"] = ExportFailed
+		updates["error"] = err.Error()
+	} else {
+		updates["status"] = ExportDone
+		updates["file_path"] = path
+	}
+	if err := jobs.Session(&gorm.Session{}).Updates(updates).Error; err != nil {
+		log.Printf("report export %d: %v", jobID, err)
+	}
+}
+
+func (s *Service) writeExportFile(ctx context.Context, req ExportRequest, path string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if req.Format == FormatZIP {
+		count, err = s.WriteArchiveRange(ctx, req.Filter, f)
+	} else {
+		count, err = s.WriteExport(ctx, req, f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
+
+// GetExportJob returns an export job.
+func (s *Service) GetExportJob(ctx context.Context, id uint64) (*ExportJob, error) {
+	var job ExportJob
+	if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExportFile returns the file of a finished export job.
+func (s *Service) ExportFile(ctx context.Context, id uint64) (*ExportJob, error) {
+	job, err := s.GetExportJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != ExportDone {
+		return nil, ErrExportNotReady
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		return nil, ErrExportUnavailable
+	}
+	return job, nil
+}
+
+// FailInterruptedExports fails jobs left pending or running by a restart. It must run
+// before the server accepts requests, or it would also fail exports started since.
+func (s *Service) FailInterruptedExports(ctx context.Context) error {
+	return s.db.WithContext(ctx).Model(&ExportJob{}).
+		Where("status IN ?", []string{ExportPending, ExportRunning}).
+		Updates(map[string]interface{}{"status": ExportFailed, "error": "interrupted by a server restart", "finished_at": time.Now()}).Error
+}
+
+// ExpireExports removes export jobs older than the retention period and their files.
+func (s *Service) ExpireExports(ctx context.Context) error {
+	var expired []ExportJob
+	if err := s.db.WithContext(ctx).Where("created_at < ?", time.Now().Add(-exportRetention)).Find(&expired).Error; err != nil {
+		return err
+	}
+	for _, job := range expired {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("report export %d: remove file: %v", job.ID, err)
+			}
+		}
+		if err := s.db.WithContext(ctx).Delete(&ExportJob{}, job.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunExportExpiry expires old exports every interval until ctx is cancelled.
+func (s *Service) RunExportExpiry(ctx context.Context, interval time.Duration) {
+	schedule.Every(ctx, interval, func(ctx context.Context) {
+		if err := s.ExpireExports(ctx); err != nil {
+			log.Printf("report export: expiry failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookupPayload(t *testing.T) {
+	var payload interface{}
+	if err := json.Unmarshal([]byte(`{
+		"customerName": "RSUD Dr. Soetomo",
+		"deviceRows": [
+			{"serialNo": "SN-1", "parts": [{"partNo": "P-1"}, {"partNo": "P-2"}]},
+			{"serialNo": "SN-2", "parts": []},
+			{"serialNo": null}
+		],
+		"checks": {"power": true, "voltage": 220.5}
+	}`), &payload); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"customerName", []interface{}{"RSUD Dr. Soetomo"}},
+		{"checks.voltage", []interface{}{220.5}},
+		{"checks.power", []interface{}{true}},
+		{"deviceRows.serialNo", []interface{}{"SN-1", "SN-2", nil}},
+		{"deviceRows.1.serialNo", []interface{}{"SN-2"}},
+		{"deviceRows.parts.partNo", []interface{}{"P-1", "P-2"}},
+		{"deviceRows.0.parts.1.partNo", []interface{}{"P-2"}},
+		{"deviceRows.3.serialNo", nil},
+		{"deviceRows.-1.serialNo", nil},
+		{"missing", nil},
+		{"customerName.first", nil},
+	}
+	for _, tt := range tests {
+		got := lookupPayload(payload, strings.Split(tt.path, "."))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupPayload(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPayloadColumn(t *testing.T) {
+	var payload interface{}
+	if err := json.Unmarshal([]byte(`{"deviceRows":[{"serialNo":" SN-1 "},{"serialNo":""},{"serialNo":12}]}`), &payload); err != nil {
+		t.Fatal(err)
+	}
+	column := payloadColumn("payload.deviceRows.serialNo", "deviceRows.serialNo", func(r *exportRow) interface{} { return r.teknisiData })
+	if got := column.value(&exportRow{teknisiData: payload}); got != "SN-1; 12" {
+		t.Errorf("value = %q, want %q", got, "SN-1; 12")
+	}
+}
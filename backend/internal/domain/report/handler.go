@@ -1,9 +1,17 @@
 package report
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/company/internal-service-report/internal/domain/user"
 	"github.com/company/internal-service-report/pkg/response"
+	"github.com/company/internal-service-report/pkg/tabular"
 	"github.com/gin-gonic/gin"
 )
 
@@ -91,6 +99,10 @@ func (h *Handler) UploadAttachment(c *gin.Context) {
 			response.ForbiddenWithMessage(c, "report not assigned to this technician")
 			return
 		}
+		if err == ErrReportLocked {
+			response.Conflict(c, err.Error())
+			return
+		}
 		if err == ErrReportNotFound {
 			response.NotFound(c, "report not found")
 			return
@@ -172,7 +184,11 @@ func (h *Handler) DeleteAttachment(c *gin.Context) {
 
 	if err := h.svc.DeleteAttachment(c.Request.Context(), uri.ID, teknisiID, uri.AttachmentID); err != nil {
 		if err == ErrReportForbidden {
-			response.ForbiddenWithMessage(c, "report finalized or not assigned")
+			response.ForbiddenWithMessage(c, "report not assigned to this technician")
+			return
+		}
+		if err == ErrReportLocked {
+			response.Conflict(c, err.Error())
 			return
 		}
 		if err == ErrReportNotFound {
@@ -199,12 +215,19 @@ func (h *Handler) SaveTechnicianForm(c *gin.Context) {
 		response.BadRequest(c, err)
 		return
 	}
-	report, err := h.svc.SaveTechnicianPayload(c.Request.Context(), uri.ID, teknisiID, req.Payload)
+	report, err := h.svc.SaveTechnicianPayload(c.Request.Context(), uri.ID, teknisiID, req)
 	if err != nil {
+		if writePayloadError(c, err) {
+			return
+		}
 		if err == ErrReportForbidden {
 			response.ForbiddenWithMessage(c, "report not assigned to this technician")
 			return
 		}
+		if err == ErrReportLocked {
+			response.Conflict(c, err.Error())
+			return
+		}
 		if err == ErrReportNotFound {
 			response.NotFound(c, "report not found")
 			return
@@ -228,30 +251,111 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 	report, err := h.svc.Create(c.Request.Context(), adminID, req)
 	if err != nil {
-		response.InternalError(c, err)
+		if writePayloadError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, ErrDeviceNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, ErrDeviceAmbiguous):
+			response.UnprocessableEntity(c, err.Error())
+		case errors.Is(err, ErrCustomerNotFound), errors.Is(err, ErrContactNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, ErrCustomerDetailsMissing):
+			response.BadRequest(c, err)
+		default:
+			response.InternalError(c, err)
+		}
 		return
 	}
 	response.Created(c, report)
 }
 
 func (h *Handler) List(c *gin.Context) {
-	filter := ListFilter{Status: c.Query("status")}
-	reports, err := h.svc.List(c.Request.Context(), filter)
+	filter, err := bindListFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	result, err := h.svc.List(c.Request.Context(), filter)
 	if err != nil {
 		response.InternalError(c, err)
 		return
 	}
-	response.OK(c, reports)
+	response.OKWithMeta(c, result.Items, pageMeta(result))
 }
 
 func (h *Handler) ListAssigned(c *gin.Context) {
 	teknisiID := c.GetUint64("userID")
-	reports, err := h.svc.ListAssigned(c.Request.Context(), teknisiID)
+	filter, err := bindListFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	result, err := h.svc.ListAssigned(c.Request.Context(), teknisiID, filter)
 	if err != nil {
 		response.InternalError(c, err)
 		return
 	}
-	response.OK(c, reports)
+	response.OKWithMeta(c, result.Items, pageMeta(result))
+}
+
+func bindListFilter(c *gin.Context) (ListFilter, error) {
+	var q ListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		return ListFilter{}, err
+	}
+	from, err := ParseDateParam(q.From, false)
+	if err != nil {
+		return ListFilter{}, fmt.Errorf("invalid from date: %w", err)
+	}
+	to, err := ParseDateParam(q.To, true)
+	if err != nil {
+		return ListFilter{}, fmt.Errorf("invalid to date: %w", err)
+	}
+
+	filter := ListFilter{
+		From:     from,
+		To:       to,
+		Customer: q.Customer,
+		Serial:   q.Serial,
+		Device:   q.Device,
+		Priority: q.Priority,
+		Sort:     q.Sort,
+		Desc:     q.Order != "asc",
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}
+	for _, status := range strings.Split(q.Status, ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+		if !IsValidStatus(status) {
+			return ListFilter{}, fmt.Errorf("unknown status %q", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	if q.TeknisiID != 0 {
+		filter.TeknisiID = &q.TeknisiID
+	}
+	if q.PartnerID != 0 {
+		filter.PartnerID = &q.PartnerID
+	}
+	return filter, nil
+}
+
+func pageMeta(result *ListResult) PageMeta {
+	pages := int64(0)
+	if result.Size > 0 {
+		pages = (result.Total + int64(result.Size) - 1) / int64(result.Size)
+	}
+	return PageMeta{
+		Page:       result.Page,
+		PageSize:   result.Size,
+		Total:      result.Total,
+		TotalPages: pages,
+	}
 }
 
 func (h *Handler) Assign(c *gin.Context) {
@@ -268,8 +372,25 @@ func (h *Handler) Assign(c *gin.Context) {
 		response.BadRequest(c, err)
 		return
 	}
-	report, err := h.svc.Assign(c.Request.Context(), uri.ID, req.TeknisiID, adminID)
+	report, err := h.svc.Assign(c.Request.Context(), uri.ID, adminID, req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
+		if errors.Is(err, user.ErrNotFound) {
+			response.NotFound(c, "technician not found")
+			return
+		}
+		if errors.Is(err, user.ErrNotTechnician) || errors.Is(err, user.ErrInactive) ||
+			errors.Is(err, user.ErrOutsideSubtree) || errors.Is(err, ErrTeknisiOverloaded) {
+			response.UnprocessableEntity(c, err.Error())
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
@@ -292,8 +413,469 @@ func (h *Handler) UpdateProgress(c *gin.Context) {
 	}
 	report, err := h.svc.UpdateProgress(c.Request.Context(), uri.ID, teknisiID, req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, report)
+}
+
+func (h *Handler) UpdateStatus(c *gin.Context) {
+	adminID := c.GetUint64("userID")
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req StatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	report, err := h.svc.UpdateStatus(c.Request.Context(), uri.ID, adminID, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) || err == ErrReviewPending {
+			response.Conflict(c, err.Error())
+			return
+		}
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
 		response.InternalError(c, err)
 		return
 	}
 	response.OK(c, report)
 }
+
+func (h *Handler) Timeline(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	entries, err := h.svc.Timeline(c.Request.Context(), uri.ID)
+	if err != nil {
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, entries)
+}
+
+func (h *Handler) Approve(c *gin.Context) {
+	adminID := c.GetUint64("userID")
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req ApproveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err)
+		return
+	}
+	report, err := h.svc.Approve(c.Request.Context(), uri.ID, adminID, req)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+	response.OK(c, report)
+}
+
+func (h *Handler) Reject(c *gin.Context) {
+	adminID := c.GetUint64("userID")
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	report, err := h.svc.Reject(c.Request.Context(), uri.ID, adminID, req)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+	response.OK(c, report)
+}
+
+func (h *Handler) writeReviewError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidTransition) {
+		response.Conflict(c, "report is not awaiting review")
+		return
+	}
+	if err == ErrReportNotFound {
+		response.NotFound(c, "report not found")
+		return
+	}
+	response.InternalError(c, err)
+}
+
+func (h *Handler) Assignments(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	entries, err := h.svc.ListAssignments(c.Request.Context(), uri.ID)
+	if err != nil {
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, entries)
+}
+
+func (h *Handler) Search(c *gin.Context) {
+	var q SearchQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	hits, total, err := h.svc.Search(c.Request.Context(), q.Q, q.Page, q.PageSize)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	page, size := normalizePage(q.Page, q.PageSize)
+	response.OKWithMeta(c, hits, pageMeta(&ListResult{Total: total, Page: page, Size: size}))
+}
+
+// writePayloadError renders schema validation failures as 422 with field details.
+func writePayloadError(c *gin.Context, err error) bool {
+	var payloadErr *PayloadError
+	if errors.As(err, &payloadErr) {
+		response.ValidationFailed(c, payloadErr.Error(), payloadErr.Fields)
+		return true
+	}
+	if err == ErrUnknownSchemaVersion {
+		response.UnprocessableEntity(c, err.Error())
+		return true
+	}
+	return false
+}
+
+func (h *Handler) Parts(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	parts, err := h.svc.ReportParts(c.Request.Context(), uri.ID)
+	if err != nil {
+		if err == ErrReportNotFound {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, parts)
+}
+
+func (h *Handler) PartsUsage(c *gin.Context) {
+	var q PartsUsageQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	from, err := ParseDateParam(q.From, false)
+	if err != nil {
+		response.BadRequest(c, fmt.Errorf("invalid from date: %w", err))
+		return
+	}
+	to, err := ParseDateParam(q.To, true)
+	if err != nil {
+		response.BadRequest(c, fmt.Errorf("invalid to date: %w", err))
+		return
+	}
+	if q.Status != "" && !IsValidStatus(q.Status) {
+		response.BadRequest(c, fmt.Errorf("unknown status %q", q.Status))
+		return
+	}
+	rows, err := h.svc.PartsUsage(c.Request.Context(), PartsUsageFilter{
+		From:    from,
+		To:      to,
+		PartNo:  q.PartNo,
+		Serial:  q.Serial,
+		Status:  q.Status,
+		GroupBy: q.GroupBy,
+	})
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, rows)
+}
+
+// Metrics returns the turnaround stages of a report in elapsed and business minutes.
+func (h *Handler) Metrics(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	metrics, err := h.svc.Metrics(c.Request.Context(), uri.ID)
+	if err != nil {
+		if errors.Is(err, ErrReportNotFound) {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, metrics)
+}
+
+// Timesheet returns the travel, waiting, work and return times of a report.
+func (h *Handler) Timesheet(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	sheet, err := h.svc.Timesheet(c.Request.Context(), uri.ID)
+	if err != nil {
+		if errors.Is(err, ErrReportNotFound) {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, sheet)
+}
+
+// TimesheetTotals sums timesheet hours per technician, partner or month.
+func (h *Handler) TimesheetTotals(c *gin.Context) {
+	var q TimesheetTotalsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	from, err := ParseDateParam(q.From, false)
+	if err != nil {
+		response.BadRequest(c, fmt.Errorf("invalid from date: %w", err))
+		return
+	}
+	to, err := ParseDateParam(q.To, true)
+	if err != nil {
+		response.BadRequest(c, fmt.Errorf("invalid to date: %w", err))
+		return
+	}
+	rows, err := h.svc.TimesheetTotals(c.Request.Context(), TimesheetTotalsFilter{
+		From:      from,
+		To:        to,
+		TeknisiID: q.TeknisiID,
+		PartnerID: q.PartnerID,
+		GroupBy:   q.GroupBy,
+	})
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, rows)
+}
+
+// Export streams the reports matching the list filters as CSV or XLSX. Exports larger than
+// the configured threshold, or requested with async=true, run as a background job and
+// answer 202 with the job to poll.
+func (h *Handler) Export(c *gin.Context) {
+	filter, err := bindListFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var q ExportQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	req := ExportRequest{Filter: filter, Format: q.Format}
+	if req.Format == "" {
+		req.Format = tabular.FormatCSV
+	}
+	for _, key := range strings.Split(q.Columns, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			req.Columns = append(req.Columns, key)
+		}
+	}
+	if err := ValidateExport(req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := h.svc.CountExport(ctx, filter)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	if q.Async || h.svc.ExportInBackground(count) {
+		job, err := h.svc.StartExport(ctx, c.GetUint64("userID"), req)
+		if err != nil {
+			response.InternalError(c, err)
+			return
+		}
+		response.Accepted(c, exportJobResponse(job))
+		return
+	}
+
+	c.Header("Content-Type", tabular.ContentType(req.Format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ExportFileName(req.Format, time.Now())))
+	c.Status(http.StatusOK)
+	if _, err := h.svc.WriteExport(ctx, req, c.Writer); err != nil {
+		log.Printf("report export: %v", err)
+	}
+}
+
+// ExportColumns lists the fixed columns an export can select.
+func (h *Handler) ExportColumns(c *gin.Context) {
+	response.OK(c, ExportColumns())
+}
+
+// ExportJob returns the state of a background export.
+func (h *Handler) ExportJob(c *gin.Context) {
+	job, ok := h.ownExportJob(c, false)
+	if !ok {
+		return
+	}
+	response.OK(c, exportJobResponse(job))
+}
+
+// DownloadExport sends the file of a finished background export.
+func (h *Handler) DownloadExport(c *gin.Context) {
+	job, ok := h.ownExportJob(c, true)
+	if !ok {
+		return
+	}
+	if job.Format == FormatZIP {
+		c.Header("Content-Type", "application/zip")
+	} else {
+		c.Header("Content-Type", tabular.ContentType(job.Format))
+	}
+	c.FileAttachment(job.FilePath, job.FileName)
+}
+
+// Archive streams a ZIP of the report with its files, status logs and a checksum manifest.
+func (h *Handler) Archive(c *gin.Context) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	ctx := c.Request.Context()
+	report, err := h.svc.GetByID(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, ErrReportNotFound) {
+			response.NotFound(c, "report not found")
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ArchiveFolder(report)+".zip"))
+	c.Status(http.StatusOK)
+	if err := h.svc.WriteArchive(ctx, report, c.Writer); err != nil {
+		log.Printf("report archive %d: %v", report.ID, err)
+	}
+}
+
+// ArchiveRange starts a background archive of the reports opened in a date range of at
+// most maxArchiveDays. It takes the list filters as query parameters and is followed
+// through the export job endpoints.
+func (h *Handler) ArchiveRange(c *gin.Context) {
+	filter, err := bindListFilter(c)
+	if err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	job, err := h.svc.StartArchive(c.Request.Context(), c.GetUint64("userID"), filter)
+	if err != nil {
+		if errors.Is(err, ErrArchiveRange) || errors.Is(err, ErrArchiveRangeTooLong) {
+			response.BadRequest(c, err)
+			return
+		}
+		response.InternalError(c, err)
+		return
+	}
+	response.Accepted(c, exportJobResponse(job))
+}
+
+// ownExportJob loads the export in the URI. Admins only see their own exports.
+func (h *Handler) ownExportJob(c *gin.Context, file bool) (*ExportJob, bool) {
+	var uri struct {
+		ID uint64 `uri:"job_id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return nil, false
+	}
+	var job *ExportJob
+	var err error
+	if file {
+		job, err = h.svc.ExportFile(c.Request.Context(), uri.ID)
+	} else {
+		job, err = h.svc.GetExportJob(c.Request.Context(), uri.ID)
+	}
+	if err == nil && c.GetString("role") != "MASTER_ADMIN" && job.RequestedBy != c.GetUint64("userID") {
+		err = ErrExportNotFound
+	}
+	switch {
+	case err == nil:
+		return job, true
+	case errors.Is(err, ErrExportNotFound):
+		response.NotFound(c, "export not found")
+	case errors.Is(err, ErrExportNotReady):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, ErrExportUnavailable):
+		response.NotFound(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+	return nil, false
+}
+
+func exportJobResponse(job *ExportJob) ExportJobResponse {
+	out := ExportJobResponse{ExportJob: job}
+	if job.Status == ExportDone {
+		out.DownloadURL = fmt.Sprintf("/api/v1/reports/exports/%d/download", job.ID)
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// sortColumns maps accepted sort keys to indexed columns.
+var sortColumns = map[string]string{
+	"opened_at":    "opened_at",
+	"updated_at":   "updated_at",
+	"completed_at": "completed_at",
+}
+
+// ListFilter controls filtering, sorting and paging when listing reports.
+type ListFilter struct {
+	Statuses  []string
+	AdminID   *uint64
+	TeknisiID *uint64
+	PartnerID *uint64
+	Priority  string
+	From      *time.Time
+	To        *time.Time
+	Customer  string
+	Serial    string
+	Device    string
+	Sort      string
+	Desc      bool
+	Page      int
+	PageSize  int
+}
+
+// ListResult is a single page of reports plus the total number of matches.
+type ListResult struct {
+	Items []ServiceReport
+	Total int64
+	Page  int
+	Size  int
+}
+
+func (s *Service) List(ctx context.Context, filter ListFilter) (*ListResult, error) {
+	query := s.db.WithContext(ctx).Model(&ServiceReport{})
+	if filter.TeknisiID != nil {
+		query = query.Scopes(s.assignedTo(*filter.TeknisiID))
+	}
+	return s.paginate(query, filter)
+}
+
+func (s *Service) ListAssigned(ctx context.Context, teknisiID uint64, filter ListFilter) (*ListResult, error) {
+	query := s.db.WithContext(ctx).Model(&ServiceReport{}).Scopes(s.assignedTo(teknisiID))
+	return s.paginate(query, filter)
+}
+
+func (s *Service) paginate(query *gorm.DB, filter ListFilter) (*ListResult, error) {
+	query = applyListFilter(query, filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	page, size := normalizePage(filter.Page, filter.PageSize)
+	column, ok := sortColumns[filter.Sort]
+	if !ok {
+		column = "opened_at"
+	}
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+
+	var reports []ServiceReport
+	if err := query.
+		Order(column + " " + direction).
+		Order("id " + direction).
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return &ListResult{Items: reports, Total: total, Page: page, Size: size}, nil
+}
+
+func applyListFilter(query *gorm.DB, filter ListFilter) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.AdminID != nil {
+		query = query.Where("admin_id = ?", *filter.AdminID)
+	}
+	if filter.PartnerID != nil {
+		query = query.Where("partner_location_id = ?", *filter.PartnerID)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.From != nil {
+		query = query.Where("opened_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("opened_at < ?", *filter.To)
+	}
+	if filter.Customer != "" {
+		query = query.Where("customer_name LIKE ?", likePattern(filter.Customer))
+	}
+	if filter.Serial != "" {
+		query = query.Where("serial_number LIKE ?", likePattern(filter.Serial))
+	}
+	if filter.Device != "" {
+		query = query.Where("device_name LIKE ?", likePattern(filter.Device))
+	}
+	return query
+}
+
+func normalizePage(page, size int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+	return page, size
+}
+
+// likeEscaper escapes the wildcard characters of a LIKE operand.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likePattern wraps term for a substring LIKE match, escaping wildcard characters.
+func likePattern(term string) string {
+	return "%" + likeEscaper.Replace(strings.TrimSpace(term)) + "%"
+}
+
+// ParseDateParam parses a from/to query parameter given as YYYY-MM-DD or RFC3339. Date-only
+// upper bounds cover the whole day. List endpoints in other packages use it too.
+func ParseDateParam(raw string, endOfDay bool) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
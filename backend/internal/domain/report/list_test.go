@@ -0,0 +1,50 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateParam(t *testing.T) {
+	tests := []struct {
+		raw      string
+		endOfDay bool
+		want     string
+		wantErr  bool
+	}{
+		{"", false, "", false},
+		{"   ", true, "", false},
+		{"2024-03-01", false, "2024-03-01T00:00:00", false},
+		{"2024-03-01", true, "2024-03-02T00:00:00", false},
+		{"2024-12-31", true, "2025-01-01T00:00:00", false},
+		{"2024-03-01T08:30:00+07:00", true, "2024-03-01T08:30:00", false},
+		{"01/03/2024", false, "", true},
+		{"2024-02-30", false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateParam(tt.raw, tt.endOfDay)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDateParam(%q, %v) error = %v, wantErr %v", tt.raw, tt.endOfDay, err, tt.wantErr)
+			continue
+		}
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("ParseDateParam(%q, %v) = %v, want nil", tt.raw, tt.endOfDay, got)
+			}
+			continue
+		}
+		if got == nil || got.Format("2006-01-02T15:04:05") != tt.want {
+			t.Errorf("ParseDateParam(%q, %v) = %v, want %s", tt.raw, tt.endOfDay, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateParamDateOnlyIsLocal(t *testing.T) {
+	got, err := ParseDateParam("2024-03-01", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Location() != time.Local {
+		t.Errorf("location = %v, want Local", got.Location())
+	}
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Turnaround stages measured from report creation.
+const (
+	StageResponse   = "response"
+	StageArrival    = "arrival"
+	StageResolution = "resolution"
+	StageTurnaround = "turnaround"
+)
+
+// Milestones are the moments a report reached each service stage, nil until it does.
+type Milestones struct {
+	AssignedAt *time.Time
+	ArrivedAt  *time.Time
+	ResolvedAt *time.Time
+}
+
+// LoadMilestones reads when a report was first assigned to a lead, when the technician
+// arrived on site and when the work was resolved. Arrival comes from the timesheet, since a
+// report already moves to progress when it is assigned. A report under review or done counts
+// as resolved when the technician last sent it to review, or else when it was completed; a
+// rejected review reopens the clock. r must carry its technician payload.
+func LoadMilestones(db *gorm.DB, r *ServiceReport) (Milestones, error) {
+	var m Milestones
+	var lead ReportAssignment
+	if err := db.Where("report_id = ? AND role = ?", r.ID, CrewLead).Order("assigned_at ASC").First(&lead).Error; err == nil {
+		m.AssignedAt = &lead.AssignedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, err
+	}
+
+	var sheet ReportTimesheet
+	if err := db.First(&sheet, "report_id = ?", r.ID).Error; err == nil {
+		m.ArrivedAt = arrivedAt(&sheet)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, err
+	} else if len(r.TeknisiPayload) > 0 {
+		// Reports saved before timesheets were stored are read from the payload itself.
+		if built, _, err := buildTimesheet(r.ID, r.TeknisiPayload, r.OpenedAt); err == nil {
+			m.ArrivedAt = arrivedAt(built)
+		}
+	}
+
+	if r.Status == StatusReview || r.Status == StatusDone {
+		var entry StatusLog
+		err := db.Where("report_id = ? AND `to` = ? AND `from` <> `to`", r.ID, StatusReview).
+			Order("created_at DESC, id DESC").First(&entry).Error
+		switch {
+		case err == nil:
+			m.ResolvedAt = &entry.CreatedAt
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			m.ResolvedAt = r.CompletedAt
+		default:
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// arrivedAt is when a timesheet has the technician on site: the end of travel, or the start
+// of waiting or work when travel was not filled in.
+func arrivedAt(sheet *ReportTimesheet) *time.Time {
+	for _, t := range []*time.Time{sheet.TravelFinish, sheet.WaitingStart, sheet.WorkStart} {
+		if t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// Metrics measures how long a report took to reach each stage, both as elapsed time and,
+// when a business clock is configured, as working time in the partner's region. Response,
+// arrival and resolution end at the report's milestones, and turnaround at completion.
+func (s *Service) Metrics(ctx context.Context, id uint64) (*ReportMetrics, error) {
+	var report ServiceReport
+	if err := s.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	milestones, err := LoadMilestones(s.db.WithContext(ctx), &report)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &ReportMetrics{ReportID: report.ID, OpenedAt: report.OpenedAt, BusinessClock: s.clock != nil}
+	for _, stage := range []struct {
+		name string
+		end  *time.Time
+	}{
+		{StageResponse, milestones.AssignedAt},
+		{StageArrival, milestones.ArrivedAt},
+		{StageResolution, milestones.ResolvedAt},
+		{StageTurnaround, report.CompletedAt},
+	} {
+		m := StageMetric{Stage: stage.name, EndedAt: stage.end}
+		if stage.end != nil {
+			elapsed := int64(stage.end.Sub(report.OpenedAt) / time.Minute)
+			m.ElapsedMinutes = &elapsed
+			if s.clock != nil {
+				d, err := s.clock.BusinessDuration(ctx, report.PartnerLocationID, report.OpenedAt, *stage.end)
+				if err != nil {
+					return nil, err
+				}
+				business := int64(d / time.Minute)
+				m.BusinessMinutes = &business
+			}
+		}
+		metrics.Stages = append(metrics.Stages, m)
+	}
+	return metrics, nil
+}
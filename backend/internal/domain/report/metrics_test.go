@@ -0,0 +1,35 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArrivedAt(t *testing.T) {
+	fallback := time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local)
+	at := func(h, m int) time.Time { return time.Date(2024, 5, 6, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		name    string
+		payload string
+		want    *time.Time
+	}{
+		{"assigned, nothing filled in", `{}`, nil},
+		{"dispatch date only", `{"dispatchDate":"2024-05-06"}`, nil},
+		{"on the road", `{"travelStart":"2024-05-06","travelStartTime":"07:00"}`, nil},
+		{"travel finished", `{"travelFinish":"2024-05-06","travelFinishTime":"08:30","waitingStart":"08:45"}`, ptr(at(8, 30))},
+		{"waiting without travel", `{"waitingStart":"08:45","deviceRows":[{"workStart":"09:00"}]}`, ptr(at(8, 45))},
+		{"work without travel", `{"deviceRows":[{"workStart":"10:00","workFinish":"11:00"},{"workStart":"09:15"}]}`, ptr(at(9, 15))},
+	}
+	for _, tt := range tests {
+		sheet, _, err := buildTimesheet(1, []byte(tt.payload), fallback)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := arrivedAt(sheet)
+		if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+			t.Errorf("%s: arrivedAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
@@ -6,29 +6,42 @@ import (
 	"gorm.io/datatypes"
 )
 
-// ServiceReport stores lifecycle for each maintenance job.
+// ServiceReport stores lifecycle for each maintenance job. ApprovedBy and ApprovedAt
+// record the admin sign-off and are the source of truth for approval; the payload's
+// approvedBy and approvedDate hold what the technician typed and readers fall back to the
+// columns when they are blank.
 type ServiceReport struct {
-	ID              uint64         `gorm:"primaryKey" json:"id"`
-	DispatchNo      string         `gorm:"uniqueIndex;size:32" json:"dispatch_no"`
-	AdminID         uint64         `gorm:"index" json:"admin_id"`
-	TeknisiID       *uint64        `gorm:"index;index:idx_teknisi_opened_at,priority:1" json:"teknisi_id"`
-	CustomerName    string         `gorm:"size:120" json:"customer_name"`
-	CustomerAddress string         `gorm:"size:255" json:"customer_address"`
-	CustomerContact string         `gorm:"size:120" json:"customer_contact"`
-	DeviceName      string         `gorm:"size:100" json:"device_name"`
-	SerialNumber    string         `gorm:"size:100" json:"serial_number"`
-	DeviceLocation  string         `gorm:"size:120" json:"device_location"`
-	Complaint       string         `gorm:"type:text" json:"complaint"`
-	ActionTaken     string         `gorm:"type:text" json:"action_taken"`
-	Status          string         `gorm:"type:enum('open','progress','done');default:'open';index:idx_status_opened_at,priority:1" json:"status"`
-	OpenedAt        time.Time      `gorm:"autoCreateTime;index:idx_opened_at,priority:1;index:idx_status_opened_at,priority:2;index:idx_teknisi_opened_at,priority:2" json:"opened_at"`
-	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	CompletedAt     *time.Time     `json:"completed_at"`
-	FormPayload     datatypes.JSON `gorm:"type:json" json:"form_payload"`
-	TeknisiPayload  datatypes.JSON `gorm:"type:json" json:"teknisi_payload"`
-	Photos          []ReportPhoto  `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"photos"`
-	Attachments     []ReportAttachment `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"attachments"`
-	StatusLogs      []StatusLog    `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"status_logs"`
+	ID                uint64             `gorm:"primaryKey" json:"id"`
+	DispatchNo        string             `gorm:"uniqueIndex;size:64" json:"dispatch_no"`
+	AdminID           uint64             `gorm:"index" json:"admin_id"`
+	TeknisiID         *uint64            `gorm:"index;index:idx_teknisi_opened_at,priority:1" json:"teknisi_id"`
+	CustomerID        *uint64            `gorm:"index" json:"customer_id"`
+	ContactID         *uint64            `gorm:"index" json:"contact_id"`
+	PartnerLocationID *uint64            `gorm:"index" json:"partner_location_id"`
+	CustomerName      string             `gorm:"size:120" json:"customer_name"`
+	CustomerAddress   string             `gorm:"size:255" json:"customer_address"`
+	CustomerContact   string             `gorm:"size:120" json:"customer_contact"`
+	DeviceID          *uint64            `gorm:"index" json:"device_id"`
+	DeviceName        string             `gorm:"size:100" json:"device_name"`
+	SerialNumber      string             `gorm:"size:100" json:"serial_number"`
+	DeviceLocation    string             `gorm:"size:120" json:"device_location"`
+	Complaint         string             `gorm:"type:text" json:"complaint"`
+	Priority          string             `gorm:"size:16;default:'normal';index" json:"priority"`
+	ActionTaken       string             `gorm:"type:text" json:"action_taken"`
+	Status            string             `gorm:"size:32;default:'open';index:idx_status_opened_at,priority:1" json:"status"`
+	OpenedAt          time.Time          `gorm:"autoCreateTime;index:idx_opened_at,priority:1;index:idx_status_opened_at,priority:2;index:idx_teknisi_opened_at,priority:2" json:"opened_at"`
+	UpdatedAt         time.Time          `gorm:"autoUpdateTime;index:idx_updated_at,priority:1" json:"updated_at"`
+	CompletedAt       *time.Time         `gorm:"index:idx_completed_at,priority:1" json:"completed_at"`
+	ApprovedBy        *uint64            `json:"approved_by"`
+	ApprovedAt        *time.Time         `json:"approved_at"`
+	ReviewNote        string             `gorm:"type:text" json:"review_note"`
+	SchemaVersion     int                `gorm:"default:1" json:"schema_version"`
+	FormPayload       datatypes.JSON     `gorm:"type:json" json:"form_payload"`
+	TeknisiPayload    datatypes.JSON     `gorm:"type:json" json:"teknisi_payload"`
+	Photos            []ReportPhoto      `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"photos"`
+	Attachments       []ReportAttachment `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"attachments"`
+	StatusLogs        []StatusLog        `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"status_logs"`
+	Crew              []ReportAssignment `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"crew"`
 }
 
 // ReportPhoto stores uploaded media.
@@ -42,22 +55,132 @@ type ReportPhoto struct {
 
 // ReportAttachment stores uploaded file attachments (pdf/doc/etc).
 type ReportAttachment struct {
-	ID           uint64    `gorm:"primaryKey" json:"id"`
-	ReportID     uint64    `gorm:"index" json:"report_id"`
-	FilePath     string    `gorm:"size:255" json:"file_path"`
-	FileName     string    `gorm:"size:255" json:"file_name"`
-	ContentType  string    `gorm:"size:120" json:"content_type"`
-	Size         int64     `json:"size"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ReportID    uint64    `gorm:"index" json:"report_id"`
+	FilePath    string    `gorm:"size:255" json:"file_path"`
+	FileName    string    `gorm:"size:255" json:"file_name"`
+	ContentType string    `gorm:"size:120" json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // StatusLog tracks transitions.
 type StatusLog struct {
-	ID        uint64 `gorm:"primaryKey"`
-	ReportID  uint64 `gorm:"index"`
-	ChangedBy uint64
-	From      string    `gorm:"size:32"`
-	To        string    `gorm:"size:32"`
-	Note      string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	ReportID  uint64    `gorm:"index" json:"report_id"`
+	ChangedBy uint64    `json:"changed_by"`
+	From      string    `gorm:"size:32" json:"from"`
+	To        string    `gorm:"size:32" json:"to"`
+	Note      string    `gorm:"type:text" json:"note"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Crew roles on a report assignment.
+const (
+	CrewLead   = "lead"
+	CrewHelper = "helper"
+)
+
+// ReportAssignment keeps every technician who held a report, lead or helper.
+// Rows with UnassignedAt == nil form the current crew.
+type ReportAssignment struct {
+	ID             uint64     `gorm:"primaryKey" json:"id"`
+	ReportID       uint64     `gorm:"index:idx_assignment_report,priority:1" json:"report_id"`
+	TeknisiID      uint64     `gorm:"index:idx_assignment_teknisi,priority:1" json:"teknisi_id"`
+	Role           string     `gorm:"size:16" json:"role"`
+	AssignedBy     uint64     `json:"assigned_by"`
+	Reason         string     `gorm:"size:255" json:"reason"`
+	AssignedAt     time.Time  `gorm:"autoCreateTime" json:"assigned_at"`
+	UnassignedAt   *time.Time `gorm:"index:idx_assignment_report,priority:2;index:idx_assignment_teknisi,priority:2" json:"unassigned_at"`
+	UnassignedBy   *uint64    `json:"unassigned_by"`
+	UnassignReason string     `gorm:"size:255" json:"unassign_reason"`
+}
+
+// Report event types recorded alongside status transitions.
+const (
+	EventAssigned           = "assigned"
+	EventCrewUpdated        = "crew_updated"
+	EventPayloadSaved       = "payload_saved"
+	EventAttachmentUploaded = "attachment_uploaded"
+	EventAttachmentDeleted  = "attachment_deleted"
+)
+
+// ReportEvent records non-status activity on a report for the timeline.
+type ReportEvent struct {
+	ID        uint64         `gorm:"primaryKey" json:"id"`
+	ReportID  uint64         `gorm:"index:idx_report_event_created,priority:1" json:"report_id"`
+	ActorID   uint64         `json:"actor_id"`
+	Type      string         `gorm:"size:32" json:"type"`
+	TeknisiID *uint64        `json:"teknisi_id"`
+	Note      string         `gorm:"type:text" json:"note"`
+	Data      datatypes.JSON `gorm:"type:json" json:"data"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index:idx_report_event_created,priority:2" json:"created_at"`
+}
+
+// ReportSearchDoc holds the flattened searchable text of a report.
+type ReportSearchDoc struct {
+	ReportID  uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Content   string    `gorm:"type:mediumtext;index:idx_search_content,class:FULLTEXT"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// ReportSparePart is a spare part row extracted from the technician payload.
+type ReportSparePart struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ReportID    uint64    `gorm:"index" json:"report_id"`
+	Position    int       `json:"position"`
+	PartNo      string    `gorm:"size:100;index" json:"part_no"`
+	Description string    `gorm:"size:255" json:"description"`
+	Qty         float64   `json:"qty"`
+	QtyText     string    `gorm:"size:32" json:"qty_text"`
+	Status      string    `gorm:"size:64" json:"status"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ReportTool is a tool row extracted from the technician payload.
+type ReportTool struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ReportID    uint64    `gorm:"index" json:"report_id"`
+	Position    int       `json:"position"`
+	Code        string    `gorm:"size:100;index" json:"code"`
+	Description string    `gorm:"size:255" json:"description"`
+	UsableLimit string    `gorm:"size:64" json:"usable_limit"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ReportDevice is a device row extracted from the technician payload.
+type ReportDevice struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ReportID    uint64    `gorm:"index" json:"report_id"`
+	Position    int       `json:"position"`
+	PartNo      string    `gorm:"size:100" json:"part_no"`
+	Description string    `gorm:"size:255" json:"description"`
+	SerialNo    string    `gorm:"size:100;index" json:"serial_no"`
+	SWVersion   string    `gorm:"size:64" json:"sw_version"`
+	Location    string    `gorm:"size:120" json:"location"`
+	WorkStart   string    `gorm:"size:32" json:"work_start"`
+	WorkFinish  string    `gorm:"size:32" json:"work_finish"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ReportTimesheet is the travel, waiting, work and return timeline extracted from the
+// technician payload. Minutes are only counted for segments with both ends timed.
+type ReportTimesheet struct {
+	ReportID          uint64     `gorm:"primaryKey;autoIncrement:false" json:"report_id"`
+	TeknisiID         *uint64    `gorm:"index" json:"teknisi_id"`
+	PartnerLocationID *uint64    `gorm:"index" json:"partner_location_id"`
+	SheetDate         *time.Time `gorm:"index" json:"sheet_date"`
+	TravelStart       *time.Time `json:"travel_start"`
+	TravelFinish      *time.Time `json:"travel_finish"`
+	WaitingStart      *time.Time `json:"waiting_start"`
+	WaitingFinish     *time.Time `json:"waiting_finish"`
+	WorkStart         *time.Time `json:"work_start"`
+	WorkFinish        *time.Time `json:"work_finish"`
+	ReturnStart       *time.Time `json:"return_start"`
+	ReturnFinish      *time.Time `json:"return_finish"`
+	TravelMinutes     int64      `json:"travel_minutes"`
+	WaitingMinutes    int64      `json:"waiting_minutes"`
+	WorkMinutes       int64      `json:"work_minutes"`
+	ReturnMinutes     int64      `json:"return_minutes"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -0,0 +1,260 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// payloadText accepts a JSON string or number, which older frontends send interchangeably.
+type payloadText string
+
+func (t *payloadText) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*t = payloadText(strings.TrimSpace(str))
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err == nil {
+		*t = payloadText(num.String())
+		return nil
+	}
+	*t = ""
+	return nil
+}
+
+// payloadRows is the subset of the technician payload that is normalized into tables.
+type payloadRows struct {
+	Spareparts []struct {
+		Qty         payloadText `json:"qty"`
+		PartNo      payloadText `json:"partNo"`
+		Description payloadText `json:"description"`
+		Status      payloadText `json:"status"`
+	} `json:"spareparts"`
+	Tools []struct {
+		Code        payloadText `json:"code"`
+		Description payloadText `json:"description"`
+		UsableLimit payloadText `json:"usableLimit"`
+	} `json:"tools"`
+	DeviceRows []struct {
+		PartNo      payloadText `json:"partNo"`
+		Description payloadText `json:"description"`
+		SerialNo    payloadText `json:"serialNo"`
+		SWVersion   payloadText `json:"swVersion"`
+		Location    payloadText `json:"location"`
+		WorkStart   payloadText `json:"workStart"`
+		WorkFinish  payloadText `json:"workFinish"`
+	} `json:"deviceRows"`
+}
+
+// syncPayloadRows replaces the normalized part, tool and device rows of a report with the
+// rows found in payload. Blank rows left over from the form are skipped.
+func syncPayloadRows(tx *gorm.DB, reportID uint64, payload []byte) error {
+	var rows payloadRows
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &rows); err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{&ReportSparePart{}, &ReportTool{}, &ReportDevice{}} {
+		if err := tx.Where("report_id = ?", reportID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	parts := make([]ReportSparePart, 0, len(rows.Spareparts))
+	for i, r := range rows.Spareparts {
+		if r.PartNo == "" && r.Description == "" {
+			continue
+		}
+		parts = append(parts, ReportSparePart{
+			ReportID:    reportID,
+			Position:    i + 1,
+			PartNo:      strings.ToUpper(string(r.PartNo)),
+			Description: string(r.Description),
+			Qty:         parseQty(string(r.Qty)),
+			QtyText:     string(r.Qty),
+			Status:      string(r.Status),
+		})
+	}
+	tools := make([]ReportTool, 0, len(rows.Tools))
+	for i, r := range rows.Tools {
+		if r.Code == "" && r.Description == "" {
+			continue
+		}
+		tools = append(tools, ReportTool{
+			ReportID:    reportID,
+			Position:    i + 1,
+			Code:        string(r.Code),
+			Description: string(r.Description),
+			UsableLimit: string(r.UsableLimit),
+		})
+	}
+	devices := make([]ReportDevice, 0, len(rows.DeviceRows))
+	for i, r := range rows.DeviceRows {
+		if r.PartNo == "" && r.Description == "" && r.SerialNo == "" {
+			continue
+		}
+		devices = append(devices, ReportDevice{
+			ReportID:    reportID,
+			Position:    i + 1,
+			PartNo:      string(r.PartNo),
+			Description: string(r.Description),
+			SerialNo:    string(r.SerialNo),
+			SWVersion:   string(r.SWVersion),
+			Location:    string(r.Location),
+			WorkStart:   string(r.WorkStart),
+			WorkFinish:  string(r.WorkFinish),
+		})
+	}
+
+	if len(parts) > 0 {
+		if err := tx.Create(&parts).Error; err != nil {
+			return err
+		}
+	}
+	if len(tools) > 0 {
+		if err := tx.Create(&tools).Error; err != nil {
+			return err
+		}
+	}
+	if len(devices) > 0 {
+		if err := tx.Create(&devices).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseQty reads the leading number of a quantity such as "2", "1.5" or "3 pcs".
+func parseQty(raw string) float64 {
+	raw = strings.TrimSpace(strings.ReplaceAll(raw, ",", "."))
+	end := 0
+	for end < len(raw) && (unicode.IsDigit(rune(raw[end])) || raw[end] == '.') {
+		end++
+	}
+	if end == 0 {
+		return 0
+	}
+	qty, err := strconv.ParseFloat(raw[:end], 64)
+	if err != nil {
+		return 0
+	}
+	return qty
+}
+
+// SyncMissingPayloadRows normalizes technician payloads of reports that have no rows yet.
+func (s *Service) SyncMissingPayloadRows(ctx context.Context) error {
+	var reports []ServiceReport
+	return s.db.WithContext(ctx).
+		Select("id", "teknisi_payload").
+		Where("teknisi_payload IS NOT NULL").
+		Where("id NOT IN (?)", s.db.Model(&ReportSparePart{}).Select("report_id")).
+		Where("id NOT IN (?)", s.db.Model(&ReportTool{}).Select("report_id")).
+		Where("id NOT IN (?)", s.db.Model(&ReportDevice{}).Select("report_id")).
+		FindInBatches(&reports, 100, func(tx *gorm.DB, _ int) error {
+			for _, r := range reports {
+				if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+					return syncPayloadRows(tx, r.ID, r.TeknisiPayload)
+				}); err != nil {
+					log.Printf("report parts: sync report %d: %v", r.ID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+// ReportParts returns the normalized rows of one report.
+func (s *Service) ReportParts(ctx context.Context, reportID uint64) (*ReportPartsView, error) {
+	if err := s.db.WithContext(ctx).Select("id").First(&ServiceReport{}, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	view := &ReportPartsView{}
+	db := s.db.WithContext(ctx)
+	if err := db.Where("report_id = ?", reportID).Order("position ASC").Find(&view.Spareparts).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("report_id = ?", reportID).Order("position ASC").Find(&view.Tools).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("report_id = ?", reportID).Order("position ASC").Find(&view.Devices).Error; err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// PartsUsageFilter narrows and groups spare part consumption. An empty Status means done.
+type PartsUsageFilter struct {
+	From    *time.Time
+	To      *time.Time
+	PartNo  string
+	Serial  string
+	Status  string
+	GroupBy string
+}
+
+// PartsUsage aggregates spare part quantities per part number, optionally split by device or month.
+// Only done reports count unless a status is given, since parts on open or cancelled jobs
+// were not consumed. The period applies to the completion date of finished reports and the
+// open date of the rest.
+func (s *Service) PartsUsage(ctx context.Context, filter PartsUsageFilter) ([]PartsUsageRow, error) {
+	period := "COALESCE(service_reports.completed_at, service_reports.opened_at)"
+	query := s.db.WithContext(ctx).
+		Table("report_spare_parts").
+		Joins("JOIN service_reports ON service_reports.id = report_spare_parts.report_id")
+	if filter.From != nil {
+		query = query.Where(period+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(period+" < ?", *filter.To)
+	}
+	if filter.PartNo != "" {
+		query = query.Where("report_spare_parts.part_no = ?", strings.ToUpper(strings.TrimSpace(filter.PartNo)))
+	}
+	if filter.Serial != "" {
+		query = query.Where("service_reports.serial_number = ?", strings.TrimSpace(filter.Serial))
+	}
+	status := filter.Status
+	if status == "" {
+		status = StatusDone
+	}
+	query = query.Where("service_reports.status = ?", status)
+
+	selects := []string{
+		"report_spare_parts.part_no AS part_no",
+		"MAX(report_spare_parts.description) AS description",
+		"SUM(report_spare_parts.qty) AS total_qty",
+		"COUNT(DISTINCT report_spare_parts.report_id) AS report_count",
+	}
+	groups := []string{"report_spare_parts.part_no"}
+	switch filter.GroupBy {
+	case "device":
+		selects = append(selects, "service_reports.serial_number AS serial_number", "MAX(service_reports.device_name) AS device_name")
+		groups = append(groups, "service_reports.serial_number")
+	case "month":
+		selects = append(selects, "DATE_FORMAT("+period+", '%Y-%m') AS month")
+		groups = append(groups, "month")
+	}
+
+	var rows []PartsUsageRow
+	if err := query.
+		Select(strings.Join(selects, ", ")).
+		Group(strings.Join(groups, ", ")).
+		Order("total_qty DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
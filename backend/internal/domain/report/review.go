@@ -0,0 +1,100 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrReviewPending is returned when a report under review is changed outside approve/reject.
+var ErrReviewPending = errors.New("report is awaiting admin review")
+
+// Approve signs off a report under review, making it final and printable. The sign-off is
+// stored in the approved_by and approved_at columns only; the technician payload is not
+// rewritten, so the sealed form stays what the technician submitted.
+func (s *Service) Approve(ctx context.Context, reportID, adminID uint64, req ApproveRequest) (*ServiceReport, error) {
+	var report ServiceReport
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&report, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+		comment := strings.TrimSpace(req.Comment)
+		now := time.Now()
+		note := "Approved"
+		if comment != "" {
+			note = "Approved: " + comment
+		}
+		if err := s.transition(tx, &report, StatusDone, adminID, note, map[string]interface{}{
+			"approved_by": adminID,
+			"approved_at": now,
+			"review_note": comment,
+		}); err != nil {
+			return err
+		}
+		report.ApprovedBy = &adminID
+		report.ApprovedAt = &now
+		report.ReviewNote = comment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Reject sends a report under review back to the technician with the admin's comment.
+// The technician payload loses its finalizedDate so the form becomes editable again.
+func (s *Service) Reject(ctx context.Context, reportID, adminID uint64, req RejectRequest) (*ServiceReport, error) {
+	var report ServiceReport
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&report, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+		comment := strings.TrimSpace(req.Comment)
+		updates := map[string]interface{}{"review_note": comment}
+		if payload, changed := clearFinalizedDate(report.TeknisiPayload); changed {
+			updates["teknisi_payload"] = payload
+			report.TeknisiPayload = payload
+		}
+		if err := s.transition(tx, &report, StatusProgress, adminID, "Rejected: "+comment, updates); err != nil {
+			return err
+		}
+		report.ReviewNote = comment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.indexReport(ctx, &report)
+	return &report, nil
+}
+
+func clearFinalizedDate(payload datatypes.JSON) (datatypes.JSON, bool) {
+	if len(payload) == 0 {
+		return payload, false
+	}
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &root); err != nil {
+		return payload, false
+	}
+	if _, ok := root["finalizedDate"]; !ok {
+		return payload, false
+	}
+	root["finalizedDate"] = json.RawMessage(`""`)
+	out, err := json.Marshal(root)
+	if err != nil {
+		return payload, false
+	}
+	return datatypes.JSON(out), true
+}
@@ -0,0 +1,129 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// CurrentSchemaVersion is the payload schema version used when a client does not send one.
+const CurrentSchemaVersion = 1
+
+// Payload kinds validated against the embedded schemas.
+const (
+	payloadForm    = "form"
+	payloadTeknisi = "teknisi"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// ErrUnknownSchemaVersion is returned when a payload names a schema version the server does not know.
+var ErrUnknownSchemaVersion = errors.New("unknown payload schema version")
+
+// FieldError points at a single invalid value inside a payload.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// PayloadError lists every schema violation found in a payload.
+type PayloadError struct {
+	Kind   string
+	Fields []FieldError
+}
+
+func (e *PayloadError) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("invalid %s payload", e.Kind)
+	}
+	return fmt.Sprintf("invalid %s payload: %s %s", e.Kind, e.Fields[0].Field, e.Fields[0].Message)
+}
+
+// schemas holds compiled schemas keyed by kind and version, e.g. "form.v1".
+var schemas = mustCompileSchemas()
+
+func mustCompileSchemas() map[string]*jsonschema.Schema {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	out := map[string]*jsonschema.Schema{}
+	for _, entry := range entries {
+		raw, err := schemaFiles.ReadFile("schemas/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		url := "mem://schemas/" + entry.Name()
+		if err := compiler.AddResource(url, bytes.NewReader(raw)); err != nil {
+			panic(fmt.Sprintf("report schema %s: %v", name, err))
+		}
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			panic(fmt.Sprintf("report schema %s: %v", name, err))
+		}
+		out[name] = schema
+	}
+	return out
+}
+
+// resolveSchemaVersion returns the version to validate with, defaulting to CurrentSchemaVersion.
+func resolveSchemaVersion(version int) (int, error) {
+	if version == 0 {
+		version = CurrentSchemaVersion
+	}
+	if _, ok := schemas[fmt.Sprintf("%s.v%d", payloadForm, version)]; !ok {
+		return 0, ErrUnknownSchemaVersion
+	}
+	return version, nil
+}
+
+// validatePayload checks payload against the schema for kind and version.
+func validatePayload(kind string, version int, payload []byte) error {
+	schema, ok := schemas[fmt.Sprintf("%s.v%d", kind, version)]
+	if !ok {
+		return ErrUnknownSchemaVersion
+	}
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return &PayloadError{Kind: kind, Fields: []FieldError{{Field: "/", Message: "is not valid JSON"}}}
+	}
+	err := schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	fields := []FieldError{}
+	collectFieldErrors(ve, &fields)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &PayloadError{Kind: kind, Fields: fields}
+}
+
+// collectFieldErrors flattens the validation tree into its leaf errors.
+func collectFieldErrors(ve *jsonschema.ValidationError, out *[]FieldError) {
+	if len(ve.Causes) == 0 {
+		field := ve.InstanceLocation
+		if field == "" {
+			field = "/"
+		}
+		*out = append(*out, FieldError{Field: field, Message: ve.Message})
+		return
+	}
+	for _, cause := range ve.Causes {
+		collectFieldErrors(cause, out)
+	}
+}
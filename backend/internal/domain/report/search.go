@@ -0,0 +1,322 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const snippetRadius = 80
+
+// SearchDocument is the text of one report handed to a SearchIndex.
+type SearchDocument struct {
+	ReportID uint64
+	Content  string
+}
+
+// SearchMatch is a single result returned by a SearchIndex.
+type SearchMatch struct {
+	ReportID uint64
+	Score    float64
+	Content  string
+}
+
+// SearchIndex stores report documents and answers text queries.
+// The default implementation uses a MySQL FULLTEXT index; other engines can be plugged in with UseSearchIndex.
+type SearchIndex interface {
+	Index(ctx context.Context, doc SearchDocument) error
+	Search(ctx context.Context, terms []string, limit, offset int) ([]SearchMatch, int64, error)
+}
+
+// MySQLSearchIndex implements SearchIndex with MATCH ... AGAINST in boolean mode.
+type MySQLSearchIndex struct {
+	db *gorm.DB
+}
+
+func NewMySQLSearchIndex(db *gorm.DB) *MySQLSearchIndex {
+	return &MySQLSearchIndex{db: db}
+}
+
+func (m *MySQLSearchIndex) Index(ctx context.Context, doc SearchDocument) error {
+	row := ReportSearchDoc{ReportID: doc.ReportID, Content: doc.Content}
+	return m.db.WithContext(ctx).Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&row).Error
+}
+
+func (m *MySQLSearchIndex) Search(ctx context.Context, terms []string, limit, offset int) ([]SearchMatch, int64, error) {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, "+"+t+"*")
+	}
+	against := strings.Join(parts, " ")
+	match := "MATCH(content) AGAINST (? IN BOOLEAN MODE)"
+
+	var total int64
+	if err := m.db.WithContext(ctx).Model(&ReportSearchDoc{}).Where(match, against).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ReportID uint64
+		Score    float64
+		Content  string
+	}
+	if err := m.db.WithContext(ctx).Model(&ReportSearchDoc{}).
+		Select("report_id, content, "+match+" AS score", against).
+		Where(match, against).
+		Order("score DESC").
+		Order("report_id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	matches := make([]SearchMatch, 0, len(rows))
+	for _, r := range rows {
+		matches = append(matches, SearchMatch{ReportID: r.ReportID, Score: r.Score, Content: r.Content})
+	}
+	return matches, total, nil
+}
+
+// UseSearchIndex replaces the search backend.
+func (s *Service) UseSearchIndex(idx SearchIndex) {
+	s.search = idx
+}
+
+// Search runs a text query over reports and returns hits with highlighted snippets.
+func (s *Service) Search(ctx context.Context, query string, page, size int) ([]SearchHit, int64, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []SearchHit{}, 0, nil
+	}
+	page, size = normalizePage(page, size)
+	matches, total, err := s.search.Search(ctx, terms, size, (page-1)*size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(matches) == 0 {
+		return []SearchHit{}, total, nil
+	}
+
+	ids := make([]uint64, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ReportID)
+	}
+	var reports []ServiceReport
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint64]ServiceReport, len(reports))
+	for _, r := range reports {
+		byID[r.ID] = r
+	}
+
+	hits := make([]SearchHit, 0, len(matches))
+	for _, m := range matches {
+		r, ok := byID[m.ReportID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			ID:           r.ID,
+			DispatchNo:   r.DispatchNo,
+			Status:       r.Status,
+			CustomerName: r.CustomerName,
+			DeviceName:   r.DeviceName,
+			SerialNumber: r.SerialNumber,
+			OpenedAt:     r.OpenedAt,
+			Score:        m.Score,
+			Snippet:      highlightSnippet(m.Content, terms),
+		})
+	}
+	return hits, total, nil
+}
+
+// indexReport refreshes the search document of a report. Failures are logged, not returned,
+// so a broken index never blocks saving a report.
+func (s *Service) indexReport(ctx context.Context, report *ServiceReport) {
+	if s.search == nil {
+		return
+	}
+	doc := SearchDocument{ReportID: report.ID, Content: buildSearchContent(report)}
+	if err := s.search.Index(ctx, doc); err != nil {
+		log.Printf("report search: index report %d: %v", report.ID, err)
+	}
+}
+
+// ReindexMissing builds search documents for reports that do not have one yet.
+func (s *Service) ReindexMissing(ctx context.Context) error {
+	var reports []ServiceReport
+	return s.db.WithContext(ctx).
+		Where("id NOT IN (?)", s.db.Model(&ReportSearchDoc{}).Select("report_id")).
+		FindInBatches(&reports, 100, func(tx *gorm.DB, _ int) error {
+			for i := range reports {
+				s.indexReport(ctx, &reports[i])
+			}
+			return nil
+		}).Error
+}
+
+// searchPayloadFields lists the payload keys copied into the search document.
+var searchPayloadFields = []string{
+	"customerName", "customerPerson", "department", "address", "problemDescription",
+	"serviceDescription", "recommendation", "conclusion", "changedNote",
+}
+
+// searchPayloadRows lists payload arrays whose row values are indexed.
+var searchPayloadRows = []string{"spareparts", "deviceRows", "tools"}
+
+func buildSearchContent(report *ServiceReport) string {
+	parts := []string{
+		report.DispatchNo,
+		report.CustomerName,
+		report.CustomerAddress,
+		report.CustomerContact,
+		report.DeviceName,
+		report.SerialNumber,
+		report.DeviceLocation,
+		report.Complaint,
+		report.ActionTaken,
+	}
+	for _, payload := range [][]byte{report.FormPayload, report.TeknisiPayload} {
+		var root map[string]any
+		if len(payload) == 0 || json.Unmarshal(payload, &root) != nil {
+			continue
+		}
+		for _, key := range searchPayloadFields {
+			if v, ok := root[key].(string); ok {
+				parts = append(parts, v)
+			}
+		}
+		for _, key := range searchPayloadRows {
+			rows, _ := root[key].([]any)
+			for _, row := range rows {
+				fields, _ := row.(map[string]any)
+				for _, v := range fields {
+					if str, ok := v.(string); ok {
+						parts = append(parts, str)
+					}
+				}
+			}
+		}
+	}
+
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" && !strings.HasPrefix(p, "data:") && !strings.HasPrefix(p, "/uploads/") {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, " · ")
+}
+
+// minSearchTermLen is InnoDB's default innodb_ft_min_token_size. Shorter words are not
+// indexed, so requiring one would make every search fail.
+const minSearchTermLen = 3
+
+// searchStopwords is InnoDB's default full-text stopword list; these words are not indexed
+// either.
+var searchStopwords = map[string]bool{
+	"about": true, "an": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"com": true, "de": true, "en": true, "for": true, "from": true, "how": true, "in": true,
+	"is": true, "it": true, "la": true, "of": true, "on": true, "or": true, "that": true,
+	"the": true, "this": true, "to": true, "was": true, "what": true, "when": true,
+	"where": true, "who": true, "will": true, "with": true, "und": true, "www": true,
+}
+
+// searchTerms splits a user query into terms safe for boolean-mode FULLTEXT.
+// Punctuation such as the dash in "SN-1234" is a word separator for the FULLTEXT parser
+// and an operator in boolean mode (+-"()~*<>@), so it is dropped here, as are words too
+// short to be indexed, stopwords and repeats.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	terms := words[:0]
+	seen := map[string]bool{}
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < minSearchTermLen || searchStopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// highlightSnippet cuts a window around the first matching term and wraps every match in <mark>.
+// The text is HTML-escaped so the snippet can be rendered as-is.
+func highlightSnippet(content string, terms []string) string {
+	lower := strings.ToLower(content)
+	if len(lower) != len(content) {
+		// Case folding changed byte offsets; fall back to case-sensitive matching.
+		lower = content
+	}
+	first := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, strings.ToLower(t)); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+	start := first - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := first + snippetRadius
+	if end > len(content) {
+		end = len(content)
+	}
+	// Keep the window on UTF-8 boundaries.
+	for start > 0 && !isRuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !isRuneStart(content[end]) {
+		end++
+	}
+	window := content[start:end]
+	windowLower := lower[start:end]
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := 0
+	for pos < len(window) {
+		next, length := -1, 0
+		for _, t := range terms {
+			tl := strings.ToLower(t)
+			if i := strings.Index(windowLower[pos:], tl); i >= 0 && (next < 0 || i < next || (i == next && len(tl) > length)) {
+				next, length = i, len(tl)
+			}
+		}
+		if next < 0 {
+			b.WriteString(html.EscapeString(window[pos:]))
+			break
+		}
+		b.WriteString(html.EscapeString(window[pos : pos+next]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(window[pos+next : pos+next+length]))
+		b.WriteString("</mark>")
+		pos += next + length
+	}
+	if end < len(content) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
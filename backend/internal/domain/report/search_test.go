@@ -0,0 +1,69 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"Soetomo", []string{"soetomo"}},
+		{"SN-1234 ventilator", []string{"1234", "ventilator"}},
+		{`+pump -"infusion" (alarm)~ error* <low >high @3`, []string{"pump", "infusion", "alarm", "error", "low", "high"}},
+		{"ab cd efg", []string{"efg"}},
+		{"the pump with the alarm", []string{"pump", "alarm"}},
+		{"Pump pump PUMP", []string{"pump"}},
+		{"ruang ICU lantai 2", []string{"ruang", "icu", "lantai"}},
+		{"pemeriksaan élève", []string{"pemeriksaan", "élève"}},
+	}
+	for _, tt := range tests {
+		got := searchTerms(tt.query)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	long := strings.Repeat("x", 200) + " alarm " + strings.Repeat("y", 200)
+	tests := []struct {
+		name    string
+		content string
+		terms   []string
+		want    string
+	}{
+		{"marks every match", "Pump alarm, pump reset", []string{"pump"}, "<mark>Pump</mark> alarm, <mark>pump</mark> reset"},
+		{"longest term wins", "ventilator", []string{"vent", "ventilator"}, "<mark>ventilator</mark>"},
+		{"escapes html", "<b>pump</b> & co", []string{"pump"}, "&lt;b&gt;<mark>pump</mark>&lt;/b&gt; &amp; co"},
+		{"no match keeps the start", "RS Soetomo", []string{"alarm"}, "RS Soetomo"},
+		{"cuts a window", long, []string{"alarm"}, "…" + strings.Repeat("x", 79) + " <mark>alarm</mark> " + strings.Repeat("y", 74) + "…"},
+	}
+	for _, tt := range tests {
+		if got := highlightSnippet(tt.content, tt.terms); got != tt.want {
+			t.Errorf("%s: highlightSnippet = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSnippetKeepsRunes(t *testing.T) {
+	content := strings.Repeat("é", 100) + "alarm" + strings.Repeat("ü", 100)
+	got := highlightSnippet(content, []string{"alarm"})
+	if !strings.Contains(got, "<mark>alarm</mark>") {
+		t.Errorf("snippet %q lost the match", got)
+	}
+	if !strings.HasPrefix(got, "…é") || !strings.HasSuffix(got, "ü…") {
+		t.Errorf("snippet %q was not cut on rune boundaries", got)
+	}
+	if strings.ContainsRune(got, '�') {
+		t.Errorf("snippet %q has broken runes", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,12 +23,85 @@ import (
 var (
 	ErrReportNotFound  = errors.New("report not found")
 	ErrReportForbidden = errors.New("report forbidden")
+	ErrReportLocked    = errors.New("report is locked for review or finalized")
+	ErrDeviceNotFound  = errors.New("device not found")
+	ErrDeviceAmbiguous = errors.New("serial number matches several devices; pick the device or give manufacturer and model")
+
+	ErrCustomerNotFound       = errors.New("customer not found")
+	ErrContactNotFound        = errors.New("contact not found for this customer")
+	ErrCustomerDetailsMissing = errors.New("customer name, address and contact are required when no customer is selected")
 )
 
+// AssigneeValidator checks that a user may be assigned to reports by the given admin.
+type AssigneeValidator interface {
+	ValidateAssignee(ctx context.Context, adminID, teknisiID uint64) error
+}
+
+// DeviceRef identifies the device a report is about, either by registry ID or by its details.
+type DeviceRef struct {
+	ID                *uint64
+	Manufacturer      string
+	Model             string
+	Name              string
+	Serial            string
+	Location          string
+	PartnerLocationID *uint64
+}
+
+// DeviceResolver maps a DeviceRef to a registered device, creating it when unknown.
+// The returned ref carries the registry ID and canonical details.
+type DeviceResolver interface {
+	ResolveDevice(tx *gorm.DB, ref DeviceRef) (DeviceRef, error)
+}
+
+// CustomerSnapshot holds the customer and contact details copied onto a report at dispatch time.
+type CustomerSnapshot struct {
+	CustomerID        uint64
+	ContactID         *uint64
+	PartnerLocationID uint64
+	Name              string
+	Address           string
+	Person            string
+	Department        string
+	Phone             string
+	Email             string
+}
+
+// CustomerResolver loads the customer registry entry, and optionally a contact, for a new report.
+// When only contactID is given the customer is taken from the contact.
+type CustomerResolver interface {
+	ResolveCustomer(db *gorm.DB, customerID, contactID *uint64) (*CustomerSnapshot, error)
+}
+
+// FinalizeHook runs inside the transaction that moves a report to done.
+// Returning an error rolls the finalization back.
+type FinalizeHook interface {
+	ReportFinalized(tx *gorm.DB, report *ServiceReport, actorID uint64) error
+}
+
+// BusinessClock measures working time between two instants in the calendar of a
+// partner's region; nil partnerID uses the default calendar.
+type BusinessClock interface {
+	BusinessDuration(ctx context.Context, partnerID *uint64, from, to time.Time) (time.Duration, error)
+}
+
 // Service encapsulates business logic for service reports.
 type Service struct {
-	db        *gorm.DB
-	uploadDir string
+	db          *gorm.DB
+	uploadDir   string
+	assignees   AssigneeValidator
+	maxOpenJobs int
+	search      SearchIndex
+	finalizers  []FinalizeHook
+	devices     DeviceResolver
+	customers   CustomerResolver
+	clock       BusinessClock
+	branches    BranchResolver
+	dispatch    *DispatchPattern
+	documents   DocumentRenderer
+
+	exportDir       string
+	exportAsyncRows int
 }
 
 func (s *Service) GetForTechnician(ctx context.Context, reportID, teknisiID uint64) (*ServiceReport, error) {
@@ -35,31 +109,74 @@ func (s *Service) GetForTechnician(ctx context.Context, reportID, teknisiID uint
 	if err := s.db.WithContext(ctx).
 		Preload("Attachments").
 		Preload("Photos").
+		Preload("Crew", activeCrew).
 		First(&report, reportID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrReportNotFound
 		}
 		return nil, err
 	}
-	if report.TeknisiID == nil || *report.TeknisiID != teknisiID {
+	member, err := s.isCrewMember(ctx, &report, teknisiID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
 		return nil, ErrReportForbidden
 	}
 	return &report, nil
 }
 
-func (s *Service) SaveTechnicianPayload(ctx context.Context, reportID, teknisiID uint64, payload []byte) (*ServiceReport, error) {
+func (s *Service) SaveTechnicianPayload(ctx context.Context, reportID, teknisiID uint64, req TechnicianFormRequest) (*ServiceReport, error) {
+	version, err := resolveSchemaVersion(req.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePayload(payloadTeknisi, version, req.Payload); err != nil {
+		return nil, err
+	}
 	report, err := s.GetForTechnician(ctx, reportID, teknisiID)
 	if err != nil {
 		return nil, err
 	}
-	processed, err := s.persistPayloadImages(reportID, report.Status, payload)
+	if IsLocked(report.Status) {
+		return nil, ErrReportLocked
+	}
+	sheet, sheetErrs, err := buildTimesheet(reportID, req.Payload, report.OpenedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sheetErrs) > 0 {
+		return nil, &PayloadError{Kind: payloadTeknisi, Fields: sheetErrs}
+	}
+	processed, err := s.persistPayloadImages(reportID, report.Status, req.Payload)
 	if err != nil {
 		return nil, err
 	}
 	report.TeknisiPayload = datatypes.JSON(processed)
-	if err := s.db.WithContext(ctx).Model(report).Update("teknisi_payload", report.TeknisiPayload).Error; err != nil {
+	report.SchemaVersion = version
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(report).Updates(map[string]interface{}{
+			"teknisi_payload": report.TeknisiPayload,
+			"schema_version":  version,
+		}).Error; err != nil {
+			return err
+		}
+		if err := syncPayloadRows(tx, reportID, processed); err != nil {
+			return err
+		}
+		if err := syncTimesheet(tx, report, sheet); err != nil {
+			return err
+		}
+		return s.recordEvent(tx, ReportEvent{
+			ReportID: reportID,
+			ActorID:  teknisiID,
+			Type:     EventPayloadSaved,
+		}, map[string]interface{}{"size": len(processed)})
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.indexReport(ctx, report)
 	return report, nil
 }
 
@@ -73,11 +190,11 @@ func (s *Service) persistPayloadImages(reportID uint64, status string, payload [
 	dec := json.NewDecoder(bytes.NewReader(payload))
 	dec.UseNumber()
 	if err := dec.Decode(&root); err != nil {
-		return payload, nil
+		return nil, &PayloadError{Kind: "payload", Fields: []FieldError{{Field: "/", Message: "must be a JSON object"}}}
 	}
 
 	folder := "draft"
-	if status == "done" {
+	if status == StatusDone {
 		folder = "finalized"
 	}
 	if finalizedRaw, ok := root["finalizedDate"]; ok {
@@ -142,11 +259,7 @@ func (s *Service) persistPayloadImages(reportID uint64, status string, payload [
 	storeSliceField("afterEvidence", "afterEvidence")
 	storeSliceField("problemPhotos", "problemPhoto")
 
-	out, err := json.Marshal(root)
-	if err != nil {
-		return payload, nil
-	}
-	return out, nil
+	return json.Marshal(root)
 }
 
 func (s *Service) storeImageDataURL(reportID uint64, folder string, label string, value string) (string, error) {
@@ -209,22 +322,79 @@ func (s *Service) storeImageDataURL(reportID uint64, folder string, label string
 	return fmt.Sprintf("/uploads/images/%d/%s/%s", reportID, safeFolder, storedName), nil
 }
 
-func NewService(db *gorm.DB, uploadDir string) *Service {
+// NewService builds the report service. maxOpenJobs <= 0 disables the per-technician workload limit.
+func NewService(db *gorm.DB, uploadDir string, assignees AssigneeValidator, maxOpenJobs int) *Service {
 	if uploadDir == "" {
 		uploadDir = "./uploads"
 	}
-	return &Service{db: db, uploadDir: uploadDir}
+	return &Service{
+		db:          db,
+		uploadDir:   uploadDir,
+		assignees:   assignees,
+		maxOpenJobs: maxOpenJobs,
+		search:      NewMySQLSearchIndex(db),
+		dispatch:    mustDispatchPattern(DefaultDispatchPattern, DefaultDispatchCounter),
+
+		exportDir:       "./exports",
+		exportAsyncRows: 5000,
+	}
 }
 
-// ListFilter controls filtering when listing reports.
-type ListFilter struct {
-	Status  string
-	AdminID *uint64
+func mustDispatchPattern(pattern, counter string) *DispatchPattern {
+	p, err := ParseDispatchPattern(pattern, counter)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// UseDeviceResolver links new reports to the device registry.
+func (s *Service) UseDeviceResolver(r DeviceResolver) {
+	s.devices = r
+}
+
+// UseCustomerResolver lets new reports pick customers and contacts from the registry.
+func (s *Service) UseCustomerResolver(r CustomerResolver) {
+	s.customers = r
+}
+
+// UseBusinessClock makes report metrics count working time alongside elapsed time.
+func (s *Service) UseBusinessClock(c BusinessClock) {
+	s.clock = c
+}
+
+// OnFinalize registers a hook that runs whenever a report reaches done.
+func (s *Service) OnFinalize(hook FinalizeHook) {
+	s.finalizers = append(s.finalizers, hook)
 }
 
 func (s *Service) Create(ctx context.Context, adminID uint64, req CreateReportRequest) (*ServiceReport, error) {
+	return s.CreateLinked(ctx, adminID, req, nil)
+}
+
+// CreateLinked creates a report like Create and runs link in the same transaction once the
+// report row exists, so a record pointing at the report is stored or rolled back with it.
+func (s *Service) CreateLinked(ctx context.Context, adminID uint64, req CreateReportRequest, link func(tx *gorm.DB, report *ServiceReport) error) (*ServiceReport, error) {
+	version, err := resolveSchemaVersion(req.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := s.resolveCustomer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if customer != nil {
+		if req.FormPayload, err = applyCustomerSnapshot(req.FormPayload, customer); err != nil {
+			return nil, err
+		}
+	}
+	if err := validatePayload(payloadForm, version, req.FormPayload); err != nil {
+		return nil, err
+	}
+	if req.Priority == "" {
+		req.Priority = PriorityNormal
+	}
 	report := &ServiceReport{
-		DispatchNo:      generateDispatchNo(),
 		AdminID:         adminID,
 		CustomerName:    req.Customer.Name,
 		CustomerAddress: req.Customer.Address,
@@ -233,10 +403,56 @@ func (s *Service) Create(ctx context.Context, adminID uint64, req CreateReportRe
 		SerialNumber:    req.Device.Serial,
 		DeviceLocation:  req.Device.Location,
 		Complaint:       req.Complaint,
-		Status:          "open",
+		Priority:        req.Priority,
+		Status:          StatusOpen,
+		SchemaVersion:   version,
 		FormPayload:     datatypes.JSON(req.FormPayload),
 	}
-	if err := s.db.WithContext(ctx).Create(report).Error; err != nil {
+	if req.PartnerID != nil {
+		report.PartnerLocationID = req.PartnerID
+		if req.Device.PartnerLocationID == nil {
+			req.Device.PartnerLocationID = req.PartnerID
+		}
+	}
+	if customer != nil {
+		report.CustomerID = &customer.CustomerID
+		report.ContactID = customer.ContactID
+		report.CustomerName = customer.Name
+		report.CustomerAddress = customer.Address
+		report.CustomerContact = customerContactLine(customer)
+		if customer.PartnerLocationID != 0 {
+			if report.PartnerLocationID == nil {
+				report.PartnerLocationID = &customer.PartnerLocationID
+			}
+			if req.Device.PartnerLocationID == nil {
+				req.Device.PartnerLocationID = &customer.PartnerLocationID
+			}
+		}
+	}
+	branch, err := s.dispatchBranch(ctx, report.PartnerLocationID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.resolveDevice(tx, report, req.Device); err != nil {
+			return err
+		}
+		number, err := s.nextDispatchNo(tx, now, branch)
+		if err != nil {
+			return err
+		}
+		report.DispatchNo = number
+		report.OpenedAt = now
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		if link != nil {
+			return link(tx, report)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -244,22 +460,37 @@ func (s *Service) Create(ctx context.Context, adminID uint64, req CreateReportRe
 		report.FormPayload = datatypes.JSON(processed)
 		_ = s.db.WithContext(ctx).Model(report).Update("form_payload", report.FormPayload).Error
 	}
+	s.indexReport(ctx, report)
 	return report, nil
 }
 
-func (s *Service) List(ctx context.Context, filter ListFilter) ([]ServiceReport, error) {
-	query := s.db.WithContext(ctx).Model(&ServiceReport{})
-	if filter.Status != "" {
-		query = query.Where("status = ?", filter.Status)
+// resolveDevice links report to the device registry and snapshots the device details.
+func (s *Service) resolveDevice(tx *gorm.DB, report *ServiceReport, info DeviceInfo) error {
+	if s.devices == nil {
+		return nil
 	}
-	if filter.AdminID != nil {
-		query = query.Where("admin_id = ?", *filter.AdminID)
+	device, err := s.devices.ResolveDevice(tx, DeviceRef{
+		ID:                info.ID,
+		Manufacturer:      info.Manufacturer,
+		Model:             info.Model,
+		Name:              info.Name,
+		Serial:            info.Serial,
+		Location:          info.Location,
+		PartnerLocationID: info.PartnerLocationID,
+	})
+	if err != nil {
+		return err
 	}
-	var reports []ServiceReport
-	if err := query.Order("opened_at DESC").Limit(200).Find(&reports).Error; err != nil {
-		return nil, err
+	report.DeviceID = device.ID
+	if report.PartnerLocationID == nil {
+		report.PartnerLocationID = device.PartnerLocationID
 	}
-	return reports, nil
+	report.DeviceName = device.Name
+	report.SerialNumber = device.Serial
+	if report.DeviceLocation == "" {
+		report.DeviceLocation = device.Location
+	}
+	return nil
 }
 
 func (s *Service) GetByID(ctx context.Context, id uint64) (*ServiceReport, error) {
@@ -267,6 +498,10 @@ func (s *Service) GetByID(ctx context.Context, id uint64) (*ServiceReport, error
 	if err := s.db.WithContext(ctx).
 		Preload("Attachments").
 		Preload("Photos").
+		Preload("StatusLogs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Crew", activeCrew).
 		First(&report, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrReportNotFound
@@ -297,10 +532,13 @@ func randomSuffix() string {
 }
 
 func (s *Service) SaveAttachment(ctx context.Context, reportID, teknisiID uint64, originalName, contentType string, size int64, r io.Reader) (*ReportAttachment, error) {
-	_, err := s.GetForTechnician(ctx, reportID, teknisiID)
+	report, err := s.GetForTechnician(ctx, reportID, teknisiID)
 	if err != nil {
 		return nil, err
 	}
+	if IsLocked(report.Status) {
+		return nil, ErrReportLocked
+	}
 
 	safeName := sanitizeFilename(originalName)
 	ext := filepath.Ext(safeName)
@@ -332,7 +570,18 @@ func (s *Service) SaveAttachment(ctx context.Context, reportID, teknisiID uint64
 		ContentType: contentType,
 		Size:        size,
 	}
-	if err := s.db.WithContext(ctx).Create(att).Error; err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(att).Error; err != nil {
+			return err
+		}
+		return s.recordEvent(tx, ReportEvent{
+			ReportID: reportID,
+			ActorID:  teknisiID,
+			Type:     EventAttachmentUploaded,
+			Note:     safeName,
+		}, map[string]interface{}{"attachment_id": att.ID, "file_name": safeName, "size": size})
+	})
+	if err != nil {
 		_ = os.Remove(storedPath)
 		return nil, err
 	}
@@ -355,8 +604,8 @@ func (s *Service) DeleteAttachment(ctx context.Context, reportID, teknisiID, att
 	if err != nil {
 		return err
 	}
-	if report.Status == "done" {
-		return ErrReportForbidden
+	if IsLocked(report.Status) {
+		return ErrReportLocked
 	}
 
 	att, err := s.GetAttachment(ctx, reportID, attachmentID)
@@ -368,6 +617,14 @@ func (s *Service) DeleteAttachment(ctx context.Context, reportID, teknisiID, att
 		if err := tx.Delete(&ReportAttachment{}, att.ID).Error; err != nil {
 			return err
 		}
+		if err := s.recordEvent(tx, ReportEvent{
+			ReportID: reportID,
+			ActorID:  teknisiID,
+			Type:     EventAttachmentDeleted,
+			Note:     att.FileName,
+		}, map[string]interface{}{"attachment_id": att.ID, "file_name": att.FileName}); err != nil {
+			return err
+		}
 		if att.FilePath != "" {
 			_ = os.Remove(att.FilePath)
 		}
@@ -375,68 +632,175 @@ func (s *Service) DeleteAttachment(ctx context.Context, reportID, teknisiID, att
 	})
 }
 
-func (s *Service) ListAssigned(ctx context.Context, teknisiID uint64) ([]ServiceReport, error) {
-	var reports []ServiceReport
-	if err := s.db.WithContext(ctx).Where("teknisi_id = ?", teknisiID).Order("opened_at DESC").Find(&reports).Error; err != nil {
+func (s *Service) Assign(ctx context.Context, reportID, adminID uint64, req AssignRequest) (*ServiceReport, error) {
+	teknisiID := req.TeknisiID
+	reason := strings.TrimSpace(req.Reason)
+	var report ServiceReport
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&report, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+		if IsLocked(report.Status) {
+			return &TransitionError{From: report.Status, To: StatusProgress}
+		}
+		if err := s.validateCrew(ctx, tx, &report, adminID, req); err != nil {
+			return err
+		}
+		previous := report.TeknisiID
+		changed, err := s.setLead(tx, &report, teknisiID, adminID, reason)
+		if err != nil {
+			return err
+		}
+		if previous == nil || *previous != teknisiID {
+			changed = true
+			if err := tx.Model(&report).Update("teknisi_id", teknisiID).Error; err != nil {
+				return err
+			}
+			report.TeknisiID = &teknisiID
+		}
+		if changed {
+			if err := s.recordEvent(tx, ReportEvent{
+				ReportID:  report.ID,
+				ActorID:   adminID,
+				Type:      EventAssigned,
+				TeknisiID: &teknisiID,
+				Note:      reason,
+			}, map[string]interface{}{"previous_teknisi_id": previous}); err != nil {
+				return err
+			}
+		}
+		if req.HelperIDs != nil {
+			added, removed, err := s.setHelpers(tx, &report, req.HelperIDs, adminID, reason)
+			if err != nil {
+				return err
+			}
+			if len(added) > 0 || len(removed) > 0 {
+				if err := s.recordEvent(tx, ReportEvent{
+					ReportID: report.ID,
+					ActorID:  adminID,
+					Type:     EventCrewUpdated,
+					Note:     reason,
+				}, map[string]interface{}{"added": added, "removed": removed}); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Where("report_id = ? AND unassigned_at IS NULL", report.ID).Order("role ASC, assigned_at ASC").Find(&report.Crew).Error; err != nil {
+			return err
+		}
+		if report.Status == StatusOpen || report.Status == StatusReopened {
+			return s.transition(tx, &report, StatusProgress, adminID, "Assigned technician", nil)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return reports, nil
+	return &report, nil
 }
 
-func (s *Service) Assign(ctx context.Context, reportID, teknisiID, adminID uint64) (*ServiceReport, error) {
+func (s *Service) UpdateProgress(ctx context.Context, reportID, teknisiID uint64, req ProgressRequest) (*ServiceReport, error) {
 	var report ServiceReport
-	if err := s.db.WithContext(ctx).First(&report, reportID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrReportNotFound
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(s.assignedTo(teknisiID)).Where("id = ?", reportID).First(&report).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
 		}
+		note := fmt.Sprintf("Summary: %s", req.JobSummary)
+		return s.transition(tx, &report, req.Status, teknisiID, note, map[string]interface{}{
+			"action_taken": req.ActionTaken,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
-	fromStatus := report.Status
-	report.TeknisiID = &teknisiID
-	updates := map[string]interface{}{
-		"teknisi_id": teknisiID,
-	}
-	if report.Status == "open" {
-		report.Status = "progress"
-		updates["status"] = report.Status
-	}
-	if err := s.db.WithContext(ctx).Model(&report).Updates(updates).Error; err != nil {
-		return nil, err
-	}
-	_ = s.createStatusLog(ctx, report.ID, adminID, fromStatus, report.Status, "Assigned technician")
+	report.ActionTaken = req.ActionTaken
+	s.indexReport(ctx, &report)
 	return &report, nil
 }
 
-func (s *Service) UpdateProgress(ctx context.Context, reportID, teknisiID uint64, req ProgressRequest) (*ServiceReport, error) {
+// UpdateStatus lets an admin put a report on hold, cancel, reopen or resume it. Moves that
+// have their own endpoint, such as assignment and review, are rejected here.
+func (s *Service) UpdateStatus(ctx context.Context, reportID, adminID uint64, req StatusRequest) (*ServiceReport, error) {
 	var report ServiceReport
-	if err := s.db.WithContext(ctx).Where("id = ? AND teknisi_id = ?", reportID, teknisiID).First(&report).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrReportNotFound
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&report, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
 		}
+		if report.Status == StatusReview {
+			return ErrReviewPending
+		}
+		if !slices.Contains(AdminTransitions(report.Status, report.TeknisiID != nil), req.Status) {
+			return &TransitionError{From: report.Status, To: req.Status}
+		}
+		note := strings.TrimSpace(req.Note)
+		if note == "" {
+			note = fmt.Sprintf("Status changed to %s", req.Status)
+		}
+		return s.transition(tx, &report, req.Status, adminID, note, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
-	fromStatus := report.Status
-	report.ActionTaken = req.ActionTaken
-	report.Status = req.Status
-	if req.Status == "done" {
+	return &report, nil
+}
+
+// transition validates and applies a status change inside tx and records it in StatusLog.
+// The update is guarded by the current status so concurrent changes cannot skip the state machine.
+func (s *Service) transition(tx *gorm.DB, report *ServiceReport, to string, changedBy uint64, note string, extra map[string]interface{}) error {
+	from := report.Status
+	if err := checkTransition(from, to); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"status": to}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	switch to {
+	case StatusDone:
 		now := time.Now()
 		report.CompletedAt = &now
-	} else {
+		updates["completed_at"] = report.CompletedAt
+	case StatusReopened:
 		report.CompletedAt = nil
+		report.ApprovedBy = nil
+		report.ApprovedAt = nil
+		updates["completed_at"] = nil
+		updates["approved_by"] = nil
+		updates["approved_at"] = nil
 	}
-	if err := s.db.WithContext(ctx).Model(&report).Updates(map[string]interface{}{
-		"action_taken": report.ActionTaken,
-		"status":       report.Status,
-		"completed_at": report.CompletedAt,
-	}).Error; err != nil {
-		return nil, err
+
+	result := tx.Model(&ServiceReport{}).Where("id = ? AND status = ?", report.ID, from).Updates(updates)
+	if result.Error != nil {
+		return result.Error
 	}
-	note := fmt.Sprintf("Summary: %s", req.JobSummary)
-	_ = s.createStatusLog(ctx, report.ID, teknisiID, fromStatus, report.Status, note)
-	return &report, nil
+	if result.RowsAffected == 0 {
+		return &TransitionError{From: from, To: to}
+	}
+	report.Status = to
+	if err := s.createStatusLog(tx, report.ID, changedBy, from, to, note); err != nil {
+		return err
+	}
+	if to == StatusDone {
+		for _, hook := range s.finalizers {
+			if err := hook.ReportFinalized(tx, report, changedBy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (s *Service) createStatusLog(ctx context.Context, reportID, changedBy uint64, fromStatus, toStatus, note string) error {
+func (s *Service) createStatusLog(tx *gorm.DB, reportID, changedBy uint64, fromStatus, toStatus, note string) error {
 	log := StatusLog{
 		ReportID:  reportID,
 		ChangedBy: changedBy,
@@ -444,13 +808,5 @@ func (s *Service) createStatusLog(ctx context.Context, reportID, changedBy uint6
 		To:        toStatus,
 		Note:      note,
 	}
-	return s.db.WithContext(ctx).Create(&log).Error
-}
-
-func generateDispatchNo() string {
-	suffix := "000"
-	if n, err := rand.Int(rand.Reader, big.NewInt(900)); err == nil {
-		suffix = fmt.Sprintf("%03d", n.Int64()+100)
-	}
-	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), suffix)
+	return tx.Create(&log).Error
 }
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Report lifecycle states stored in ServiceReport.Status.
+const (
+	StatusOpen         = "open"
+	StatusProgress     = "progress"
+	StatusOnHold       = "on_hold"
+	StatusWaitingParts = "waiting_parts"
+	StatusReview       = "review"
+	StatusDone         = "done"
+	StatusCancelled    = "cancelled"
+	StatusReopened     = "reopened"
+)
+
+// Report priorities stored in ServiceReport.Priority. SLA policies are set per priority.
+const (
+	PriorityLow      = "low"
+	PriorityNormal   = "normal"
+	PriorityHigh     = "high"
+	PriorityCritical = "critical"
+)
+
+// ErrInvalidTransition is returned when a status change is not allowed by the lifecycle.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// TransitionError describes a rejected status change.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move report from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// transitions lists the allowed next states for every lifecycle state.
+var transitions = map[string][]string{
+	StatusOpen:         {StatusProgress, StatusOnHold, StatusCancelled},
+	StatusProgress:     {StatusOnHold, StatusWaitingParts, StatusReview, StatusCancelled},
+	StatusOnHold:       {StatusProgress, StatusWaitingParts, StatusCancelled},
+	StatusWaitingParts: {StatusProgress, StatusOnHold, StatusCancelled},
+	StatusReview:       {StatusDone, StatusProgress},
+	StatusDone:         {StatusReopened},
+	StatusReopened:     {StatusProgress, StatusOnHold, StatusCancelled},
+	StatusCancelled:    {},
+}
+
+// IsValidStatus reports whether status is a known lifecycle state.
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition reports whether a report may move from one state to another.
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions returns the states reachable from the given state.
+func AllowedTransitions(from string) []string {
+	next := transitions[from]
+	out := make([]string, len(next))
+	copy(out, next)
+	return out
+}
+
+// AdminTransitions returns the states an admin may set directly through the status endpoint.
+// Work starts through assignment and a review ends through approve or reject, so those
+// moves are left out; resuming to progress also needs an assigned technician.
+func AdminTransitions(from string, assigned bool) []string {
+	if from == StatusReview {
+		return nil
+	}
+	var out []string
+	for _, to := range AllowedTransitions(from) {
+		if to == StatusReview || to == StatusDone {
+			continue
+		}
+		if to == StatusProgress && (from == StatusOpen || from == StatusReopened || !assigned) {
+			continue
+		}
+		out = append(out, to)
+	}
+	return out
+}
+
+// IsLocked reports whether technician edits are blocked in the given state.
+// Reports under review or approved can no longer change their payload or attachments.
+func IsLocked(status string) bool {
+	return status == StatusReview || status == StatusDone || status == StatusCancelled
+}
+
+// IsPrintable reports whether a report has been approved and may be printed.
+func IsPrintable(status string) bool {
+	return status == StatusDone
+}
+
+func checkTransition(from, to string) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusOpen, StatusProgress, true},
+		{StatusOpen, StatusOnHold, true},
+		{StatusOpen, StatusCancelled, true},
+		{StatusOpen, StatusReview, false},
+		{StatusOpen, StatusDone, false},
+		{StatusProgress, StatusWaitingParts, true},
+		{StatusProgress, StatusReview, true},
+		{StatusProgress, StatusDone, false},
+		{StatusOnHold, StatusProgress, true},
+		{StatusOnHold, StatusReview, false},
+		{StatusWaitingParts, StatusProgress, true},
+		{StatusWaitingParts, StatusReview, false},
+		{StatusReview, StatusDone, true},
+		{StatusReview, StatusProgress, true},
+		{StatusReview, StatusCancelled, false},
+		{StatusDone, StatusReopened, true},
+		{StatusDone, StatusProgress, false},
+		{StatusReopened, StatusProgress, true},
+		{StatusReopened, StatusDone, false},
+		{StatusCancelled, StatusOpen, false},
+		{StatusCancelled, StatusReopened, false},
+		{"unknown", StatusProgress, false},
+		{StatusOpen, "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionTableIsClosed(t *testing.T) {
+	for from, next := range transitions {
+		for _, to := range next {
+			if !IsValidStatus(to) {
+				t.Errorf("%s lists unknown target state %q", from, to)
+			}
+			if to == from {
+				t.Errorf("%s lists itself as a target", from)
+			}
+		}
+	}
+}
+
+func TestAllowedTransitionsReturnsCopy(t *testing.T) {
+	got := AllowedTransitions(StatusOpen)
+	got[0] = StatusDone
+	if CanTransition(StatusOpen, StatusDone) {
+		t.Fatal("changing the returned slice changed the transition table")
+	}
+	if AllowedTransitions(StatusCancelled) == nil || len(AllowedTransitions(StatusCancelled)) != 0 {
+		t.Errorf("AllowedTransitions(cancelled) = %v, want empty", AllowedTransitions(StatusCancelled))
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	if err := checkTransition(StatusProgress, StatusReview); err != nil {
+		t.Fatalf("checkTransition(progress, review) = %v", err)
+	}
+	err := checkTransition(StatusDone, StatusProgress)
+	var te *TransitionError
+	if !errors.As(err, &te) || te.From != StatusDone || te.To != StatusProgress {
+		t.Fatalf("checkTransition(done, progress) = %v, want TransitionError", err)
+	}
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("TransitionError does not unwrap to ErrInvalidTransition")
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{StatusOpen, false},
+		{StatusProgress, false},
+		{StatusOnHold, false},
+		{StatusWaitingParts, false},
+		{StatusReopened, false},
+		{StatusReview, true},
+		{StatusDone, true},
+		{StatusCancelled, true},
+	}
+	for _, tt := range tests {
+		if got := IsLocked(tt.status); got != tt.want {
+			t.Errorf("IsLocked(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestAdminTransitions(t *testing.T) {
+	tests := []struct {
+		from     string
+		assigned bool
+		want     []string
+	}{
+		{StatusOpen, false, []string{StatusOnHold, StatusCancelled}},
+		{StatusOpen, true, []string{StatusOnHold, StatusCancelled}},
+		{StatusProgress, true, []string{StatusOnHold, StatusWaitingParts, StatusCancelled}},
+		{StatusOnHold, true, []string{StatusProgress, StatusWaitingParts, StatusCancelled}},
+		{StatusOnHold, false, []string{StatusWaitingParts, StatusCancelled}},
+		{StatusWaitingParts, true, []string{StatusProgress, StatusOnHold, StatusCancelled}},
+		{StatusReview, true, nil},
+		{StatusDone, true, []string{StatusReopened}},
+		{StatusReopened, true, []string{StatusOnHold, StatusCancelled}},
+		{StatusCancelled, true, nil},
+	}
+	for _, tt := range tests {
+		got := AdminTransitions(tt.from, tt.assigned)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("AdminTransitions(%q, %v) = %v, want %v", tt.from, tt.assigned, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/user"
+)
+
+// Timeline entry types in addition to the ReportEvent types.
+const (
+	TimelineCreated       = "created"
+	TimelineStatusChanged = "status_changed"
+)
+
+// Timeline merges report creation, status logs and report events into one chronological feed.
+func (s *Service) Timeline(ctx context.Context, reportID uint64) ([]TimelineEntry, error) {
+	var report ServiceReport
+	if err := s.db.WithContext(ctx).First(&report, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+
+	var logs []StatusLog
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("created_at ASC, id ASC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	var events []ReportEvent
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]TimelineEntry, 0, len(logs)+len(events)+1)
+	entries = append(entries, TimelineEntry{
+		Type:     TimelineCreated,
+		At:       report.OpenedAt,
+		ActorID:  report.AdminID,
+		ToStatus: StatusOpen,
+		Note:     "Report created with dispatch " + report.DispatchNo,
+	})
+	for _, l := range logs {
+		entries = append(entries, TimelineEntry{
+			Type:       TimelineStatusChanged,
+			At:         l.CreatedAt,
+			ActorID:    l.ChangedBy,
+			FromStatus: l.From,
+			ToStatus:   l.To,
+			Note:       l.Note,
+		})
+	}
+	for _, e := range events {
+		entries = append(entries, TimelineEntry{
+			Type:      e.Type,
+			At:        e.CreatedAt,
+			ActorID:   e.ActorID,
+			TeknisiID: e.TeknisiID,
+			Note:      e.Note,
+			Data:      e.Data,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.Before(entries[j].At)
+	})
+
+	if err := s.resolveTimelineNames(ctx, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Service) resolveTimelineNames(ctx context.Context, entries []TimelineEntry) error {
+	ids := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ActorID)
+		if e.TeknisiID != nil {
+			ids = append(ids, *e.TeknisiID)
+		}
+	}
+	names, err := s.userNames(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].ActorName = names[entries[i].ActorID]
+		if entries[i].TeknisiID != nil {
+			entries[i].TeknisiName = names[*entries[i].TeknisiID]
+		}
+	}
+	return nil
+}
+
+// UserName returns the full name of a user, or "" when there is no such user.
+func (s *Service) UserName(ctx context.Context, id uint64) (string, error) {
+	names, err := s.userNames(ctx, []uint64{id})
+	if err != nil {
+		return "", err
+	}
+	return names[id], nil
+}
+
+// userNames maps user IDs to full names, ignoring zero and duplicate IDs.
+func (s *Service) userNames(ctx context.Context, ids []uint64) (map[uint64]string, error) {
+	seen := map[uint64]struct{}{}
+	lookup := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		lookup = append(lookup, id)
+	}
+	names := make(map[uint64]string, len(lookup))
+	if len(lookup) == 0 {
+		return names, nil
+	}
+
+	var users []user.User
+	if err := s.db.WithContext(ctx).Select("id", "full_name").Where("id IN ?", lookup).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		names[u.ID] = u.FullName
+	}
+	return names, nil
+}
+
+// recordEvent stores a timeline event using tx so it commits with the change it describes.
+func (s *Service) recordEvent(tx *gorm.DB, event ReportEvent, data any) error {
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		event.Data = datatypes.JSON(raw)
+	}
+	return tx.Create(&event).Error
+}
@@ -0,0 +1,482 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// timesheetPayload is the subset of the technician payload holding travel and work times.
+// Dates are YYYY-MM-DD, times HH:MM; waiting and work times without a date fall on the
+// arrival day, or on the day after when the job runs past midnight.
+type timesheetPayload struct {
+	TravelStart      payloadText `json:"travelStart"`
+	TravelStartTime  payloadText `json:"travelStartTime"`
+	TravelFinish     payloadText `json:"travelFinish"`
+	TravelFinishTime payloadText `json:"travelFinishTime"`
+	WaitingStart     payloadText `json:"waitingStart"`
+	WaitingFinish    payloadText `json:"waitingFinish"`
+	ReturnStart      payloadText `json:"returnStart"`
+	ReturnStartTime  payloadText `json:"returnStartTime"`
+	ReturnFinish     payloadText `json:"returnFinish"`
+	ReturnFinishTime payloadText `json:"returnFinishTime"`
+	DispatchDate     payloadText `json:"dispatchDate"`
+	DeviceRows       []struct {
+		WorkStart  payloadText `json:"workStart"`
+		WorkFinish payloadText `json:"workFinish"`
+	} `json:"deviceRows"`
+}
+
+// moment is a parsed timesheet value. A moment without a time of day only orders by date
+// and contributes no hours. A bare moment is a clock time placed on an assumed day.
+type moment struct {
+	at      time.Time
+	hasTime bool
+	bare    bool
+	field   string
+}
+
+func (m *moment) day() time.Time {
+	y, mo, d := m.at.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, m.at.Location())
+}
+
+// after reports whether m lies later than b, at day precision when either has no time.
+func (m *moment) after(b *moment) bool {
+	if !m.hasTime || !b.hasTime {
+		return m.day().After(b.day())
+	}
+	return m.at.After(b.at)
+}
+
+var (
+	sheetDateLayouts     = []string{"2006-01-02"}
+	sheetDateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+	sheetClockLayouts    = []string{"15:04", "15:04:05", "15.04"}
+)
+
+// parseSheetDate reads a date, or a full timestamp, at field. It returns nil for blanks.
+func parseSheetDate(raw payloadText, field string) (*moment, bool) {
+	value := strings.TrimSpace(string(raw))
+	if value == "" {
+		return nil, true
+	}
+	for _, layout := range sheetDateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &moment{at: t, hasTime: true, field: field}, true
+		}
+	}
+	for _, layout := range sheetDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &moment{at: t, field: field}, true
+		}
+	}
+	return nil, false
+}
+
+// withClock sets the time of day of a date-only moment from a HH:MM value.
+func withClock(m *moment, raw payloadText, field string) (*moment, bool) {
+	value := strings.TrimSpace(string(raw))
+	if value == "" {
+		return m, true
+	}
+	for _, layout := range sheetClockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if m == nil || m.hasTime {
+				return m, true
+			}
+			day := m.day()
+			return &moment{at: day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), hasTime: true, field: field}, true
+		}
+	}
+	return m, false
+}
+
+// parseSheetClock reads a HH:MM value on day, or a full timestamp.
+func parseSheetClock(raw payloadText, day *time.Time, field string) (*moment, bool) {
+	value := strings.TrimSpace(string(raw))
+	if value == "" {
+		return nil, true
+	}
+	if m, ok := parseSheetDate(raw, field); ok && m != nil && m.hasTime {
+		return m, true
+	}
+	if day == nil {
+		return nil, true
+	}
+	m, ok := withClock(&moment{at: *day, field: field}, raw, field)
+	if ok && m != nil && m.hasTime {
+		m.bare = true
+	}
+	return m, ok
+}
+
+// maxRollover bounds how far past the previous time a bare clock time may be moved over
+// midnight. Longer gaps are more likely typing mistakes than night work, so they stay
+// ordering errors.
+const maxRollover = 12 * time.Hour
+
+// rollPast moves a bare clock time that reads earlier than prev to the next day, so that a
+// call-out running from 22:00 to 02:00 is read as four hours rather than out of order.
+func rollPast(m, prev *moment) *moment {
+	if m == nil || !m.bare || prev == nil || !prev.hasTime || !prev.at.After(m.at) {
+		return m
+	}
+	next := m.at.AddDate(0, 0, 1)
+	if next.Sub(prev.at) > maxRollover {
+		return m
+	}
+	return &moment{at: next, hasTime: true, bare: true, field: m.field}
+}
+
+// latest returns the first non-nil moment, walking from the most recent.
+func latest(ms ...*moment) *moment {
+	for _, m := range ms {
+		if m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+type sheetSpan struct {
+	start, finish *moment
+}
+
+func (s sheetSpan) minutes() int64 {
+	if s.start == nil || s.finish == nil || !s.start.hasTime || !s.finish.hasTime || !s.finish.at.After(s.start.at) {
+		return 0
+	}
+	return int64(s.finish.at.Sub(s.start.at) / time.Minute)
+}
+
+// buildTimesheet extracts the timesheet of a technician payload. Field errors cover values
+// that cannot be read and segments out of order: travel, then waiting, then work, then
+// return. The sheet is returned even with errors so that backfills can keep what is valid.
+func buildTimesheet(reportID uint64, payload []byte, fallbackDay time.Time) (*ReportTimesheet, []FieldError, error) {
+	var p timesheetPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, nil, err
+		}
+	}
+	var errs []FieldError
+	unreadable := func(field string) {
+		errs = append(errs, FieldError{Field: "/" + field, Message: "is not a valid date or time"})
+	}
+	date := func(raw payloadText, field string) *moment {
+		m, ok := parseSheetDate(raw, field)
+		if !ok {
+			unreadable(field)
+		}
+		return m
+	}
+	clock := func(m *moment, raw payloadText, field string) *moment {
+		out, ok := withClock(m, raw, field)
+		if !ok {
+			unreadable(field)
+		}
+		return out
+	}
+
+	travel := sheetSpan{
+		start:  clock(date(p.TravelStart, "travelStart"), p.TravelStartTime, "travelStartTime"),
+		finish: clock(date(p.TravelFinish, "travelFinish"), p.TravelFinishTime, "travelFinishTime"),
+	}
+	returning := sheetSpan{
+		start:  clock(date(p.ReturnStart, "returnStart"), p.ReturnStartTime, "returnStartTime"),
+		finish: clock(date(p.ReturnFinish, "returnFinish"), p.ReturnFinishTime, "returnFinishTime"),
+	}
+
+	// Waiting and work times are usually bare clock times on the arrival day.
+	var siteDay *time.Time
+	for _, m := range []*moment{travel.finish, travel.start} {
+		if m != nil {
+			d := m.day()
+			siteDay = &d
+			break
+		}
+	}
+	if siteDay == nil {
+		if m, ok := parseSheetDate(p.DispatchDate, "dispatchDate"); ok && m != nil {
+			d := m.day()
+			siteDay = &d
+		} else {
+			y, mo, d := fallbackDay.In(time.Local).Date()
+			day := time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
+			siteDay = &day
+		}
+	}
+	onSite := func(raw payloadText, field string) *moment {
+		m, ok := parseSheetClock(raw, siteDay, field)
+		if !ok {
+			unreadable(field)
+		}
+		return m
+	}
+	// Device work times are free text on older forms; values that are not times are skipped.
+	workTime := func(raw payloadText, field string) *moment {
+		m, _ := parseSheetClock(raw, siteDay, field)
+		return m
+	}
+	// Each on-site time is read relative to the one before it, so times past midnight roll
+	// over to the next day.
+	var arrived *moment
+	if travel.finish != nil && travel.finish.hasTime {
+		arrived = travel.finish
+	}
+	var waiting sheetSpan
+	waiting.start = rollPast(onSite(p.WaitingStart, "waitingStart"), arrived)
+	waiting.finish = rollPast(onSite(p.WaitingFinish, "waitingFinish"), latest(waiting.start, arrived))
+	ready := latest(waiting.finish, waiting.start, arrived)
+	var work sheetSpan
+	var workRows []sheetSpan
+	for i, row := range p.DeviceRows {
+		prefix := "deviceRows/" + strconv.Itoa(i) + "/"
+		var span sheetSpan
+		span.start = rollPast(workTime(row.WorkStart, prefix+"workStart"), ready)
+		span.finish = rollPast(workTime(row.WorkFinish, prefix+"workFinish"), latest(span.start, ready))
+		if span.start != nil && span.finish != nil && span.start.after(span.finish) {
+			errs = append(errs, FieldError{Field: "/" + span.finish.field, Message: "must not be before work start"})
+			continue
+		}
+		if span.start != nil && (work.start == nil || work.start.after(span.start)) {
+			work.start = span.start
+		}
+		if span.finish != nil && (work.finish == nil || span.finish.after(work.finish)) {
+			work.finish = span.finish
+		}
+		workRows = append(workRows, span)
+	}
+
+	segments := []struct {
+		name string
+		span sheetSpan
+	}{
+		{"travel", travel},
+		{"waiting", waiting},
+		{"work", work},
+		{"return", returning},
+	}
+	var last *moment
+	lastName := ""
+	for _, seg := range segments {
+		if seg.name != "work" && seg.span.start != nil && seg.span.finish != nil && seg.span.start.after(seg.span.finish) {
+			errs = append(errs, FieldError{Field: "/" + seg.span.finish.field, Message: "must not be before " + seg.name + " start"})
+		}
+		if first := seg.span.start; first != nil && last != nil && last.after(first) {
+			errs = append(errs, FieldError{Field: "/" + first.field, Message: "must not be before the end of " + lastName})
+		}
+		if seg.span.finish != nil {
+			last, lastName = seg.span.finish, seg.name
+		} else if seg.span.start != nil {
+			last, lastName = seg.span.start, seg.name
+		}
+	}
+
+	sheet := &ReportTimesheet{
+		ReportID:       reportID,
+		TravelMinutes:  travel.minutes(),
+		WaitingMinutes: waiting.minutes(),
+		WorkMinutes:    unionMinutes(workRows),
+		ReturnMinutes:  returning.minutes(),
+	}
+	sheet.TravelStart, sheet.TravelFinish = travel.start.ptr(), travel.finish.ptr()
+	sheet.WaitingStart, sheet.WaitingFinish = waiting.start.ptr(), waiting.finish.ptr()
+	sheet.WorkStart, sheet.WorkFinish = work.start.ptr(), work.finish.ptr()
+	sheet.ReturnStart, sheet.ReturnFinish = returning.start.ptr(), returning.finish.ptr()
+	for _, t := range []*time.Time{sheet.TravelStart, sheet.WaitingStart, sheet.WorkStart, sheet.ReturnStart} {
+		if t != nil {
+			sheet.SheetDate = t
+			break
+		}
+	}
+	return sheet, errs, nil
+}
+
+func (m *moment) ptr() *time.Time {
+	if m == nil {
+		return nil
+	}
+	t := m.at
+	return &t
+}
+
+// unionMinutes totals work spans without counting overlapping device rows twice.
+func unionMinutes(spans []sheetSpan) int64 {
+	type interval struct{ start, end time.Time }
+	var list []interval
+	for _, s := range spans {
+		if s.minutes() > 0 {
+			list = append(list, interval{s.start.at, s.finish.at})
+		}
+	}
+	for i := 1; i < len(list); i++ {
+		for j := i; j > 0 && list[j].start.Before(list[j-1].start); j-- {
+			list[j], list[j-1] = list[j-1], list[j]
+		}
+	}
+	var total time.Duration
+	var cur *interval
+	for i := range list {
+		iv := list[i]
+		switch {
+		case cur == nil:
+			cur = &iv
+		case !iv.start.After(cur.end):
+			if iv.end.After(cur.end) {
+				cur.end = iv.end
+			}
+		default:
+			total += cur.end.Sub(cur.start)
+			cur = &iv
+		}
+	}
+	if cur != nil {
+		total += cur.end.Sub(cur.start)
+	}
+	return int64(total / time.Minute)
+}
+
+// syncTimesheet stores the timesheet extracted from a technician payload with the report's
+// lead technician and partner. Totals credit the sheet to the whole crew; see timesheetCrew.
+func syncTimesheet(tx *gorm.DB, report *ServiceReport, sheet *ReportTimesheet) error {
+	sheet.TeknisiID = report.TeknisiID
+	sheet.PartnerLocationID = report.PartnerLocationID
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(sheet).Error
+}
+
+// SyncMissingTimesheets extracts timesheets for reports saved before they were tracked.
+// Values that cannot be read or are out of order are kept as they are.
+func (s *Service) SyncMissingTimesheets(ctx context.Context) error {
+	var reports []ServiceReport
+	return s.db.WithContext(ctx).
+		Select("id", "teknisi_id", "partner_location_id", "opened_at", "teknisi_payload").
+		Where("teknisi_payload IS NOT NULL").
+		Where("id NOT IN (?)", s.db.Model(&ReportTimesheet{}).Select("report_id")).
+		FindInBatches(&reports, 100, func(_ *gorm.DB, _ int) error {
+			for i := range reports {
+				r := &reports[i]
+				sheet, _, err := buildTimesheet(r.ID, r.TeknisiPayload, r.OpenedAt)
+				if err == nil {
+					err = syncTimesheet(s.db.WithContext(ctx), r, sheet)
+				}
+				if err != nil {
+					log.Printf("report timesheet: sync report %d: %v", r.ID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+// Timesheet returns the extracted timesheet of a report.
+func (s *Service) Timesheet(ctx context.Context, reportID uint64) (*ReportTimesheet, error) {
+	var sheet ReportTimesheet
+	err := s.db.WithContext(ctx).First(&sheet, "report_id = ?", reportID).Error
+	if err == nil {
+		return &sheet, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Select("id").First(&ServiceReport{}, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &ReportTimesheet{ReportID: reportID}, nil
+}
+
+// TimesheetTotalsFilter narrows and groups timesheet totals.
+type TimesheetTotalsFilter struct {
+	From      *time.Time
+	To        *time.Time
+	TeknisiID *uint64
+	PartnerID *uint64
+	GroupBy   string
+}
+
+// timesheetCrew lists who worked on each report: its active crew and its lead technician,
+// who has no assignment row on reports assigned before crews were tracked.
+func (s *Service) timesheetCrew() *gorm.DB {
+	return s.db.Raw("SELECT report_id, teknisi_id FROM report_assignments WHERE unassigned_at IS NULL" +
+		" UNION SELECT id, teknisi_id FROM service_reports WHERE teknisi_id IS NOT NULL")
+}
+
+// TimesheetTotals sums travel, waiting, work and return hours per technician, partner or
+// month. The period applies to the day the timesheet starts. Per technician, every crew
+// member of a report is credited its full sheet, since they travelled and worked together.
+func (s *Service) TimesheetTotals(ctx context.Context, filter TimesheetTotalsFilter) ([]TimesheetTotalsRow, error) {
+	query := s.db.WithContext(ctx).Table("report_timesheets")
+	byTeknisi := filter.GroupBy != "partner" && filter.GroupBy != "month"
+	if byTeknisi || filter.TeknisiID != nil {
+		query = query.Joins("JOIN (?) AS crew ON crew.report_id = report_timesheets.report_id", s.timesheetCrew())
+	}
+	if filter.From != nil {
+		query = query.Where("report_timesheets.sheet_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("report_timesheets.sheet_date < ?", *filter.To)
+	}
+	if filter.TeknisiID != nil {
+		query = query.Where("crew.teknisi_id = ?", *filter.TeknisiID)
+	}
+	if filter.PartnerID != nil {
+		query = query.Where("report_timesheets.partner_location_id = ?", *filter.PartnerID)
+	}
+
+	selects := []string{
+		"COUNT(*) AS report_count",
+		"SUM(report_timesheets.travel_minutes) AS travel_minutes",
+		"SUM(report_timesheets.waiting_minutes) AS waiting_minutes",
+		"SUM(report_timesheets.work_minutes) AS work_minutes",
+		"SUM(report_timesheets.return_minutes) AS return_minutes",
+	}
+	var groups []string
+	order := "work_minutes DESC"
+	switch filter.GroupBy {
+	case "partner":
+		query = query.Joins("LEFT JOIN partner_locations ON partner_locations.id = report_timesheets.partner_location_id")
+		selects = append(selects, "report_timesheets.partner_location_id AS partner_location_id", "MAX(partner_locations.hospital_name) AS partner_name")
+		groups = append(groups, "report_timesheets.partner_location_id")
+	case "month":
+		selects = append(selects, "DATE_FORMAT(report_timesheets.sheet_date, '%Y-%m') AS month")
+		groups = append(groups, "month")
+		order = "month ASC"
+	default:
+		query = query.Joins("LEFT JOIN users ON users.id = crew.teknisi_id")
+		selects = append(selects, "crew.teknisi_id AS teknisi_id", "MAX(users.full_name) AS teknisi_name")
+		groups = append(groups, "crew.teknisi_id")
+	}
+
+	var rows []TimesheetTotalsRow
+	if err := query.
+		Select(strings.Join(selects, ", ")).
+		Group(strings.Join(groups, ", ")).
+		Order(order).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		r := &rows[i]
+		r.TravelHours = minutesToHours(r.TravelMinutes)
+		r.WaitingHours = minutesToHours(r.WaitingMinutes)
+		r.WorkHours = minutesToHours(r.WorkMinutes)
+		r.ReturnHours = minutesToHours(r.ReturnMinutes)
+		r.TotalHours = minutesToHours(r.TravelMinutes + r.WaitingMinutes + r.WorkMinutes + r.ReturnMinutes)
+	}
+	return rows, nil
+}
+
+func minutesToHours(minutes int64) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildTimesheet(t *testing.T) {
+	fallback := time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name                       string
+		payload                    string
+		travel, waiting, work, ret int64
+		errFields                  []string
+	}{
+		{
+			name: "day job",
+			payload: `{"travelStart":"2024-05-06","travelStartTime":"07:00","travelFinish":"2024-05-06","travelFinishTime":"08:30",
+				"waitingStart":"08:30","waitingFinish":"09:00",
+				"deviceRows":[{"workStart":"09:00","workFinish":"11:00"},{"workStart":"10:30","workFinish":"12:00"}],
+				"returnStart":"2024-05-06","returnStartTime":"12:15","returnFinish":"2024-05-06","returnFinishTime":"13:45"}`,
+			travel: 90, waiting: 30, work: 180, ret: 90,
+		},
+		{
+			name: "work past midnight",
+			payload: `{"travelFinish":"2024-05-06","travelFinishTime":"21:30",
+				"deviceRows":[{"workStart":"22:00","workFinish":"02:00"}],
+				"returnStart":"2024-05-07","returnStartTime":"02:30"}`,
+			work: 240,
+		},
+		{
+			name: "waiting into the next day",
+			payload: `{"travelFinish":"2024-05-06","travelFinishTime":"22:45",
+				"waitingStart":"23:00","waitingFinish":"00:15",
+				"deviceRows":[{"workStart":"00:30","workFinish":"03:00"}]}`,
+			waiting: 75, work: 150,
+		},
+		{
+			name:      "long gaps are not rolled over",
+			payload:   `{"waitingStart":"10:00","waitingFinish":"10:30","deviceRows":[{"workStart":"01:00","workFinish":"02:00"}]}`,
+			waiting:   30,
+			work:      60,
+			errFields: []string{"/deviceRows/0/workStart"},
+		},
+		{
+			name:      "finish before start",
+			payload:   `{"travelStart":"2024-05-06","travelStartTime":"09:00","travelFinish":"2024-05-06","travelFinishTime":"08:00"}`,
+			errFields: []string{"/travelFinishTime"},
+		},
+		{
+			name:      "unreadable values",
+			payload:   `{"travelStart":"6 Mei","waitingStart":"pagi","deviceRows":[{"workStart":"selesai"}]}`,
+			errFields: []string{"/travelStart", "/waitingStart"},
+		},
+	}
+	for _, tt := range tests {
+		sheet, errs, err := buildTimesheet(1, []byte(tt.payload), fallback)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if sheet.TravelMinutes != tt.travel || sheet.WaitingMinutes != tt.waiting || sheet.WorkMinutes != tt.work || sheet.ReturnMinutes != tt.ret {
+			t.Errorf("%s: minutes = %d/%d/%d/%d, want %d/%d/%d/%d", tt.name,
+				sheet.TravelMinutes, sheet.WaitingMinutes, sheet.WorkMinutes, sheet.ReturnMinutes,
+				tt.travel, tt.waiting, tt.work, tt.ret)
+		}
+		if len(errs) != len(tt.errFields) {
+			t.Errorf("%s: errors = %v, want fields %v", tt.name, errs, tt.errFields)
+			continue
+		}
+		for i, e := range errs {
+			if e.Field != tt.errFields[i] {
+				t.Errorf("%s: error %d field = %s, want %s", tt.name, i, e.Field, tt.errFields[i])
+			}
+		}
+	}
+}
+
+func TestBuildTimesheetSiteDay(t *testing.T) {
+	fallback := time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local)
+	sheet, _, err := buildTimesheet(1, []byte(`{"dispatchDate":"2024-05-03","deviceRows":[{"workStart":"10:00","workFinish":"11:00"}]}`), fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 5, 3, 10, 0, 0, 0, time.Local)
+	if sheet.SheetDate == nil || !sheet.SheetDate.Equal(want) {
+		t.Errorf("sheet date = %v, want %v", sheet.SheetDate, want)
+	}
+	sheet, _, err = buildTimesheet(1, nil, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.SheetDate != nil || sheet.WorkMinutes != 0 {
+		t.Errorf("empty payload gave %+v", sheet)
+	}
+}
+
+func TestUnionMinutes(t *testing.T) {
+	day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local)
+	at := func(h, m int) *moment {
+		return &moment{at: day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), hasTime: true}
+	}
+	tests := []struct {
+		name  string
+		spans []sheetSpan
+		want  int64
+	}{
+		{"none", nil, 0},
+		{"single", []sheetSpan{{at(9, 0), at(10, 30)}}, 90},
+		{"disjoint", []sheetSpan{{at(13, 0), at(14, 0)}, {at(9, 0), at(10, 0)}}, 120},
+		{"overlapping", []sheetSpan{{at(9, 0), at(11, 0)}, {at(10, 0), at(12, 0)}}, 180},
+		{"contained", []sheetSpan{{at(9, 0), at(12, 0)}, {at(10, 0), at(11, 0)}}, 180},
+		{"touching", []sheetSpan{{at(9, 0), at(10, 0)}, {at(10, 0), at(11, 0)}}, 120},
+		{"open and reversed spans", []sheetSpan{{at(9, 0), nil}, {at(11, 0), at(10, 0)}, {at(14, 0), at(15, 0)}}, 60},
+	}
+	for _, tt := range tests {
+		if got := unionMinutes(tt.spans); got != tt.want {
+			t.Errorf("%s: unionMinutes = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
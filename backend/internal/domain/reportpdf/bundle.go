@@ -0,0 +1,284 @@
+package reportpdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+func init() {
+	// pdfcpu otherwise writes a config directory under the user's home on first use
+	// and exits the process when it cannot.
+	api.DisableConfigDir()
+}
+
+// Kinds of documents listed on the bundle cover.
+const (
+	KindReport = "Report"
+	KindPDF    = "PDF"
+	KindImage  = "Image"
+)
+
+// BundleItem is one line of the bundle's cover index. Skipped holds the reason a file
+// was left out; FirstPage and Pages are zero for skipped files.
+type BundleItem struct {
+	Name      string
+	Kind      string
+	FirstPage int
+	Pages     int
+	Skipped   string
+}
+
+type bundlePart struct {
+	doc  []byte
+	item BundleItem
+}
+
+// Bundle renders the report followed by its attachments in upload order, behind a cover
+// page that indexes every file. PDF attachments are appended as they are, images get a
+// page each, and anything else (Word documents, damaged PDFs, missing files) is listed
+// on the cover as skipped.
+func (s *Service) Bundle(r *report.ServiceReport) ([]byte, error) {
+	doc, err := s.Render(r)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := pdfPages(doc)
+	if err != nil {
+		return nil, fmt.Errorf("rendered report: %w", err)
+	}
+	parts := []bundlePart{{doc: doc, item: BundleItem{Name: "Service Report " + r.DispatchNo, Kind: KindReport, Pages: pages}}}
+
+	snap := NewSnapshot(r)
+	date := documentDate(r)
+	for _, att := range uploadOrder(r.Attachments) {
+		parts = append(parts, attachmentPart(att, snap, date))
+	}
+	cover, err := layoutCover(snap, parts, date)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []*bytes.Reader{bytes.NewReader(cover)}
+	for _, p := range parts {
+		if p.item.Skipped == "" {
+			docs = append(docs, bytes.NewReader(p.doc))
+		}
+	}
+	return mergePDFs(docs)
+}
+
+// uploadOrder returns a copy of attachments sorted by upload time, then by ID.
+func uploadOrder(attachments []report.ReportAttachment) []report.ReportAttachment {
+	sorted := append([]report.ReportAttachment(nil), attachments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// layoutCover numbers the pages of the included parts and renders the cover indexing them.
+// The cover's own length shifts every page number it prints, so it is rendered until the
+// page count it assumed is the one it produced.
+func layoutCover(snap Snapshot, parts []bundlePart, date time.Time) ([]byte, error) {
+	var cover []byte
+	coverPages := 1
+	for attempt := 0; attempt < 3; attempt++ {
+		next := coverPages + 1
+		for i := range parts {
+			if parts[i].item.Skipped != "" {
+				continue
+			}
+			parts[i].item.FirstPage = next
+			next += parts[i].item.Pages
+		}
+		items := make([]BundleItem, len(parts))
+		for i, p := range parts {
+			items[i] = p.item
+		}
+		var n int
+		var err error
+		cover, n, err = renderCover(snap, items, date)
+		if err != nil {
+			return nil, err
+		}
+		if n == coverPages {
+			break
+		}
+		coverPages = n
+	}
+	return cover, nil
+}
+
+// attachmentPart reads one attachment and decides how it enters the bundle.
+func attachmentPart(att report.ReportAttachment, snap Snapshot, date time.Time) bundlePart {
+	name := att.FileName
+	if name == "" {
+		name = filepath.Base(att.FilePath)
+	}
+	part := bundlePart{item: BundleItem{Name: name, Kind: fileKind(name)}}
+	data, err := os.ReadFile(att.FilePath)
+	if err != nil {
+		part.item.Skipped = "file not found"
+		return part
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if bytes.Contains(head, []byte("%PDF-")) {
+		part.item.Kind = KindPDF
+		pages, err := pdfPages(data)
+		if err != nil {
+			part.item.Skipped = "PDF is damaged or password protected"
+			return part
+		}
+		part.doc, part.item.Pages = data, pages
+		return part
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		part.item.Kind = KindImage
+		doc, err := renderImagePage(snap, name, data, date)
+		if err != nil {
+			part.item.Skipped = "image could not be rendered"
+			return part
+		}
+		part.doc, part.item.Pages = doc, 1
+		return part
+	}
+	part.item.Skipped = "not a PDF or image"
+	return part
+}
+
+// fileKind labels a skipped file by its extension, e.g. DOCX.
+func fileKind(name string) string {
+	if ext := strings.TrimPrefix(filepath.Ext(name), "."); ext != "" {
+		return strings.ToUpper(ext)
+	}
+	return "File"
+}
+
+// pdfPages counts the pages of a PDF after validating it the way the merge will read it.
+// pdfcpu can panic on malformed input; that is reported as an error like any other.
+func pdfPages(doc []byte) (pages int, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("read pdf: %v", v)
+		}
+	}()
+	ctx, err := api.ReadAndValidate(bytes.NewReader(doc), pdfConfig())
+	if err != nil {
+		return 0, err
+	}
+	if ctx.PageCount == 0 {
+		return 0, fmt.Errorf("read pdf: no pages")
+	}
+	return ctx.PageCount, nil
+}
+
+func mergePDFs(docs []*bytes.Reader) (out []byte, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("merge pdf: %v", v)
+		}
+	}()
+	rs := make([]io.ReadSeeker, len(docs))
+	for i, d := range docs {
+		rs[i] = d
+	}
+	var buf bytes.Buffer
+	if err := api.MergeRaw(rs, &buf, false, pdfConfig()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func pdfConfig() *model.Configuration {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	return conf
+}
+
+// renderCover prints the index of the bundle and returns the document with its page count.
+func renderCover(snap Snapshot, items []BundleItem, date time.Time) ([]byte, int, error) {
+	r := newRenderer(snap, Options{Date: date})
+	r.pdf.SetFooterFunc(nil)
+	r.meta = [2]string{"Dispatch No: " + orDash(snap.DispatchNo), "Customer: " + orDash(snap.CustomerName)}
+	r.pdf.AddPage()
+
+	r.section("Report Bundle", 20)
+	device := DeviceRow{}
+	if len(snap.DeviceRows) > 0 {
+		device = snap.DeviceRows[0]
+	}
+	included := 0
+	for _, item := range items {
+		if item.Skipped == "" {
+			included++
+		}
+	}
+	r.grid([]field{
+		{"Dispatch No", snap.DispatchNo},
+		{"Customer Name", snap.CustomerName},
+		{"Product Description", device.Description},
+		{"Serial Number", device.SerialNo},
+		{"Close Date", formatDate(snap.FinalizedDate)},
+		{"Documents", fmt.Sprintf("%d of %d included", included, len(items))},
+	}, 2)
+
+	r.section("Contents", 12)
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		pages, status := "-", "Included"
+		if item.Skipped != "" {
+			status = "Skipped: " + item.Skipped
+		} else if item.Pages == 1 {
+			pages = fmt.Sprintf("%d", item.FirstPage)
+		} else {
+			pages = fmt.Sprintf("%d-%d", item.FirstPage, item.FirstPage+item.Pages-1)
+		}
+		rows[i] = []string{fmt.Sprintf("%d", i+1), item.Name, item.Kind, pages, status}
+	}
+	r.table([]string{"No", "Document", "Type", "Pages", "Status"}, []float64{10, contentW - 110, 20, 20, 60}, rows)
+
+	var buf bytes.Buffer
+	if err := r.pdf.Output(&buf); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), r.pdf.PageNo(), nil
+}
+
+// renderImagePage places an image attachment on its own page under the report header.
+func renderImagePage(snap Snapshot, name string, data []byte, date time.Time) ([]byte, error) {
+	r := newRenderer(snap, Options{Date: date, Images: func(string) ([]byte, error) { return data, nil }})
+	r.pdf.SetFooterFunc(nil)
+	r.pdf.SetAutoPageBreak(false, bottomMargin)
+	r.meta = [2]string{"Dispatch No: " + orDash(snap.DispatchNo), "Attachment: " + name}
+	r.pdf.AddPage()
+	if r.image(data) == nil {
+		return nil, fmt.Errorf("image %s: unsupported encoding", name)
+	}
+	r.section(name, 0)
+	top := r.pdf.GetY()
+	r.picture(name, margin, top, contentW, pageH-bottomMargin-top)
+
+	var buf bytes.Buffer
+	if err := r.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package reportpdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+func testPDF(t *testing.T, pages int) []byte {
+	t.Helper()
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	for i := 0; i < pages; i++ {
+		pdf.AddPage()
+		pdf.Cell(40, 10, fmt.Sprintf("Page %d", i+1))
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAttachmentPart(t *testing.T) {
+	dir := t.TempDir()
+	doc := testPDF(t, 3)
+	files := map[string][]byte{
+		"calibration.pdf": doc,
+		"photo.png":       testImage(t),
+		"quote.docx":      append([]byte("PK\x03\x04"), bytes.Repeat([]byte{0}, 64)...),
+		"broken.pdf":      doc[:len(doc)/3],
+		"renamed.dat":     doc,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		file, name string
+		kind       string
+		pages      int
+		skipped    string
+	}{
+		{"calibration.pdf", "calibration.pdf", KindPDF, 3, ""},
+		{"renamed.dat", "", KindPDF, 3, ""},
+		{"photo.png", "photo.png", KindImage, 1, ""},
+		{"quote.docx", "quote.docx", "DOCX", 0, "not a PDF or image"},
+		{"broken.pdf", "broken.pdf", KindPDF, 0, "PDF is damaged or password protected"},
+		{"gone.pdf", "gone.pdf", "PDF", 0, "file not found"},
+	}
+	snap := NewSnapshot(testReport(report.StatusDone))
+	date := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		att := report.ReportAttachment{FileName: tt.name, FilePath: filepath.Join(dir, tt.file)}
+		part := attachmentPart(att, snap, date)
+		if part.item.Kind != tt.kind || part.item.Pages != tt.pages || part.item.Skipped != tt.skipped {
+			t.Errorf("%s: item = %+v, want kind %s, %d pages, skipped %q", tt.file, part.item, tt.kind, tt.pages, tt.skipped)
+		}
+		if tt.name == "" && part.item.Name != tt.file {
+			t.Errorf("%s: unnamed attachment listed as %q", tt.file, part.item.Name)
+		}
+		if (part.doc == nil) != (tt.skipped != "") {
+			t.Errorf("%s: document kept = %v, skipped %q", tt.file, part.doc != nil, part.item.Skipped)
+		}
+		if part.doc != nil {
+			if pages, err := pdfPages(part.doc); err != nil || pages != tt.pages {
+				t.Errorf("%s: part document has %d pages (%v), want %d", tt.file, pages, err, tt.pages)
+			}
+		}
+	}
+}
+
+func TestUploadOrder(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2026, 3, 14, 9, minute, 0, 0, time.UTC) }
+	attachments := []report.ReportAttachment{
+		{ID: 4, FileName: "d", CreatedAt: at(30)},
+		{ID: 2, FileName: "b", CreatedAt: at(10)},
+		{ID: 3, FileName: "c", CreatedAt: at(10)},
+		{ID: 1, FileName: "a", CreatedAt: at(20)},
+	}
+	got := ""
+	for _, att := range uploadOrder(attachments) {
+		got += att.FileName
+	}
+	if got != "bcad" {
+		t.Errorf("upload order = %s, want bcad", got)
+	}
+	if attachments[0].ID != 4 {
+		t.Error("uploadOrder sorted the report's own slice")
+	}
+}
+
+func TestLayoutCover(t *testing.T) {
+	snap := NewSnapshot(testReport(report.StatusDone))
+	date := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	for _, files := range []int{3, 120} {
+		parts := []bundlePart{{item: BundleItem{Name: "Service Report", Kind: KindReport, Pages: 2}}}
+		for i := 0; i < files; i++ {
+			item := BundleItem{Name: fmt.Sprintf("attachment-%03d.pdf", i), Kind: KindPDF, Pages: 1 + i%3}
+			if i%5 == 4 {
+				item.Skipped = "PDF is damaged or password protected"
+				item.Pages = 0
+			}
+			parts = append(parts, bundlePart{item: item})
+		}
+		cover, err := layoutCover(snap, parts, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coverPages, err := pdfPages(cover)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files > 100 && coverPages < 2 {
+			t.Fatalf("%d files: cover has %d page, want the index to run over", files, coverPages)
+		}
+		next := coverPages + 1
+		for _, p := range parts {
+			want := next
+			if p.item.Skipped != "" {
+				want = 0
+			} else {
+				next += p.item.Pages
+			}
+			if p.item.FirstPage != want {
+				t.Errorf("%d files: %s starts on page %d, want %d", files, p.item.Name, p.item.FirstPage, want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package reportpdf
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler serves rendered report documents.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// PDF renders the two-page service report.
+func (h *Handler) PDF(c *gin.Context) {
+	r, ok := h.load(c)
+	if !ok {
+		return
+	}
+	doc, err := h.svc.Render(r)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	sendPDF(c, doc, fileName(r.DispatchNo, ""))
+}
+
+// Bundle serves the report with its attachments merged into one PDF.
+func (h *Handler) Bundle(c *gin.Context) {
+	r, ok := h.load(c)
+	if !ok {
+		return
+	}
+	doc, err := h.svc.Bundle(r)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	sendPDF(c, doc, fileName(r.DispatchNo, "-bundle"))
+}
+
+func (h *Handler) load(c *gin.Context) (*report.ServiceReport, bool) {
+	var uri struct {
+		ID uint64 `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return nil, false
+	}
+	r, err := h.svc.Load(c.Request.Context(), uri.ID, c.GetUint64("userID"), c.GetString("role"))
+	if err != nil {
+		writeError(c, err)
+		return nil, false
+	}
+	return r, true
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, report.ErrReportNotFound):
+		response.NotFound(c, "report not found")
+	case errors.Is(err, report.ErrReportForbidden):
+		response.ForbiddenWithMessage(c, "report not assigned to this technician")
+	default:
+		response.InternalError(c, err)
+	}
+}
+
+func sendPDF(c *gin.Context, doc []byte, name string) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	c.Data(http.StatusOK, "application/pdf", doc)
+}
+
+// fileName turns a dispatch number such as SR/2026/03/00042 into SR-2026-03-00042.pdf.
+func fileName(dispatchNo, suffix string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, dispatchNo)
+	if name == "" {
+		name = "service-report"
+	}
+	return name + suffix + ".pdf"
+}
@@ -0,0 +1,21 @@
+package reportpdf
+
+import "testing"
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		dispatchNo, suffix, want string
+	}{
+		{"SR/2026/03/00042", "", "SR-2026-03-00042.pdf"},
+		{"SR/2026/03/00042", "-bundle", "SR-2026-03-00042-bundle.pdf"},
+		{"KMS 26.03_7", "", "KMS-26-03_7.pdf"},
+		{`"a";b`, "", "-a--b.pdf"},
+		{"Laporan/é", "", "Laporan--.pdf"},
+		{"", "", "service-report.pdf"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.dispatchNo, tt.suffix); got != tt.want {
+			t.Errorf("fileName(%q, %q) = %q, want %q", tt.dispatchNo, tt.suffix, got, tt.want)
+		}
+	}
+}
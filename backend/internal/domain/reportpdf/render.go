@@ -0,0 +1,645 @@
+package reportpdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+)
+
+var (
+	//go:embed assets/logo.png
+	logoPNG []byte
+	//go:embed assets/watermark.png
+	watermarkPNG []byte
+	//go:embed assets/survey-qr.png
+	surveyQRPNG []byte
+)
+
+const (
+	companyName = "PT. KANDA MEDICAL SOLUTIONS INDONESIA"
+	companyLine = "medical equipment business, Service and maintenance."
+	surveyURL   = "https://docs.google.com/forms/d/e/1FAIpQLSdyNgH3_wVZnAnh-g5AF6g9QYWH-p6TYvAE_nz55DGlqqp8lw/viewform"
+)
+
+// Page geometry in millimetres.
+const (
+	pageW        = 210.0
+	pageH        = 297.0
+	margin       = 12.0
+	contentW     = pageW - 2*margin
+	contentTop   = 32.0
+	bottomMargin = 16.0
+	maxToolRows  = 6
+	maxEvidence  = 6
+)
+
+type rgb struct{ r, g, b int }
+
+var (
+	colorText   = rgb{20, 27, 45}
+	colorMuted  = rgb{123, 128, 151}
+	colorLabel  = rgb{144, 150, 171}
+	colorBorder = rgb{207, 212, 228}
+	colorRule   = rgb{227, 230, 240}
+	colorFill   = rgb{244, 246, 251}
+	colorDraft  = rgb{220, 38, 38}
+)
+
+// ImageLoader returns the bytes of an image referenced by the report, such as an upload
+// URL or a data URL.
+type ImageLoader func(ref string) ([]byte, error)
+
+// Options controls document metadata. Date is stamped as the creation date so that the
+// same report always renders to the same bytes. VerifyURL, when set, is printed as a
+// verification QR code next to the survey code.
+type Options struct {
+	Date      time.Time
+	Images    ImageLoader
+	VerifyURL string
+}
+
+// Render writes the two-page service report for snap.
+func Render(w io.Writer, snap Snapshot, opts Options) error {
+	r := newRenderer(snap, opts)
+	r.pageOne()
+	r.pageTwo()
+	return r.pdf.Output(w)
+}
+
+type renderer struct {
+	pdf    *fpdf.Fpdf
+	tr     func(string) string
+	snap   Snapshot
+	opts   Options
+	meta   [2]string
+	images map[string]*fpdf.ImageInfoType
+}
+
+func newRenderer(snap Snapshot, opts Options) *renderer {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(opts.Date)
+	pdf.SetModificationDate(opts.Date)
+	pdf.SetProducer("internal-service-report", true)
+	pdf.SetTitle("Service Report "+snap.DispatchNo, true)
+	pdf.SetMargins(margin, contentTop, margin)
+	pdf.SetAutoPageBreak(true, bottomMargin)
+	pdf.AliasNbPages("")
+	r := &renderer{
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		snap:   snap,
+		opts:   opts,
+		images: map[string]*fpdf.ImageInfoType{},
+	}
+	pdf.SetHeaderFuncMode(r.header, true)
+	pdf.SetFooterFunc(r.footer)
+	return r
+}
+
+func (r *renderer) color(c rgb) {
+	r.pdf.SetTextColor(c.r, c.g, c.b)
+}
+
+func (r *renderer) font(style string, size float64, c rgb) {
+	r.pdf.SetFont("Helvetica", style, size)
+	r.color(c)
+}
+
+func (r *renderer) header() {
+	pdf := r.pdf
+	if wm := r.image(watermarkPNG); wm != nil {
+		w := 120.0
+		h := w * wm.Height() / wm.Width()
+		pdf.SetAlpha(0.08, "Normal")
+		pdf.ImageOptions(r.imageName(watermarkPNG), (pageW-w)/2, (pageH-h)/2, w, h, false, fpdf.ImageOptions{}, 0, "")
+		pdf.SetAlpha(1, "Normal")
+	}
+	if r.snap.Draft {
+		r.draftStamp()
+	}
+	if logo := r.image(logoPNG); logo != nil {
+		pdf.ImageOptions(r.imageName(logoPNG), margin, 10, 0, 14, false, fpdf.ImageOptions{}, 0, "")
+	}
+	x := margin + 34
+	pdf.SetXY(x, 10)
+	r.font("B", 6.5, colorMuted)
+	pdf.CellFormat(90, 4, "SERVICE REPORT", "", 2, "L", false, 0, "")
+	r.font("B", 12.5, colorText)
+	pdf.CellFormat(110, 6, r.tr(companyName), "", 2, "L", false, 0, "")
+	r.font("", 7.5, colorMuted)
+	pdf.CellFormat(110, 4, r.tr(companyLine), "", 0, "L", false, 0, "")
+
+	r.font("B", 7.5, colorMuted)
+	for i, line := range r.meta {
+		pdf.SetXY(pageW-margin-60, 11+float64(i)*4.5)
+		pdf.CellFormat(60, 4.5, r.tr(line), "", 0, "R", false, 0, "")
+	}
+	pdf.SetDrawColor(colorRule.r, colorRule.g, colorRule.b)
+	pdf.SetLineWidth(0.3)
+	pdf.Line(margin, 27, pageW-margin, 27)
+	pdf.SetXY(margin, contentTop)
+}
+
+// draftStamp prints DRAFT across the page, so an unapproved report cannot pass for the
+// signed copy.
+func (r *renderer) draftStamp() {
+	pdf := r.pdf
+	pdf.SetAlpha(0.15, "Normal")
+	r.font("B", 110, colorDraft)
+	pdf.TransformBegin()
+	pdf.TransformRotate(55, pageW/2, pageH/2)
+	w := pdf.GetStringWidth("DRAFT")
+	pdf.Text((pageW-w)/2, pageH/2+15, "DRAFT")
+	pdf.TransformEnd()
+	pdf.SetAlpha(1, "Normal")
+}
+
+func (r *renderer) footer() {
+	pdf := r.pdf
+	pdf.SetY(-10)
+	r.font("", 7, colorMuted)
+	pdf.CellFormat(contentW/2, 4, r.tr(r.snap.DispatchNo), "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentW/2, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+}
+
+// ensure starts a new page when fewer than h millimetres are left.
+func (r *renderer) ensure(h float64) {
+	if r.pdf.GetY()+h > pageH-bottomMargin {
+		r.pdf.AddPage()
+	}
+}
+
+func (r *renderer) section(title string, minHeight float64) {
+	r.ensure(8 + minHeight)
+	pdf := r.pdf
+	pdf.Ln(2)
+	r.font("B", 7.5, colorMuted)
+	pdf.CellFormat(contentW, 5, strings.ToUpper(r.tr(title)), "", 1, "L", false, 0, "")
+	pdf.SetDrawColor(colorRule.r, colorRule.g, colorRule.b)
+	pdf.Line(margin, pdf.GetY(), pageW-margin, pdf.GetY())
+	pdf.Ln(1.5)
+}
+
+type field struct {
+	label, value string
+}
+
+func orDash(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "-"
+	}
+	return v
+}
+
+// grid prints label and value pairs in columns, wrapping long values.
+func (r *renderer) grid(fields []field, columns int) {
+	pdf := r.pdf
+	colW := contentW / float64(columns)
+	for start := 0; start < len(fields); start += columns {
+		end := start + columns
+		if end > len(fields) {
+			end = len(fields)
+		}
+		row := fields[start:end]
+		r.font("", 9, colorText)
+		lines := 1
+		for _, f := range row {
+			if n := r.lineCount(r.tr(orDash(f.value)), colW-3); n > lines {
+				lines = n
+			}
+		}
+		h := 4 + float64(lines)*4.5 + 1.5
+		r.ensure(h)
+		y := pdf.GetY()
+		for i, f := range row {
+			x := margin + float64(i)*colW
+			pdf.SetXY(x, y)
+			r.font("B", 6.5, colorLabel)
+			pdf.CellFormat(colW-3, 4, strings.ToUpper(r.tr(f.label)), "", 2, "L", false, 0, "")
+			pdf.SetX(x)
+			r.font("", 9, colorText)
+			pdf.MultiCell(colW-3, 4.5, r.tr(orDash(f.value)), "", "L", false)
+		}
+		pdf.SetXY(margin, y+h)
+	}
+}
+
+// table prints rows under a shaded header, wrapping cells that do not fit.
+func (r *renderer) table(headers []string, widths []float64, rows [][]string) {
+	pdf := r.pdf
+	drawHeader := func() {
+		pdf.SetFillColor(colorFill.r, colorFill.g, colorFill.b)
+		r.font("B", 7, colorMuted)
+		for i, h := range headers {
+			pdf.CellFormat(widths[i], 6, strings.ToUpper(r.tr(h)), "", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	r.ensure(12)
+	drawHeader()
+	pdf.SetDrawColor(colorRule.r, colorRule.g, colorRule.b)
+	for _, row := range rows {
+		r.font("", 8.5, colorText)
+		lines := 1
+		for i, cell := range row {
+			if n := r.lineCount(r.tr(orDash(cell)), widths[i]-2); n > lines {
+				lines = n
+			}
+		}
+		h := float64(lines)*4.2 + 1.8
+		if pdf.GetY()+h > pageH-bottomMargin {
+			pdf.AddPage()
+			drawHeader()
+			r.font("", 8.5, colorText)
+		}
+		y := pdf.GetY()
+		x := margin
+		for i, cell := range row {
+			pdf.SetXY(x+1, y+0.9)
+			pdf.MultiCell(widths[i]-2, 4.2, r.tr(orDash(cell)), "", "L", false)
+			x += widths[i]
+		}
+		pdf.Line(margin, y+h, margin+sum(widths), y+h)
+		pdf.SetXY(margin, y+h)
+	}
+}
+
+// lineCount returns how many lines text wraps to in width w with the current font.
+// SplitLines works on the translated single-byte text; SplitText expects UTF-8.
+func (r *renderer) lineCount(text string, w float64) int {
+	return len(r.pdf.SplitLines([]byte(text), w))
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func (r *renderer) pageOne() {
+	s := r.snap
+	r.meta = [2]string{"Dispatch No: " + orDash(s.DispatchNo), "Service Engineer: " + orDash(firstText(s.CarriedBy, s.CustomerPerson))}
+	r.pdf.AddPage()
+
+	device := DeviceRow{}
+	if len(s.DeviceRows) > 0 {
+		device = s.DeviceRows[0]
+	}
+	jobs := strings.Join(s.JobInfo, ", ")
+
+	r.section("Customer Details", 20)
+	r.grid([]field{
+		{"Dispatch No", s.DispatchNo},
+		{"Dispatch Date", formatDate(s.DispatchDate)},
+		{"Customer Name", s.CustomerName},
+		{"Department", s.Department},
+		{"Contact", s.CustomerPerson},
+		{"Phone", s.Phone},
+		{"Email", s.Email},
+		{"Address", s.Address},
+	}, 2)
+
+	r.section("Equipment Details", 20)
+	r.grid([]field{
+		{"Equipment Status", jobs},
+		{"Product Description", device.Description},
+		{"Part Number", device.PartNo},
+		{"Serial Number", device.SerialNo},
+	}, 2)
+
+	r.section("Activity", 20)
+	r.grid([]field{
+		{"Activity", jobs},
+		{"Report Problem", s.ProblemDescription},
+		{"Identified Problem", s.ServiceDescription},
+		{"Solution", s.Conclusion},
+		{"Close Date", formatDate(s.FinalizedDate)},
+		{"Field Engineer", s.CarriedBy},
+	}, 1)
+
+	r.section("Labor", 10)
+	laborType := "Maintenance"
+	if len(s.JobInfo) > 0 {
+		laborType = s.JobInfo[0]
+	}
+	hours := "-"
+	if s.TravelStartTime != "" && s.TravelFinishTime != "" {
+		hours = s.TravelStartTime + " - " + s.TravelFinishTime
+	}
+	r.grid([]field{
+		{"Date", formatDate(s.CarriedDate)},
+		{"Type", laborType},
+		{"Hours", hours},
+	}, 3)
+
+	r.section("Tools", 12)
+	tools := s.Tools
+	if len(tools) == 0 {
+		tools = []ToolRow{{}}
+	}
+	if len(tools) > maxToolRows {
+		tools = tools[:maxToolRows]
+	}
+	rows := make([][]string, len(tools))
+	for i, t := range tools {
+		rows[i] = []string{t.Code, t.Description, t.UsableLimit}
+	}
+	r.table([]string{"Code/SN", "Description", "Usable limits"}, []float64{45, contentW - 90, 45}, rows)
+}
+
+func (r *renderer) pageTwo() {
+	s := r.snap
+	r.meta = [2]string{"Labor Date: " + formatDate(s.CarriedDate), "Customer: " + orDash(s.CustomerName)}
+	r.pdf.AddPage()
+
+	r.section("Materials", 12)
+	parts := s.Spareparts
+	if len(parts) == 0 {
+		parts = []SparePartRow{{}}
+	}
+	rows := make([][]string, len(parts))
+	for i, p := range parts {
+		rows[i] = []string{p.Qty, p.PartNo, p.Description, p.Status}
+	}
+	r.table([]string{"Qty", "Part No", "Description", "Status"}, []float64{18, 36, contentW - 90, 36}, rows)
+
+	r.section("Evidence", 30)
+	r.evidence()
+
+	r.section("Recommendation & Signature", 70)
+	r.recommendation()
+	r.signatures()
+
+	r.section("Customer Satisfaction", 32)
+	r.survey()
+}
+
+func limit(items []string, n int) []string {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
+
+func (r *renderer) evidence() {
+	pdf := r.pdf
+	before := limit(r.snap.BeforeEvidence, maxEvidence)
+	after := limit(r.snap.AfterEvidence, maxEvidence)
+	y := pdf.GetY()
+	if len(before)+len(after) == 0 {
+		r.dashedBox(margin, y, contentW, 20)
+		pdf.SetXY(margin, y+22)
+		return
+	}
+	half := (contentW - 6) / 2
+	const gap, thumbH = 2.0, 21.0
+	thumbW := (half - 2*gap) / 3
+	bottom := y
+	for i, group := range []struct {
+		title  string
+		images []string
+	}{{"Before", before}, {"After", after}} {
+		x := margin + float64(i)*(half+6)
+		pdf.SetXY(x, y)
+		r.font("B", 6.5, colorLabel)
+		pdf.CellFormat(half, 4, strings.ToUpper(group.title), "", 0, "L", false, 0, "")
+		for j, ref := range group.images {
+			cx := x + float64(j%3)*(thumbW+gap)
+			cy := y + 5 + float64(j/3)*(thumbH+gap)
+			r.picture(ref, cx, cy, thumbW, thumbH)
+			if end := cy + thumbH; end > bottom {
+				bottom = end
+			}
+		}
+	}
+	pdf.SetXY(margin, bottom+3)
+}
+
+func (r *renderer) recommendation() {
+	pdf := r.pdf
+	text := r.snap.Recommendation
+	if strings.TrimSpace(text) == "" {
+		text = "Tidak ada catatan."
+	}
+	r.font("", 9, colorText)
+	lines := r.lineCount(r.tr(text), contentW-4)
+	h := float64(lines)*4.5 + 4
+	if h < 24 {
+		h = 24
+	}
+	r.ensure(h + 2)
+	y := pdf.GetY()
+	r.dashedBox(margin, y, contentW, h)
+	pdf.SetXY(margin+2, y+2)
+	pdf.MultiCell(contentW-4, 4.5, r.tr(text), "", "L", false)
+	pdf.SetXY(margin, y+h+3)
+}
+
+func (r *renderer) signatures() {
+	pdf := r.pdf
+	s := r.snap
+	const blockH = 42.0
+	r.ensure(blockH)
+	y := pdf.GetY()
+	half := (contentW - 6) / 2
+	for i, sig := range []struct{ title, name, image, date string }{
+		{"Engineer Signature", s.CarriedBy, s.CarriedSignature, s.CarriedDate},
+		{"Customer Signature", s.ApprovedBy, s.ApprovedSignature, s.ApprovedDate},
+	} {
+		x := margin + float64(i)*(half+6)
+		pdf.SetFillColor(248, 249, 254)
+		pdf.SetDrawColor(225, 228, 240)
+		pdf.RoundedRect(x, y, half, blockH, 2, "1234", "FD")
+		pdf.SetXY(x+3, y+2)
+		r.font("B", 6.5, colorLabel)
+		pdf.CellFormat(half-6, 4, strings.ToUpper(sig.title), "", 0, "L", false, 0, "")
+		box := struct{ x, y, w, h float64 }{x + 3, y + 7, half - 6, 20}
+		pdf.SetFillColor(255, 255, 255)
+		r.dashedBox(box.x, box.y, box.w, box.h)
+		if sig.image != "" {
+			r.picture(sig.image, box.x+2, box.y+1, box.w-4, box.h-2)
+		} else {
+			pdf.SetXY(box.x, box.y+box.h/2-2)
+			r.font("", 7.5, colorLabel)
+			pdf.CellFormat(box.w, 4, "No signature", "", 0, "C", false, 0, "")
+		}
+		pdf.SetXY(x+3, box.y+box.h+2)
+		r.font("B", 9, colorText)
+		pdf.CellFormat(half-6, 5, r.tr(orDash(sig.name)), "", 2, "L", false, 0, "")
+		r.font("", 7.5, colorMuted)
+		date := "-"
+		if sig.date != "" {
+			date = formatDate(sig.date)
+		}
+		pdf.CellFormat(half-6, 4, r.tr(date), "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(margin, y+blockH+3)
+}
+
+func (r *renderer) survey() {
+	pdf := r.pdf
+	const qr = 28.0
+	r.ensure(qr + 6)
+	y := pdf.GetY()
+	textW := contentW - qr - 8
+	if r.opts.VerifyURL != "" {
+		textW -= qr + 6
+	}
+	pdf.SetXY(margin, y+2)
+	r.font("B", 9, colorText)
+	pdf.CellFormat(textW, 5, "Customer Satisfaction", "", 2, "L", false, 0, "")
+	r.font("", 7.5, colorMuted)
+	pdf.CellFormat(textW, 5, "Scan QR code untuk mengisi feedback layanan.", "", 2, "L", false, 0, "")
+	r.font("", 6.5, colorMuted)
+	pdf.MultiCell(textW, 3.5, surveyURL, "", "L", false)
+	if r.opts.VerifyURL != "" {
+		pdf.SetX(margin)
+		r.font("B", 9, colorText)
+		pdf.CellFormat(textW, 6, "Report Verification", "", 2, "L", false, 0, "")
+		r.font("", 7.5, colorMuted)
+		pdf.MultiCell(textW, 4, "Scan QR verifikasi untuk memeriksa keaslian laporan ini tanpa login.", "", "L", false)
+		x := pageW - margin - 2*qr - 6
+		r.qrCode(r.opts.VerifyURL, x, y, qr)
+		r.caption("VERIFY", x, y+qr, qr)
+	}
+	if info := r.image(surveyQRPNG); info != nil {
+		pdf.ImageOptions(r.imageName(surveyQRPNG), pageW-margin-qr, y, qr, qr, false, fpdf.ImageOptions{}, 0, "")
+		if r.opts.VerifyURL != "" {
+			r.caption("SURVEY", pageW-margin-qr, y+qr, qr)
+		}
+	}
+	pdf.SetXY(margin, y+qr+6)
+}
+
+func (r *renderer) caption(text string, x, y, w float64) {
+	r.pdf.SetXY(x, y)
+	r.font("B", 6.5, colorLabel)
+	r.pdf.CellFormat(w, 4, text, "", 0, "C", false, 0, "")
+}
+
+// qrCode draws text as a QR code of the given size, one filled rectangle per run of dark
+// modules, with the quiet zone inside the square.
+func (r *renderer) qrCode(text string, x, y, size float64) {
+	code, err := qr.Encode(text, qr.M, qr.Auto)
+	if err != nil {
+		r.dashedBox(x, y, size, size)
+		return
+	}
+	const quiet = 2
+	bounds := code.Bounds()
+	n := bounds.Dx()
+	module := size / float64(n+2*quiet)
+	pdf := r.pdf
+	pdf.SetFillColor(0, 0, 0)
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; {
+			if !dark(code, bounds.Min.X+col, bounds.Min.Y+row) {
+				col++
+				continue
+			}
+			start := col
+			for col < n && dark(code, bounds.Min.X+col, bounds.Min.Y+row) {
+				col++
+			}
+			pdf.Rect(x+float64(quiet+start)*module, y+float64(quiet+row)*module, float64(col-start)*module, module, "F")
+		}
+	}
+}
+
+func dark(img image.Image, x, y int) bool {
+	c, _, _, _ := img.At(x, y).RGBA()
+	return c < 0x8000
+}
+
+func (r *renderer) dashedBox(x, y, w, h float64) {
+	pdf := r.pdf
+	pdf.SetDrawColor(colorBorder.r, colorBorder.g, colorBorder.b)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.RoundedRect(x, y, w, h, 2, "1234", "D")
+	pdf.SetDashPattern([]float64{}, 0)
+}
+
+// picture fits an image into a box, keeping its aspect ratio. Images that cannot be
+// loaded leave a placeholder.
+func (r *renderer) picture(ref string, x, y, w, h float64) {
+	pdf := r.pdf
+	var info *fpdf.ImageInfoType
+	var data []byte
+	if r.opts.Images != nil {
+		if raw, err := r.opts.Images(ref); err == nil {
+			data = raw
+			info = r.image(data)
+		}
+	}
+	if info == nil {
+		r.dashedBox(x, y, w, h)
+		pdf.SetXY(x, y+h/2-2)
+		r.font("", 6.5, colorLabel)
+		pdf.CellFormat(w, 4, "Image unavailable", "", 0, "C", false, 0, "")
+		return
+	}
+	iw, ih := info.Width(), info.Height()
+	scale := w / iw
+	if ih*scale > h {
+		scale = h / ih
+	}
+	dw, dh := iw*scale, ih*scale
+	pdf.ImageOptions(r.imageName(data), x+(w-dw)/2, y+(h-dh)/2, dw, dh, false, fpdf.ImageOptions{}, 0, "")
+}
+
+func (r *renderer) imageName(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// image registers data once. JPEG files are embedded as they are; every other format is
+// decoded and stored as 8-bit PNG, the only kind fpdf reads without errors that would
+// spoil the whole document.
+func (r *renderer) image(data []byte) *fpdf.ImageInfoType {
+	name := r.imageName(data)
+	if info, ok := r.images[name]; ok {
+		return info
+	}
+	var info *fpdf.ImageInfoType
+	if embedded, kind, ok := normalizeImage(data); ok {
+		info = r.pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: kind}, bytes.NewReader(embedded))
+	}
+	r.images[name] = info
+	return info
+}
+
+func normalizeImage(data []byte) ([]byte, string, bool) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false
+	}
+	if format == "jpeg" {
+		if cfg, err := jpeg.DecodeConfig(bytes.NewReader(data)); err == nil && cfg.Width > 0 {
+			return data, "JPG", true
+		}
+	}
+	bounds := img.Bounds()
+	rgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rgba); err != nil {
+		return nil, "", false
+	}
+	return buf.Bytes(), "PNG", true
+}
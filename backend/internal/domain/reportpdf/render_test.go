@@ -0,0 +1,95 @@
+package reportpdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+func testReport(status string) *report.ServiceReport {
+	return &report.ServiceReport{
+		ID:         42,
+		DispatchNo: "SR/2026/03/00042",
+		Status:     status,
+		OpenedAt:   time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+		FormPayload: []byte(`{"dispatchNo":"SR/2026/03/00042","customerName":"RS Citra Medika","address":"Jl. Merdeka 1",
+			"problemDescription":"Alarm sensor tekanan","deviceRows":[{"description":"Syringe pump","serialNo":"SP-001"}]}`),
+		TeknisiPayload: []byte(`{"serviceDescription":"Sensor diganti","conclusion":"Normal",
+			"beforeEvidence":["/uploads/before.png"],"afterEvidence":["data:image/png;base64,not-an-image"],
+			"spareparts":[{"qty":"1","partNo":"SEN-02","description":"Pressure sensor"}]}`),
+	}
+}
+
+func testImage(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.NRGBA{R: 200, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderIsDeterministic(t *testing.T) {
+	photo := testImage(t)
+	opts := Options{
+		Date:      time.Date(2026, 3, 15, 16, 30, 0, 0, time.UTC),
+		Images:    func(string) ([]byte, error) { return photo, nil },
+		VerifyURL: "https://reports.example.com/verify/abc",
+	}
+	snap := NewSnapshot(testReport(report.StatusDone))
+	var first, second bytes.Buffer
+	if err := Render(&first, snap, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := Render(&second, snap, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("rendering the same snapshot twice gave different documents")
+	}
+	if pages, err := pdfPages(first.Bytes()); err != nil || pages != 2 {
+		t.Errorf("pdfPages = %d, %v; want 2 pages", pages, err)
+	}
+}
+
+func TestRenderDraftStamp(t *testing.T) {
+	tests := []struct {
+		status string
+		draft  bool
+	}{
+		{report.StatusOpen, true},
+		{report.StatusProgress, true},
+		{report.StatusReview, true},
+		{report.StatusDone, false},
+	}
+	for _, tt := range tests {
+		snap := NewSnapshot(testReport(tt.status))
+		if snap.Draft != tt.draft {
+			t.Errorf("%s: Draft = %v, want %v", tt.status, snap.Draft, tt.draft)
+		}
+		r := newRenderer(snap, Options{Date: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)})
+		r.pdf.SetCompression(false)
+		r.pageOne()
+		r.pageTwo()
+		var buf bytes.Buffer
+		if err := r.pdf.Output(&buf); err != nil {
+			t.Fatal(err)
+		}
+		stamps := strings.Count(buf.String(), "(DRAFT) Tj")
+		want := 0
+		if tt.draft {
+			want = r.pdf.PageCount()
+		}
+		if stamps != want {
+			t.Errorf("%s: %d pages stamped DRAFT, want %d", tt.status, stamps, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package reportpdf renders service reports to PDF on the server.
+package reportpdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/internal/domain/user"
+)
+
+var ErrImageSource = errors.New("image is not an upload or data URL")
+
+// VerifyLinker returns the public verification URL of a report, or "" when it has none.
+type VerifyLinker interface {
+	VerifyURL(reportID uint64) (string, error)
+}
+
+// Service renders reports loaded through the report service.
+type Service struct {
+	reports   *report.Service
+	uploadDir string
+	links     VerifyLinker
+}
+
+func NewService(reports *report.Service, uploadDir string) *Service {
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	return &Service{reports: reports, uploadDir: uploadDir}
+}
+
+// UseVerifyLinks makes rendered reports carry a verification QR code.
+func (s *Service) UseVerifyLinks(links VerifyLinker) {
+	s.links = links
+}
+
+// Load returns a report the caller may print: any report for admins, assigned reports
+// for technicians.
+func (s *Service) Load(ctx context.Context, reportID, userID uint64, role string) (*report.ServiceReport, error) {
+	if role == user.RoleAdmin || role == user.RoleMasterAdmin {
+		return s.reports.GetByID(ctx, reportID)
+	}
+	return s.reports.GetForTechnician(ctx, reportID, userID)
+}
+
+// Render builds the PDF of a report. The document is dated with the report's completion,
+// or its creation while open, so repeated renders of the same report are identical as
+// long as its verification token stays the same. Reports that are not approved render
+// as drafts without a verification QR code.
+func (s *Service) Render(r *report.ServiceReport) ([]byte, error) {
+	snap := NewSnapshot(r)
+	if snap.ApprovedBy == "" && r.ApprovedBy != nil {
+		name, err := s.reports.UserName(context.Background(), *r.ApprovedBy)
+		if err != nil {
+			return nil, err
+		}
+		snap.ApprovedBy = name
+	}
+	opts := Options{Date: documentDate(r), Images: s.loadImage}
+	if s.links != nil && !snap.Draft {
+		url, err := s.links.VerifyURL(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		opts.VerifyURL = url
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, snap, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func documentDate(r *report.ServiceReport) time.Time {
+	if r.CompletedAt != nil {
+		return *r.CompletedAt
+	}
+	return r.OpenedAt
+}
+
+// loadImage reads data URLs and files under /uploads. Remote URLs are not fetched, so
+// rendering never depends on the network.
+func (s *Service) loadImage(ref string) ([]byte, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "data:") {
+		comma := strings.IndexByte(ref, ',')
+		if comma < 0 || !strings.Contains(ref[:comma], ";base64") {
+			return nil, ErrImageSource
+		}
+		return base64.StdEncoding.DecodeString(ref[comma+1:])
+	}
+	path, ok := s.uploadPath(ref)
+	if !ok {
+		return nil, ErrImageSource
+	}
+	return os.ReadFile(path)
+}
+
+// uploadPath maps an /uploads URL to a file inside the upload directory.
+func (s *Service) uploadPath(ref string) (string, bool) {
+	if i := strings.Index(ref, "/uploads/"); i >= 0 {
+		ref = ref[i+len("/uploads/"):]
+	} else {
+		return "", false
+	}
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	rel := filepath.Clean(filepath.FromSlash(ref))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(s.uploadDir, rel), true
+}
@@ -0,0 +1,31 @@
+package reportpdf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadPath(t *testing.T) {
+	s := NewService(nil, "/srv/uploads")
+	tests := []struct {
+		ref  string
+		want string
+		ok   bool
+	}{
+		{"/uploads/reports/42/before.jpg", "/srv/uploads/reports/42/before.jpg", true},
+		{"https://reports.example.com/uploads/reports/42/a.png?v=2#top", "/srv/uploads/reports/42/a.png", true},
+		{"/uploads/reports/../42/a.png", "/srv/uploads/42/a.png", true},
+		{"/uploads/../config.env", "", false},
+		{"/uploads/reports/../../config.env", "", false},
+		{"/uploads/..", "", false},
+		{"/uploads/", "", false},
+		{"/static/logo.png", "", false},
+		{"reports/42/a.png", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := s.uploadPath(tt.ref)
+		if ok != tt.ok || got != filepath.FromSlash(tt.want) {
+			t.Errorf("uploadPath(%q) = %q %v, want %q %v", tt.ref, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package reportpdf
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+// Snapshot is the content of the printed service report. It mirrors what the browser
+// printout reads from the dispatch and technician forms.
+type Snapshot struct {
+	DispatchNo         string
+	DispatchDate       string
+	CustomerName       string
+	CustomerPerson     string
+	Department         string
+	Address            string
+	Phone              string
+	Email              string
+	FinalizedDate      string
+	JobInfo            []string
+	ProblemDescription string
+	ServiceDescription string
+	Conclusion         string
+	Recommendation     string
+	CarriedBy          string
+	CarriedDate        string
+	ApprovedBy         string
+	ApprovedDate       string
+	CarriedSignature   string
+	ApprovedSignature  string
+	TravelStartTime    string
+	TravelFinishTime   string
+	BeforeEvidence     []string
+	AfterEvidence      []string
+	Spareparts         []SparePartRow
+	Tools              []ToolRow
+	DeviceRows         []DeviceRow
+	// Draft marks a report that has not been approved; every page is stamped DRAFT.
+	Draft bool
+}
+
+type SparePartRow struct {
+	Qty         string
+	PartNo      string
+	Description string
+	Status      string
+}
+
+type ToolRow struct {
+	Code        string
+	Description string
+	UsableLimit string
+}
+
+type DeviceRow struct {
+	PartNo      string
+	Description string
+	SerialNo    string
+}
+
+// payloadFields reads form values as text whether they were sent as strings or numbers.
+type payloadFields map[string]interface{}
+
+func (p payloadFields) text(key string) string {
+	switch v := p[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+func (p payloadFields) list(key string) []string {
+	items, _ := p[key].([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+func (p payloadFields) rows(key string) []payloadFields {
+	items, _ := p[key].([]interface{})
+	out := make([]payloadFields, 0, len(items))
+	for _, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			out = append(out, payloadFields(row))
+		}
+	}
+	return out
+}
+
+func firstText(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// NewSnapshot merges the dispatch form, the technician form and the report columns. The
+// technician's values win over the dispatch form, and the report's dispatch number and
+// customer fill gaps left by both.
+func NewSnapshot(r *report.ServiceReport) Snapshot {
+	fields := payloadFields{}
+	for _, raw := range [][]byte{r.FormPayload, r.TeknisiPayload} {
+		if len(raw) == 0 {
+			continue
+		}
+		var layer map[string]interface{}
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.UseNumber()
+		if err := dec.Decode(&layer); err != nil {
+			continue
+		}
+		for k, v := range layer {
+			if s, ok := v.(string); ok && strings.TrimSpace(s) == "" {
+				if _, exists := fields[k]; exists {
+					continue
+				}
+			}
+			fields[k] = v
+		}
+	}
+
+	snap := Snapshot{
+		Draft:              !report.IsPrintable(r.Status),
+		DispatchNo:         r.DispatchNo,
+		DispatchDate:       fields.text("dispatchDate"),
+		CustomerName:       firstText(fields.text("customerName"), r.CustomerName),
+		CustomerPerson:     fields.text("customerPerson"),
+		Department:         fields.text("department"),
+		Address:            firstText(fields.text("address"), r.CustomerAddress),
+		Phone:              fields.text("phone"),
+		Email:              fields.text("email"),
+		FinalizedDate:      fields.text("finalizedDate"),
+		JobInfo:            fields.list("jobInfo"),
+		ProblemDescription: firstText(fields.text("problemDescription"), r.Complaint),
+		ServiceDescription: fields.text("serviceDescription"),
+		Conclusion:         fields.text("conclusion"),
+		Recommendation:     fields.text("recommendation"),
+		CarriedBy:          firstText(fields.text("carriedBy"), fields.text("fseName")),
+		CarriedDate:        fields.text("carriedDate"),
+		ApprovedBy:         fields.text("approvedBy"),
+		ApprovedDate:       fields.text("approvedDate"),
+		CarriedSignature:   fields.text("carriedSignature"),
+		ApprovedSignature:  fields.text("approvedSignature"),
+		TravelStartTime:    fields.text("travelStartTime"),
+		TravelFinishTime:   fields.text("travelFinishTime"),
+		BeforeEvidence:     fields.list("beforeEvidence"),
+		AfterEvidence:      fields.list("afterEvidence"),
+	}
+	if snap.DispatchDate == "" {
+		snap.DispatchDate = fields.text("notifOpen")
+	}
+	// Approval is recorded in the report columns; the form fields only override them.
+	if snap.ApprovedDate == "" && r.ApprovedAt != nil {
+		snap.ApprovedDate = r.ApprovedAt.Format("2006-01-02")
+	}
+	if snap.FinalizedDate == "" && r.CompletedAt != nil {
+		snap.FinalizedDate = r.CompletedAt.Format("2006-01-02")
+	}
+	if len(snap.BeforeEvidence) == 0 && fields.text("beforeImage") != "" {
+		snap.BeforeEvidence = []string{fields.text("beforeImage")}
+	}
+	if len(snap.AfterEvidence) == 0 && fields.text("afterImage") != "" {
+		snap.AfterEvidence = []string{fields.text("afterImage")}
+	}
+	for _, row := range fields.rows("spareparts") {
+		item := SparePartRow{Qty: row.text("qty"), PartNo: row.text("partNo"), Description: row.text("description"), Status: row.text("status")}
+		if item != (SparePartRow{}) {
+			snap.Spareparts = append(snap.Spareparts, item)
+		}
+	}
+	for _, row := range fields.rows("tools") {
+		item := ToolRow{Code: row.text("code"), Description: row.text("description"), UsableLimit: row.text("usableLimit")}
+		if item != (ToolRow{}) {
+			snap.Tools = append(snap.Tools, item)
+		}
+	}
+	for _, row := range fields.rows("deviceRows") {
+		item := DeviceRow{PartNo: row.text("partNo"), Description: row.text("description"), SerialNo: row.text("serialNo")}
+		if item != (DeviceRow{}) {
+			snap.DeviceRows = append(snap.DeviceRows, item)
+		}
+	}
+	if len(snap.DeviceRows) == 0 && (r.DeviceName != "" || r.SerialNumber != "") {
+		snap.DeviceRows = []DeviceRow{{Description: r.DeviceName, SerialNo: r.SerialNumber}}
+	}
+	return snap
+}
+
+var payloadDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02 15:04:05"}
+
+// formatDate prints payload dates as 02 Jan 2006 and leaves anything else as typed.
+func formatDate(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "-"
+	}
+	for _, layout := range payloadDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("02 Jan 2006")
+		}
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package seal
+
+import "time"
+
+// Verification compares a report with its latest seal.
+type Verification struct {
+	ReportID     uint64      `json:"report_id"`
+	DispatchNo   string      `json:"dispatch_no"`
+	ReportStatus string      `json:"report_status"`
+	Status       string      `json:"status"`
+	Changed      []string    `json:"changed,omitempty"`
+	CurrentHash  string      `json:"current_hash"`
+	Seal         *ReportSeal `json:"seal"`
+	PublicKey    string      `json:"public_key"`
+	CheckedAt    time.Time   `json:"checked_at"`
+}
+
+// TokenInfo is a verification token with the URL its QR code encodes.
+type TokenInfo struct {
+	VerificationToken
+	URL string `json:"url"`
+}
+
+// PublicVerification is what an unauthenticated holder of a verification token sees. It
+// carries no customer contact details or form contents.
+type PublicVerification struct {
+	DispatchNo   string     `json:"dispatch_no"`
+	Device       string     `json:"device"`
+	SerialNumber string     `json:"serial_number"`
+	Hospital     string     `json:"hospital"`
+	CompletedOn  string     `json:"completed_on"`
+	Technician   string     `json:"technician"`
+	SealStatus   string     `json:"seal_status"`
+	SealedAt     *time.Time `json:"sealed_at"`
+	CheckedAt    time.Time  `json:"checked_at"`
+}
@@ -0,0 +1,138 @@
+package seal
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes seal verification and verification tokens.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type idURI struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+// Verify reports whether a report still matches the seal made when it was finalized.
+func (h *Handler) Verify(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	v, err := h.svc.Verify(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, v)
+}
+
+// Tokens lists the verification tokens of a report, revoked ones included.
+func (h *Handler) Tokens(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	tokens, err := h.svc.Tokens(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, tokens)
+}
+
+// IssueToken replaces the verification token of a finalized report.
+func (h *Handler) IssueToken(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	token, err := h.svc.IssueToken(c.Request.Context(), uri.ID, c.GetUint64("userID"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, token)
+}
+
+// RevokeTokens revokes the verification tokens of a report.
+func (h *Handler) RevokeTokens(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	revoked, err := h.svc.RevokeTokens(c.Request.Context(), uri.ID, c.GetUint64("userID"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"revoked": revoked})
+}
+
+// Public returns the verification summary behind the QR code printed on a report as JSON.
+func (h *Handler) Public(c *gin.Context) {
+	var uri struct {
+		Token string `uri:"token" binding:"required,max=64"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.NotFound(c, ErrTokenNotFound.Error())
+		return
+	}
+	summary, err := h.svc.Public(c.Request.Context(), uri.Token)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	response.OK(c, summary)
+}
+
+// PublicPage is the HTML page the QR code printed on a report opens. It shows the same
+// summary as Public.
+func (h *Handler) PublicPage(c *gin.Context) {
+	var uri struct {
+		Token string `uri:"token" binding:"required,max=64"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		renderPage(c, http.StatusNotFound, nil)
+		return
+	}
+	summary, err := h.svc.Public(c.Request.Context(), uri.Token)
+	switch {
+	case errors.Is(err, ErrTokenNotFound):
+		renderPage(c, http.StatusNotFound, nil)
+	case err != nil:
+		log.Printf("seal: public page: %v", err)
+		c.String(http.StatusInternalServerError, "verification is unavailable, please try again later")
+	default:
+		renderPage(c, http.StatusOK, summary)
+	}
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, report.ErrReportNotFound):
+		response.NotFound(c, "report not found")
+	case errors.Is(err, ErrTokenNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, ErrNotFinalized):
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,33 @@
+package seal
+
+import "time"
+
+// ReportSeal is the signed hash of a report as it stood when it was finalized. A report
+// that is reopened and finalized again gets a new seal; the latest one is verified.
+// Hash covers the whole canonical document and is what Signature signs. The part hashes
+// are kept so that a failed verification can say what changed.
+type ReportSeal struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ReportID    uint64    `gorm:"index" json:"report_id"`
+	Version     int       `json:"version"`
+	Hash        string    `gorm:"size:64" json:"hash"`
+	FieldsHash  string    `gorm:"size:64" json:"fields_hash"`
+	PayloadHash string    `gorm:"size:64" json:"payload_hash"`
+	FilesHash   string    `gorm:"size:64" json:"files_hash"`
+	Signature   string    `gorm:"size:100" json:"signature"`
+	KeyID       string    `gorm:"size:16" json:"key_id"`
+	SealedBy    uint64    `json:"sealed_by"`
+	SealedAt    time.Time `json:"sealed_at"`
+}
+
+// VerificationToken is the capability printed as a QR code on a report. Anyone holding it
+// can see the public summary of the report until it is revoked.
+type VerificationToken struct {
+	ID        uint64     `gorm:"primaryKey" json:"id"`
+	ReportID  uint64     `gorm:"index" json:"report_id"`
+	Token     string     `gorm:"size:64;uniqueIndex" json:"token"`
+	CreatedBy uint64     `json:"created_by"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	RevokedBy *uint64    `json:"revoked_by"`
+}
@@ -0,0 +1,88 @@
+package seal
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageStatus words a seal status for the public page. Tone picks its colour.
+type pageStatus struct {
+	Title, Detail, Tone string
+}
+
+var pageStatuses = map[string]pageStatus{
+	StatusValid:            {"Authentic", "This report matches the copy sealed when it was approved.", "ok"},
+	StatusModified:         {"Modified", "This report was changed after it was sealed. Ask the service office for the current copy.", "bad"},
+	StatusSignatureInvalid: {"Not verifiable", "The seal of this report is damaged. Ask the service office for the current copy.", "bad"},
+	StatusUnknownKey:       {"Not verifiable", "This report was sealed with a key the server no longer holds.", "bad"},
+	StatusUnsealed:         {"Not sealed", "This report has no seal yet.", "warn"},
+}
+
+var publicPage = template.Must(template.New("verify").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Service Report Verification</title>
+<style>
+body{margin:0;background:#f4f6fb;color:#141b2d;font:15px/1.5 -apple-system,"Segoe UI",Helvetica,Arial,sans-serif}
+main{max-width:480px;margin:0 auto;padding:24px 16px}
+.card{background:#fff;border:1px solid #cfd4e4;border-radius:8px;padding:20px}
+.label{color:#7b8097;font-size:12px;font-weight:600;letter-spacing:.04em;text-transform:uppercase}
+.status{margin:4px 0 8px;font-size:24px;font-weight:700}
+.ok{color:#15803d}.bad{color:#dc2626}.warn{color:#b45309}
+dl{display:grid;grid-template-columns:max-content 1fr;gap:6px 16px;margin:16px 0 0}
+dt{color:#7b8097}dd{margin:0}
+footer{margin-top:12px;color:#7b8097;font-size:12px}
+</style>
+</head>
+<body>
+<main>
+<div class="card">
+<div class="label">Service Report Verification</div>
+{{with .Summary}}
+<div class="status {{$.Status.Tone}}">{{$.Status.Title}}</div>
+<div>{{$.Status.Detail}}</div>
+<dl>
+<dt>Dispatch No</dt><dd>{{.DispatchNo}}</dd>
+<dt>Hospital</dt><dd>{{.Hospital}}</dd>
+<dt>Device</dt><dd>{{.Device}}</dd>
+<dt>Serial Number</dt><dd>{{.SerialNumber}}</dd>
+<dt>Completed</dt><dd>{{.CompletedOn}}</dd>
+<dt>Technician</dt><dd>{{.Technician}}</dd>
+{{with .SealedAt}}<dt>Sealed</dt><dd>{{.Format "2006-01-02 15:04"}}</dd>{{end}}
+</dl>
+{{else}}
+<div class="status bad">Not found</div>
+<div>This verification code is unknown or has been withdrawn. Ask the service office for the current copy of the report.</div>
+{{end}}
+</div>
+{{with .Summary}}<footer>Checked {{.CheckedAt.Format "2006-01-02 15:04 MST"}}</footer>{{end}}
+</main>
+</body>
+</html>
+`))
+
+type pageData struct {
+	Summary *PublicVerification
+	Status  pageStatus
+}
+
+// renderPage writes the public verification page; a nil summary shows the not found page.
+func renderPage(c *gin.Context, code int, summary *PublicVerification) {
+	data := pageData{Summary: summary}
+	if summary != nil {
+		data.Status = pageStatuses[summary.SealStatus]
+	}
+	var buf bytes.Buffer
+	if err := publicPage.Execute(&buf, data); err != nil {
+		c.String(http.StatusInternalServerError, "verification page unavailable")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(code, "text/html; charset=utf-8", buf.Bytes())
+}
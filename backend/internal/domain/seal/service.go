@@ -0,0 +1,390 @@
+// Package seal signs finalized service reports so later changes can be detected.
+package seal
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+// sealVersion numbers the layout of the canonical document. A change to what is hashed
+// needs a new version so that older seals are still checked against their own layout.
+const sealVersion = 1
+
+// Seal states reported by Verify.
+const (
+	StatusUnsealed         = "unsealed"
+	StatusValid            = "valid"
+	StatusModified         = "modified"
+	StatusSignatureInvalid = "signature_invalid"
+	StatusUnknownKey       = "unknown_key"
+)
+
+// Parts of the canonical document named in Verification.Changed.
+const (
+	PartFields  = "fields"
+	PartPayload = "payload"
+	PartFiles   = "files"
+)
+
+// Service seals reports when they are finalized and verifies them later.
+type Service struct {
+	db        *gorm.DB
+	uploadDir string
+	key       ed25519.PrivateKey
+	keyID     string
+	verifyURL string
+	checks    publicChecks
+	now       func() time.Time
+}
+
+func NewService(db *gorm.DB, uploadDir string, key ed25519.PrivateKey) *Service {
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	return &Service{
+		db:        db,
+		uploadDir: uploadDir,
+		key:       key,
+		keyID:     keyID(key.Public().(ed25519.PublicKey)),
+		now:       time.Now,
+	}
+}
+
+// sealedFields are the report columns covered by a seal. Times are written as the wall
+// clock stored in their DATETIME column, so the hash does not depend on the loc setting
+// of the database connection.
+type sealedFields struct {
+	ID                uint64  `json:"id"`
+	DispatchNo        string  `json:"dispatch_no"`
+	Status            string  `json:"status"`
+	AdminID           uint64  `json:"admin_id"`
+	TeknisiID         *uint64 `json:"teknisi_id"`
+	CustomerID        *uint64 `json:"customer_id"`
+	ContactID         *uint64 `json:"contact_id"`
+	PartnerLocationID *uint64 `json:"partner_location_id"`
+	CustomerName      string  `json:"customer_name"`
+	CustomerAddress   string  `json:"customer_address"`
+	CustomerContact   string  `json:"customer_contact"`
+	DeviceID          *uint64 `json:"device_id"`
+	DeviceName        string  `json:"device_name"`
+	SerialNumber      string  `json:"serial_number"`
+	DeviceLocation    string  `json:"device_location"`
+	Complaint         string  `json:"complaint"`
+	Priority          string  `json:"priority"`
+	ActionTaken       string  `json:"action_taken"`
+	ReviewNote        string  `json:"review_note"`
+	SchemaVersion     int     `json:"schema_version"`
+	OpenedAt          string  `json:"opened_at"`
+	CompletedAt       string  `json:"completed_at"`
+	ApprovedBy        *uint64 `json:"approved_by"`
+	ApprovedAt        string  `json:"approved_at"`
+}
+
+// sealedPayload holds both forms re-encoded with sorted keys, so storage that reorders
+// JSON objects does not change the hash.
+type sealedPayload struct {
+	Form    json.RawMessage `json:"form"`
+	Teknisi json.RawMessage `json:"teknisi"`
+}
+
+// sealedFile is an attachment or payload image. Missing files are hashed as missing, so
+// deleting one breaks the seal as well.
+type sealedFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+type sealedDocument struct {
+	Version int           `json:"version"`
+	Fields  sealedFields  `json:"fields"`
+	Payload sealedPayload `json:"payload"`
+	Files   []sealedFile  `json:"files"`
+}
+
+// digest holds the hash of the canonical document and of each of its parts.
+type digest struct {
+	hash, fields, payload, files string
+}
+
+// ReportFinalized implements report.FinalizeHook. It seals the report in the transaction
+// that finalizes it, so a report is never done without a seal, and gives it a
+// verification token for the printed QR code.
+func (s *Service) ReportFinalized(tx *gorm.DB, r *report.ServiceReport, actorID uint64) error {
+	_, d, err := s.digest(tx, r.ID)
+	if err != nil {
+		return err
+	}
+	sum, err := hex.DecodeString(d.hash)
+	if err != nil {
+		return err
+	}
+	seal := &ReportSeal{
+		ReportID:    r.ID,
+		Version:     sealVersion,
+		Hash:        d.hash,
+		FieldsHash:  d.fields,
+		PayloadHash: d.payload,
+		FilesHash:   d.files,
+		Signature:   base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, sum)),
+		KeyID:       s.keyID,
+		SealedBy:    actorID,
+		SealedAt:    s.now(),
+	}
+	if err := tx.Create(seal).Error; err != nil {
+		return err
+	}
+	return s.ensureToken(tx, r.ID, actorID)
+}
+
+// Latest returns the newest seal of a report, or nil when it was never sealed.
+func (s *Service) Latest(ctx context.Context, reportID uint64) (*ReportSeal, error) {
+	var seal ReportSeal
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("id DESC").First(&seal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &seal, nil
+}
+
+// Verify recomputes the hash of the report as it is now and checks it, and the signature,
+// against the latest seal.
+func (s *Service) Verify(ctx context.Context, reportID uint64) (*Verification, error) {
+	r, d, err := s.digest(s.db.WithContext(ctx), reportID)
+	if err != nil {
+		return nil, err
+	}
+	seal, err := s.Latest(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	v := &Verification{
+		ReportID:     r.ID,
+		DispatchNo:   r.DispatchNo,
+		ReportStatus: r.Status,
+		CurrentHash:  d.hash,
+		Seal:         seal,
+		PublicKey:    base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey)),
+		CheckedAt:    s.now(),
+	}
+	v.Status, v.Changed = s.check(seal, d)
+	return v, nil
+}
+
+func (s *Service) check(seal *ReportSeal, d digest) (string, []string) {
+	if seal == nil {
+		return StatusUnsealed, nil
+	}
+	if seal.KeyID != s.keyID {
+		return StatusUnknownKey, nil
+	}
+	sum, err := hex.DecodeString(seal.Hash)
+	if err != nil {
+		return StatusSignatureInvalid, nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(seal.Signature)
+	if err != nil || !ed25519.Verify(s.key.Public().(ed25519.PublicKey), sum, sig) {
+		return StatusSignatureInvalid, nil
+	}
+	if seal.Hash == d.hash {
+		return StatusValid, nil
+	}
+	var changed []string
+	for _, part := range []struct{ name, sealed, current string }{
+		{PartFields, seal.FieldsHash, d.fields},
+		{PartPayload, seal.PayloadHash, d.payload},
+		{PartFiles, seal.FilesHash, d.files},
+	} {
+		if part.sealed != part.current {
+			changed = append(changed, part.name)
+		}
+	}
+	return StatusModified, changed
+}
+
+// digest loads the report through db, which may be the finalizing transaction, and hashes
+// its canonical document.
+func (s *Service) digest(db *gorm.DB, reportID uint64) (*report.ServiceReport, digest, error) {
+	var r report.ServiceReport
+	if err := db.Preload("Attachments").First(&r, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, digest{}, report.ErrReportNotFound
+		}
+		return nil, digest{}, err
+	}
+	doc := sealedDocument{Version: sealVersion, Fields: fieldsOf(&r)}
+	var err error
+	if doc.Payload.Form, err = canonicalJSON(r.FormPayload); err != nil {
+		return nil, digest{}, fmt.Errorf("form payload: %w", err)
+	}
+	if doc.Payload.Teknisi, err = canonicalJSON(r.TeknisiPayload); err != nil {
+		return nil, digest{}, fmt.Errorf("teknisi payload: %w", err)
+	}
+	if doc.Files, err = s.filesOf(&r); err != nil {
+		return nil, digest{}, err
+	}
+
+	var d digest
+	for _, part := range []struct {
+		out *string
+		v   interface{}
+	}{
+		{&d.hash, doc},
+		{&d.fields, doc.Fields},
+		{&d.payload, doc.Payload},
+		{&d.files, doc.Files},
+	} {
+		if *part.out, err = hashJSON(part.v); err != nil {
+			return nil, digest{}, err
+		}
+	}
+	return &r, d, nil
+}
+
+func fieldsOf(r *report.ServiceReport) sealedFields {
+	return sealedFields{
+		ID:                r.ID,
+		DispatchNo:        r.DispatchNo,
+		Status:            r.Status,
+		AdminID:           r.AdminID,
+		TeknisiID:         r.TeknisiID,
+		CustomerID:        r.CustomerID,
+		ContactID:         r.ContactID,
+		PartnerLocationID: r.PartnerLocationID,
+		CustomerName:      r.CustomerName,
+		CustomerAddress:   r.CustomerAddress,
+		CustomerContact:   r.CustomerContact,
+		DeviceID:          r.DeviceID,
+		DeviceName:        r.DeviceName,
+		SerialNumber:      r.SerialNumber,
+		DeviceLocation:    r.DeviceLocation,
+		Complaint:         r.Complaint,
+		Priority:          r.Priority,
+		ActionTaken:       r.ActionTaken,
+		ReviewNote:        r.ReviewNote,
+		SchemaVersion:     r.SchemaVersion,
+		OpenedAt:          canonicalTime(&r.OpenedAt),
+		CompletedAt:       canonicalTime(r.CompletedAt),
+		ApprovedBy:        r.ApprovedBy,
+		ApprovedAt:        canonicalTime(r.ApprovedAt),
+	}
+}
+
+// canonicalTimeLayout prints a DATETIME value without a zone. DATETIME columns carry no
+// zone, and the driver labels what it reads with the connection's loc, so converting to
+// UTC would shift the hash whenever loc changes.
+const canonicalTimeLayout = "2006-01-02 15:04:05.000000"
+
+func canonicalTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(canonicalTimeLayout)
+}
+
+// canonicalJSON re-encodes a JSON document with sorted object keys and numbers kept as
+// written.
+func canonicalJSON(raw []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(out), nil
+}
+
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// filesOf hashes the attachments in ID order followed by the payload images stored under
+// uploads/images/<id>/ in path order.
+func (s *Service) filesOf(r *report.ServiceReport) ([]sealedFile, error) {
+	attachments := append([]report.ReportAttachment(nil), r.Attachments...)
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+	files := []sealedFile{}
+	for _, att := range attachments {
+		file, err := hashFile(fmt.Sprintf("attachments/%d/%s", att.ID, att.FileName), att.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	root := filepath.Join(s.uploadDir, "images", strconv.FormatUint(r.ID, 10))
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		file, err := hashFile("images/"+filepath.ToSlash(rel), path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+func hashFile(name, path string) (sealedFile, error) {
+	file := sealedFile{Path: name}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			file.Missing = true
+			return file, nil
+		}
+		return file, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if file.Size, err = io.Copy(hash, f); err != nil {
+		return file, err
+	}
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
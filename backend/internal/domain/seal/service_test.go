@@ -0,0 +1,107 @@
+package seal
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{``, `null`},
+		{"  \n", `null`},
+		{`null`, `null`},
+		{`{"b":1,"a":{"d":[3,2],"c":"x"}}`, `{"a":{"c":"x","d":[3,2]},"b":1}`},
+		{"{\n  \"a\" : 1.50,\n  \"b\": 12345678901234567890\n}", `{"a":1.50,"b":12345678901234567890}`},
+		{`{"html":"<b>&</b>"}`, `{"html":"\u003cb\u003e\u0026\u003c/b\u003e"}`},
+	}
+	for _, tt := range tests {
+		got, err := canonicalJSON([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("canonicalJSON(%q): %v", tt.in, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("canonicalJSON(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if _, err := canonicalJSON([]byte(`{"a":`)); err == nil {
+		t.Error("truncated JSON was accepted")
+	}
+}
+
+func TestCanonicalTime(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	local := time.Date(2026, 3, 14, 9, 30, 0, 250000000, wib)
+	utc := time.Date(2026, 3, 14, 9, 30, 0, 250000000, time.UTC)
+	if canonicalTime(&local) != canonicalTime(&utc) {
+		t.Errorf("same wall clock hashed as %q and %q", canonicalTime(&local), canonicalTime(&utc))
+	}
+	if got, want := canonicalTime(&local), "2026-03-14 09:30:00.250000"; got != want {
+		t.Errorf("canonicalTime = %q, want %q", got, want)
+	}
+	if got := canonicalTime(nil); got != "" {
+		t.Errorf("canonicalTime(nil) = %q", got)
+	}
+	if got := canonicalTime(&time.Time{}); got != "" {
+		t.Errorf("canonicalTime(zero) = %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(nil, "", key)
+	sealed := digest{hash: hexHash("doc"), fields: hexHash("fields"), payload: hexHash("payload"), files: hexHash("files")}
+	sign := func(d digest) *ReportSeal {
+		sum, _ := hex.DecodeString(d.hash)
+		return &ReportSeal{
+			Hash:        d.hash,
+			FieldsHash:  d.fields,
+			PayloadHash: d.payload,
+			FilesHash:   d.files,
+			Signature:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, sum)),
+			KeyID:       s.keyID,
+		}
+	}
+	edited := sealed
+	edited.hash, edited.payload, edited.files = hexHash("doc2"), hexHash("payload2"), hexHash("files2")
+	forged := sign(sealed)
+	forged.Hash = edited.hash
+	otherKey := sign(sealed)
+	otherKey.KeyID = "other"
+	badSignature := sign(sealed)
+	badSignature.Signature = "not base64!"
+
+	tests := []struct {
+		name    string
+		seal    *ReportSeal
+		current digest
+		status  string
+		changed []string
+	}{
+		{"unsealed", nil, sealed, StatusUnsealed, nil},
+		{"unchanged", sign(sealed), sealed, StatusValid, nil},
+		{"edited", sign(sealed), edited, StatusModified, []string{PartPayload, PartFiles}},
+		{"hash replaced", forged, edited, StatusSignatureInvalid, nil},
+		{"signature garbled", badSignature, sealed, StatusSignatureInvalid, nil},
+		{"other key", otherKey, sealed, StatusUnknownKey, nil},
+	}
+	for _, tt := range tests {
+		status, changed := s.check(tt.seal, tt.current)
+		if status != tt.status || !reflect.DeepEqual(changed, tt.changed) {
+			t.Errorf("%s: check = %s %v, want %s %v", tt.name, status, changed, tt.status, tt.changed)
+		}
+	}
+}
+
+func hexHash(s string) string {
+	sum, _ := hashJSON(s)
+	return sum
+}
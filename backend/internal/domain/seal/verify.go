@@ -0,0 +1,256 @@
+package seal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+// tokenBytes is the entropy of a verification token; 32 random bytes cannot be guessed.
+const tokenBytes = 32
+
+// publicCheckTTL is how long the public page reuses a seal check. Verifying hashes every
+// file of the report, which anyone holding a printed QR code could otherwise trigger on
+// every request.
+const publicCheckTTL = 10 * time.Minute
+
+var (
+	ErrTokenNotFound = errors.New("verification token not found")
+	ErrNotFinalized  = errors.New("report is not finalized")
+)
+
+// UseVerifyURL sets the base URL that tokens are appended to in printed QR codes, e.g.
+// https://service.example.com/verify.
+func (s *Service) UseVerifyURL(base string) {
+	s.verifyURL = strings.TrimRight(base, "/")
+}
+
+func (s *Service) tokenURL(token string) string {
+	return s.verifyURL + "/" + token
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *Service) issueToken(tx *gorm.DB, reportID, actorID uint64) (*VerificationToken, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	t := &VerificationToken{ReportID: reportID, Token: token, CreatedBy: actorID}
+	if err := tx.Create(t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func activeTokens(db *gorm.DB, reportID uint64) *gorm.DB {
+	return db.Model(&VerificationToken{}).Where("report_id = ? AND revoked_at IS NULL", reportID)
+}
+
+// ensureToken gives a freshly sealed report a verification token unless it still has one
+// from an earlier finalization, so reprinting after a reopen keeps the same QR code.
+func (s *Service) ensureToken(tx *gorm.DB, reportID, actorID uint64) error {
+	var count int64
+	if err := activeTokens(tx, reportID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := s.issueToken(tx, reportID, actorID)
+	return err
+}
+
+// Tokens lists every verification token of a report, newest first.
+func (s *Service) Tokens(ctx context.Context, reportID uint64) ([]TokenInfo, error) {
+	var tokens []VerificationToken
+	if err := s.db.WithContext(ctx).Where("report_id = ?", reportID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	out := make([]TokenInfo, len(tokens))
+	for i, t := range tokens {
+		out[i] = TokenInfo{VerificationToken: t, URL: s.tokenURL(t.Token)}
+	}
+	return out, nil
+}
+
+// IssueToken revokes the active tokens of a finalized report and issues a new one. Paper
+// copies printed with the old QR code stop verifying.
+func (s *Service) IssueToken(ctx context.Context, reportID, actorID uint64) (*TokenInfo, error) {
+	var issued *VerificationToken
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var r report.ServiceReport
+		if err := tx.Select("id", "status").First(&r, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return report.ErrReportNotFound
+			}
+			return err
+		}
+		if r.Status != report.StatusDone {
+			return ErrNotFinalized
+		}
+		if _, err := s.revoke(tx, reportID, actorID); err != nil {
+			return err
+		}
+		var err error
+		issued, err = s.issueToken(tx, reportID, actorID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TokenInfo{VerificationToken: *issued, URL: s.tokenURL(issued.Token)}, nil
+}
+
+// RevokeTokens revokes every active token of a report and returns how many there were.
+func (s *Service) RevokeTokens(ctx context.Context, reportID, actorID uint64) (int64, error) {
+	return s.revoke(s.db.WithContext(ctx), reportID, actorID)
+}
+
+func (s *Service) revoke(db *gorm.DB, reportID, actorID uint64) (int64, error) {
+	result := activeTokens(db, reportID).Updates(map[string]interface{}{"revoked_at": s.now(), "revoked_by": actorID})
+	return result.RowsAffected, result.Error
+}
+
+// VerifyURL returns the URL of the active verification token of a report, or "" when it
+// has none. The PDF renderer prints it as a QR code.
+func (s *Service) VerifyURL(reportID uint64) (string, error) {
+	var t VerificationToken
+	err := activeTokens(s.db, reportID).Order("id DESC").First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.tokenURL(t.Token), nil
+}
+
+// publicCheck is a cached seal check of one report.
+type publicCheck struct {
+	sealID    uint64
+	status    string
+	sealedAt  *time.Time
+	checkedAt time.Time
+}
+
+// publicChecks caches seal checks for the public page by report.
+type publicChecks struct {
+	mu       sync.Mutex
+	byReport map[uint64]publicCheck
+}
+
+func (p *publicChecks) get(reportID, sealID uint64, now time.Time) (publicCheck, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.byReport[reportID]
+	if !ok || c.sealID != sealID || now.Sub(c.checkedAt) >= publicCheckTTL {
+		return publicCheck{}, false
+	}
+	return c, true
+}
+
+func (p *publicChecks) put(reportID uint64, c publicCheck) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.byReport == nil {
+		p.byReport = map[uint64]publicCheck{}
+	}
+	for id, old := range p.byReport {
+		if c.checkedAt.Sub(old.checkedAt) >= publicCheckTTL {
+			delete(p.byReport, id)
+		}
+	}
+	p.byReport[reportID] = c
+}
+
+// publicCheck verifies a report for the public page. A check of the same seal made within
+// publicCheckTTL is reused.
+func (s *Service) publicCheck(ctx context.Context, reportID uint64) (publicCheck, error) {
+	seal, err := s.Latest(ctx, reportID)
+	if err != nil {
+		return publicCheck{}, err
+	}
+	var sealID uint64
+	if seal != nil {
+		sealID = seal.ID
+	}
+	if c, ok := s.checks.get(reportID, sealID, s.now()); ok {
+		return c, nil
+	}
+	v, err := s.Verify(ctx, reportID)
+	if err != nil {
+		return publicCheck{}, err
+	}
+	c := publicCheck{status: v.Status, checkedAt: v.CheckedAt}
+	if v.Seal != nil {
+		c.sealID, c.sealedAt = v.Seal.ID, &v.Seal.SealedAt
+	}
+	s.checks.put(reportID, c)
+	return c, nil
+}
+
+// Public returns the summary shown to anyone holding an active token. Unknown and revoked
+// tokens are both reported as not found.
+func (s *Service) Public(ctx context.Context, token string) (*PublicVerification, error) {
+	db := s.db.WithContext(ctx)
+	var t VerificationToken
+	if err := db.Where("token = ? AND revoked_at IS NULL", token).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	check, err := s.publicCheck(ctx, t.ReportID)
+	if err != nil {
+		if errors.Is(err, report.ErrReportNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	var r report.ServiceReport
+	if err := db.First(&r, t.ReportID).Error; err != nil {
+		return nil, err
+	}
+	out := &PublicVerification{
+		DispatchNo:   r.DispatchNo,
+		Device:       r.DeviceName,
+		SerialNumber: r.SerialNumber,
+		Hospital:     r.CustomerName,
+		SealStatus:   check.status,
+		SealedAt:     check.sealedAt,
+		CheckedAt:    check.checkedAt,
+	}
+	if r.CompletedAt != nil {
+		out.CompletedOn = r.CompletedAt.Format("2006-01-02")
+	}
+	if r.PartnerLocationID != nil {
+		var hospital string
+		if err := db.Table("partner_locations").Select("hospital_name").Where("id = ?", *r.PartnerLocationID).Scan(&hospital).Error; err != nil {
+			return nil, err
+		}
+		if hospital != "" {
+			out.Hospital = hospital
+		}
+	}
+	if r.TeknisiID != nil {
+		if err := db.Table("users").Select("full_name").Where("id = ?", *r.TeknisiID).Scan(&out.Technician).Error; err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package seal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublicChecks(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local)
+	var checks publicChecks
+	if _, ok := checks.get(1, 10, now); ok {
+		t.Fatal("empty cache returned a check")
+	}
+	checks.put(1, publicCheck{sealID: 10, status: StatusValid, checkedAt: now})
+	if c, ok := checks.get(1, 10, now.Add(publicCheckTTL-time.Second)); !ok || c.status != StatusValid {
+		t.Errorf("fresh check not reused: %+v %v", c, ok)
+	}
+	if _, ok := checks.get(1, 11, now); ok {
+		t.Error("check reused for a newer seal")
+	}
+	if _, ok := checks.get(1, 10, now.Add(publicCheckTTL)); ok {
+		t.Error("expired check reused")
+	}
+	checks.put(2, publicCheck{sealID: 20, status: StatusModified, checkedAt: now.Add(publicCheckTTL)})
+	if _, ok := checks.byReport[1]; ok {
+		t.Error("expired check kept")
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sealed := time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		code    int
+		summary *PublicVerification
+		want    []string
+	}{
+		{"valid", http.StatusOK, &PublicVerification{DispatchNo: "SR/2026/03/00042", Hospital: "RS <A>", SealStatus: StatusValid, SealedAt: &sealed}, []string{"Authentic", "SR/2026/03/00042", "RS &lt;A&gt;", "2026-03-14 09:00"}},
+		{"modified", http.StatusOK, &PublicVerification{SealStatus: StatusModified}, []string{"Modified"}},
+		{"not found", http.StatusNotFound, nil, []string{"Not found"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		renderPage(c, tt.code, tt.summary)
+		if w.Code != tt.code || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("%s: %d %s", tt.name, w.Code, w.Header().Get("Content-Type"))
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(w.Body.String(), s) {
+				t.Errorf("%s: page does not contain %q", tt.name, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sla
+
+import "time"
+
+// SLA clocks.
+const (
+	ClockBusiness = "business"
+	Clock24x7     = "24x7"
+)
+
+// SLA target names.
+const (
+	TargetAssign  = "assign"
+	TargetArrive  = "arrive"
+	TargetResolve = "resolve"
+)
+
+// SLA target and report states, from best to worst.
+const (
+	StateNone     = "none"
+	StateMet      = "met"
+	StatePending  = "pending"
+	StateAtRisk   = "at_risk"
+	StateBreached = "breached"
+)
+
+// PolicyRequest creates or replaces an SLA policy.
+type PolicyRequest struct {
+	Name              string  `json:"name" binding:"required"`
+	CustomerID        *uint64 `json:"customer_id"`
+	PartnerLocationID *uint64 `json:"partner_location_id"`
+	Priority          string  `json:"priority" binding:"omitempty,oneof=low normal high critical"`
+	AssignMinutes     int     `json:"assign_minutes" binding:"min=0"`
+	ArriveMinutes     int     `json:"arrive_minutes" binding:"min=0"`
+	ResolveMinutes    int     `json:"resolve_minutes" binding:"min=0"`
+	AtRiskPercent     int     `json:"at_risk_percent" binding:"omitempty,min=1,max=99"`
+	Clock             string  `json:"clock" binding:"omitempty,oneof=business 24x7"`
+	Active            *bool   `json:"active"`
+}
+
+// TargetState is the progress of one SLA target.
+type TargetState struct {
+	Target           string     `json:"target"`
+	State            string     `json:"state"`
+	DueAt            *time.Time `json:"due_at"`
+	DoneAt           *time.Time `json:"done_at"`
+	RemainingMinutes *int64     `json:"remaining_minutes,omitempty"`
+}
+
+// ReportState is the SLA state of a report. State is the worst state of its targets.
+type ReportState struct {
+	ReportID   uint64        `json:"report_id"`
+	DispatchNo string        `json:"dispatch_no"`
+	Status     string        `json:"status"`
+	Priority   string        `json:"priority"`
+	PolicyID   *uint64       `json:"policy_id"`
+	PolicyName string        `json:"policy_name,omitempty"`
+	Clock      string        `json:"clock,omitempty"`
+	TeknisiID  *uint64       `json:"teknisi_id"`
+	State      string        `json:"state"`
+	Targets    []TargetState `json:"targets"`
+}
+
+// AtRiskQuery filters the at-risk list. State narrows it to at_risk or breached jobs.
+type AtRiskQuery struct {
+	State     string `form:"state" binding:"omitempty,oneof=at_risk breached"`
+	PartnerID uint64 `form:"partner_id"`
+	TeknisiID uint64 `form:"teknisi_id"`
+}
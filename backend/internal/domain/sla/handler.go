@@ -0,0 +1,120 @@
+package sla
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/pkg/response"
+)
+
+// Handler exposes SLA policy and state endpoints.
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type idURI struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+func (h *Handler) ListPolicies(c *gin.Context) {
+	policies, err := h.svc.ListPolicies(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, policies)
+}
+
+func (h *Handler) CreatePolicy(c *gin.Context) {
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	policy, err := h.svc.CreatePolicy(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.Created(c, policy)
+}
+
+func (h *Handler) UpdatePolicy(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	var req PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	policy, err := h.svc.UpdatePolicy(c.Request.Context(), uri.ID, req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, policy)
+}
+
+func (h *Handler) DeletePolicy(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	if err := h.svc.DeletePolicy(c.Request.Context(), uri.ID); err != nil {
+		writeError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Report returns the SLA state of one report.
+func (h *Handler) Report(c *gin.Context) {
+	var uri idURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	state, err := h.svc.ForReport(c.Request.Context(), uri.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, state)
+}
+
+func (h *Handler) AtRisk(c *gin.Context) {
+	var q AtRiskQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, err)
+		return
+	}
+	states, err := h.svc.AtRisk(c.Request.Context(), q)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.OK(c, states)
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrPolicyNotFound):
+		response.NotFound(c, "sla policy not found")
+	case errors.Is(err, report.ErrReportNotFound):
+		response.NotFound(c, "report not found")
+	case errors.Is(err, ErrPolicyEmpty):
+		response.UnprocessableEntity(c, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
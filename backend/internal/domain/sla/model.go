@@ -0,0 +1,45 @@
+package sla
+
+import "time"
+
+// SLAPolicy holds the target times promised for reports of one scope. A policy applies to
+// a customer, whose contract it encodes, to a partner site or, with neither set, to every
+// report; Priority left empty matches every priority. Targets are minutes from report
+// creation; zero means the policy sets no target for that stage. With the business clock
+// only working hours of the partner's region count towards a target; 24x7 counts
+// wall-clock time.
+type SLAPolicy struct {
+	ID                uint64    `gorm:"primaryKey" json:"id"`
+	Name              string    `gorm:"size:120" json:"name"`
+	CustomerID        *uint64   `gorm:"index" json:"customer_id"`
+	PartnerLocationID *uint64   `gorm:"index" json:"partner_location_id"`
+	Priority          string    `gorm:"size:16" json:"priority"`
+	AssignMinutes     int       `json:"assign_minutes"`
+	ArriveMinutes     int       `json:"arrive_minutes"`
+	ResolveMinutes    int       `json:"resolve_minutes"`
+	AtRiskPercent     int       `json:"at_risk_percent"`
+	Clock             string    `gorm:"size:16;default:'business'" json:"clock"`
+	Active            bool      `gorm:"index" json:"active"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ReportSLA is the SLA state of one report. Due times are fixed when the report is first
+// checked, so later policy edits do not move the goalposts of running jobs.
+type ReportSLA struct {
+	ReportID          uint64     `gorm:"primaryKey;autoIncrement:false" json:"report_id"`
+	PolicyID          *uint64    `gorm:"index" json:"policy_id"`
+	AtRiskPercent     int        `json:"at_risk_percent"`
+	Clock             string     `gorm:"size:16" json:"clock"`
+	AssignDueAt       *time.Time `json:"assign_due_at"`
+	ArriveDueAt       *time.Time `json:"arrive_due_at"`
+	ResolveDueAt      *time.Time `json:"resolve_due_at"`
+	AssignedAt        *time.Time `json:"assigned_at"`
+	ArrivedAt         *time.Time `json:"arrived_at"`
+	ResolvedAt        *time.Time `json:"resolved_at"`
+	AssignBreachedAt  *time.Time `json:"assign_breached_at"`
+	ArriveBreachedAt  *time.Time `json:"arrive_breached_at"`
+	ResolveBreachedAt *time.Time `json:"resolve_breached_at"`
+	Settled           bool       `gorm:"index" json:"settled"`
+	CheckedAt         time.Time  `json:"checked_at"`
+}
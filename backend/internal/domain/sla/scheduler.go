@@ -0,0 +1,20 @@
+package sla
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/company/internal-service-report/pkg/schedule"
+)
+
+// Run checks report SLAs every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	schedule.Every(ctx, interval, func(ctx context.Context) {
+		if n, err := s.Check(ctx); err != nil {
+			log.Printf("sla: check failed: %v", err)
+		} else if n > 0 {
+			log.Printf("sla: flagged %d new breaches", n)
+		}
+	})
+}
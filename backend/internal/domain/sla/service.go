@@ -0,0 +1,469 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/company/internal-service-report/internal/domain/calendar"
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+// DefaultAtRiskPercent is the share of a target window after which a pending target is at risk.
+const DefaultAtRiskPercent = 80
+
+var (
+	ErrPolicyNotFound = errors.New("sla policy not found")
+	ErrPolicyEmpty    = errors.New("sla policy needs at least one target")
+)
+
+// Service manages SLA policies and tracks report SLA state.
+type Service struct {
+	db        *gorm.DB
+	calendars *calendar.Service
+	now       func() time.Time
+}
+
+func NewService(db *gorm.DB, calendars *calendar.Service) *Service {
+	return &Service{db: db, calendars: calendars, now: time.Now}
+}
+
+func (s *Service) ListPolicies(ctx context.Context) ([]SLAPolicy, error) {
+	var policies []SLAPolicy
+	if err := s.db.WithContext(ctx).Order("name ASC, id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (s *Service) GetPolicy(ctx context.Context, id uint64) (*SLAPolicy, error) {
+	var policy SLAPolicy
+	if err := s.db.WithContext(ctx).First(&policy, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (s *Service) CreatePolicy(ctx context.Context, req PolicyRequest) (*SLAPolicy, error) {
+	policy := &SLAPolicy{Active: true}
+	if err := applyRequest(policy, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdatePolicy replaces a policy. Reports already checked keep the due times they were given.
+func (s *Service) UpdatePolicy(ctx context.Context, id uint64, req PolicyRequest) (*SLAPolicy, error) {
+	policy, err := s.GetPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRequest(policy, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *Service) DeletePolicy(ctx context.Context, id uint64) error {
+	result := s.db.WithContext(ctx).Delete(&SLAPolicy{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+func applyRequest(policy *SLAPolicy, req PolicyRequest) error {
+	if req.AssignMinutes == 0 && req.ArriveMinutes == 0 && req.ResolveMinutes == 0 {
+		return ErrPolicyEmpty
+	}
+	policy.Name = strings.TrimSpace(req.Name)
+	policy.CustomerID = req.CustomerID
+	policy.PartnerLocationID = req.PartnerLocationID
+	policy.Priority = req.Priority
+	policy.AssignMinutes = req.AssignMinutes
+	policy.ArriveMinutes = req.ArriveMinutes
+	policy.ResolveMinutes = req.ResolveMinutes
+	policy.AtRiskPercent = req.AtRiskPercent
+	policy.Clock = req.Clock
+	if policy.Clock == "" {
+		policy.Clock = ClockBusiness
+	}
+	if req.Active != nil {
+		policy.Active = *req.Active
+	}
+	return nil
+}
+
+// policyFor picks the most specific active policy for a report: a customer beats a
+// partner site, which beats a global policy; within a scope a matching priority beats a
+// policy for any priority.
+func policyFor(policies []SLAPolicy, r *report.ServiceReport) *SLAPolicy {
+	var best *SLAPolicy
+	bestScore := -1
+	for i := range policies {
+		p := &policies[i]
+		score := 0
+		switch {
+		case p.CustomerID != nil:
+			if r.CustomerID == nil || *r.CustomerID != *p.CustomerID {
+				continue
+			}
+			score += 4
+		case p.PartnerLocationID != nil:
+			if r.PartnerLocationID == nil || *r.PartnerLocationID != *p.PartnerLocationID {
+				continue
+			}
+			score += 2
+		}
+		if p.Priority != "" {
+			if p.Priority != r.Priority {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best
+}
+
+// dueAfter adds a target to start, counting working time only when cal is set.
+func dueAfter(cal *calendar.Calendar, start time.Time, minutes int) *time.Time {
+	if minutes <= 0 {
+		return nil
+	}
+	d := time.Duration(minutes) * time.Minute
+	due := start.Add(d)
+	if cal != nil {
+		due = cal.AddBusiness(start, d)
+	}
+	return &due
+}
+
+// calendarFor returns the business calendar a report's SLA runs on, or nil for wall-clock
+// time.
+func (s *Service) calendarFor(ctx context.Context, clock string, partnerID *uint64) (*calendar.Calendar, error) {
+	if clock != ClockBusiness || s.calendars == nil {
+		return nil, nil
+	}
+	return s.calendars.ForPartner(ctx, partnerID)
+}
+
+// Check refreshes the SLA state of every report that is still running or changed since its
+// last check. A target that passes its due time unmet is flagged once, with a note in the
+// report's status log. It returns the number of new breaches.
+func (s *Service) Check(ctx context.Context) (int, error) {
+	var policies []SLAPolicy
+	if err := s.db.WithContext(ctx).Where("active = ?", true).Find(&policies).Error; err != nil {
+		return 0, err
+	}
+	var reports []report.ServiceReport
+	breaches := 0
+	err := s.db.WithContext(ctx).
+		Select("service_reports.*").
+		Joins("LEFT JOIN report_slas ON report_slas.report_id = service_reports.id").
+		Where("report_slas.report_id IS NULL OR report_slas.settled = ? OR service_reports.updated_at > report_slas.checked_at", false).
+		FindInBatches(&reports, 100, func(_ *gorm.DB, _ int) error {
+			for i := range reports {
+				n, err := s.checkReport(ctx, &reports[i], policies)
+				if err != nil {
+					log.Printf("sla: report %d: %v", reports[i].ID, err)
+					continue
+				}
+				breaches += n
+			}
+			return nil
+		}).Error
+	return breaches, err
+}
+
+func (s *Service) checkReport(ctx context.Context, r *report.ServiceReport, policies []SLAPolicy) (int, error) {
+	now := s.now()
+	breaches := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var state ReportSLA
+		err := tx.Where("report_id = ?", r.ID).First(&state).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			state = ReportSLA{ReportID: r.ID}
+		} else if err != nil {
+			return err
+		}
+		// A report without a policy picks one up when it next changes while still running.
+		if state.PolicyID == nil && r.Status != report.StatusDone && r.Status != report.StatusCancelled {
+			if policy := policyFor(policies, r); policy != nil {
+				cal, err := s.calendarFor(ctx, policy.Clock, r.PartnerLocationID)
+				if err != nil {
+					return err
+				}
+				id := policy.ID
+				state.PolicyID = &id
+				state.AtRiskPercent = policy.AtRiskPercent
+				state.Clock = policy.Clock
+				state.AssignDueAt = dueAfter(cal, r.OpenedAt, policy.AssignMinutes)
+				state.ArriveDueAt = dueAfter(cal, r.OpenedAt, policy.ArriveMinutes)
+				state.ResolveDueAt = dueAfter(cal, r.OpenedAt, policy.ResolveMinutes)
+			}
+		}
+
+		m, err := report.LoadMilestones(tx, r)
+		if err != nil {
+			return err
+		}
+		state.AssignedAt, state.ArrivedAt, state.ResolvedAt = m.AssignedAt, m.ArrivedAt, m.ResolvedAt
+		for _, t := range state.targets() {
+			if *t.breachedAt != nil || t.dueAt == nil || r.Status == report.StatusCancelled {
+				continue
+			}
+			late := (t.doneAt == nil && now.After(*t.dueAt)) || (t.doneAt != nil && t.doneAt.After(*t.dueAt))
+			if !late {
+				continue
+			}
+			at := now
+			*t.breachedAt = &at
+			breaches++
+			note := fmt.Sprintf("SLA breach: time to %s exceeded, due %s", t.name, t.dueAt.Format("2006-01-02 15:04"))
+			if err := tx.Create(&report.StatusLog{ReportID: r.ID, From: r.Status, To: r.Status, Note: note}).Error; err != nil {
+				return err
+			}
+		}
+		state.Settled = r.Status == report.StatusCancelled || state.PolicyID == nil || state.settled()
+		state.CheckedAt = now
+		return tx.Save(&state).Error
+	})
+	return breaches, err
+}
+
+type target struct {
+	name       string
+	dueAt      *time.Time
+	doneAt     *time.Time
+	breachedAt **time.Time
+}
+
+func (r *ReportSLA) targets() []target {
+	return []target{
+		{TargetAssign, r.AssignDueAt, r.AssignedAt, &r.AssignBreachedAt},
+		{TargetArrive, r.ArriveDueAt, r.ArrivedAt, &r.ArriveBreachedAt},
+		{TargetResolve, r.ResolveDueAt, r.ResolvedAt, &r.ResolveBreachedAt},
+	}
+}
+
+func (r *ReportSLA) settled() bool {
+	for _, t := range r.targets() {
+		if t.dueAt != nil && t.doneAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+var stateRank = map[string]int{StateNone: 0, StateMet: 1, StatePending: 2, StateAtRisk: 3, StateBreached: 4}
+
+// evaluate derives the live state of every target at now. With a calendar, the share of a
+// target window used up is measured in working time.
+func evaluate(r *ReportSLA, cal *calendar.Calendar, openedAt, now time.Time) (string, []TargetState) {
+	percent := r.AtRiskPercent
+	if percent <= 0 {
+		percent = DefaultAtRiskPercent
+	}
+	worst := StateNone
+	states := make([]TargetState, 0, 3)
+	for _, t := range r.targets() {
+		ts := TargetState{Target: t.name, DueAt: t.dueAt, DoneAt: t.doneAt}
+		switch {
+		case t.dueAt == nil:
+			ts.State = StateNone
+		case *t.breachedAt != nil:
+			ts.State = StateBreached
+		case t.doneAt != nil:
+			ts.State = StateMet
+		case now.After(*t.dueAt):
+			ts.State = StateBreached
+		default:
+			window, elapsed := t.dueAt.Sub(openedAt), now.Sub(openedAt)
+			if cal != nil {
+				window, elapsed = cal.BusinessDuration(openedAt, *t.dueAt), cal.BusinessDuration(openedAt, now)
+			}
+			ts.State = StatePending
+			if window > 0 && elapsed*100 >= window*time.Duration(percent) {
+				ts.State = StateAtRisk
+			}
+		}
+		if ts.State == StatePending || ts.State == StateAtRisk {
+			remaining := int64(t.dueAt.Sub(now) / time.Minute)
+			ts.RemainingMinutes = &remaining
+		}
+		if stateRank[ts.State] > stateRank[worst] {
+			worst = ts.State
+		}
+		states = append(states, ts)
+	}
+	return worst, states
+}
+
+// ForReport returns the live SLA state of a report, checking it first if it changed since
+// its last check.
+func (s *Service) ForReport(ctx context.Context, reportID uint64) (*ReportState, error) {
+	var r report.ServiceReport
+	if err := s.db.WithContext(ctx).First(&r, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, report.ErrReportNotFound
+		}
+		return nil, err
+	}
+	var state ReportSLA
+	err := s.db.WithContext(ctx).Where("report_id = ?", reportID).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && r.UpdatedAt.After(state.CheckedAt)) {
+		var policies []SLAPolicy
+		if err := s.db.WithContext(ctx).Where("active = ?", true).Find(&policies).Error; err != nil {
+			return nil, err
+		}
+		if _, err := s.checkReport(ctx, &r, policies); err != nil {
+			return nil, err
+		}
+		err = s.db.WithContext(ctx).Where("report_id = ?", reportID).First(&state).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.describe(ctx, &r, &state)
+}
+
+func (s *Service) describe(ctx context.Context, r *report.ServiceReport, state *ReportSLA) (*ReportState, error) {
+	out := &ReportState{
+		ReportID:   r.ID,
+		DispatchNo: r.DispatchNo,
+		Status:     r.Status,
+		Priority:   r.Priority,
+		PolicyID:   state.PolicyID,
+		TeknisiID:  r.TeknisiID,
+		Clock:      state.Clock,
+	}
+	cal, err := s.calendarFor(ctx, state.Clock, r.PartnerLocationID)
+	if err != nil {
+		return nil, err
+	}
+	out.State, out.Targets = evaluate(state, cal, r.OpenedAt, s.now())
+	if state.PolicyID != nil {
+		var policy SLAPolicy
+		if err := s.db.WithContext(ctx).Select("name").First(&policy, *state.PolicyID).Error; err == nil {
+			out.PolicyName = policy.Name
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// AtRisk lists running reports with a target at risk or already breached, worst first.
+func (s *Service) AtRisk(ctx context.Context, q AtRiskQuery) ([]ReportState, error) {
+	query := s.db.WithContext(ctx).
+		Joins("JOIN report_slas ON report_slas.report_id = service_reports.id").
+		Where("report_slas.policy_id IS NOT NULL AND report_slas.settled = ?", false).
+		Where("service_reports.status NOT IN ?", []string{report.StatusDone, report.StatusCancelled})
+	if q.PartnerID != 0 {
+		query = query.Where("service_reports.partner_location_id = ?", q.PartnerID)
+	}
+	if q.TeknisiID != 0 {
+		query = query.Where("service_reports.teknisi_id = ?", q.TeknisiID)
+	}
+	var reports []report.ServiceReport
+	if err := query.Order("service_reports.opened_at ASC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return []ReportState{}, nil
+	}
+	ids := make([]uint64, len(reports))
+	for i, r := range reports {
+		ids[i] = r.ID
+	}
+	var states []ReportSLA
+	if err := s.db.WithContext(ctx).Where("report_id IN ?", ids).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	byReport := make(map[uint64]*ReportSLA, len(states))
+	for i := range states {
+		byReport[states[i].ReportID] = &states[i]
+	}
+	var policies []SLAPolicy
+	if err := s.db.WithContext(ctx).Select("id", "name").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint64]string, len(policies))
+	for _, p := range policies {
+		names[p.ID] = p.Name
+	}
+
+	now := s.now()
+	out := []ReportState{}
+	for i := range reports {
+		r := &reports[i]
+		state := byReport[r.ID]
+		if state == nil {
+			continue
+		}
+		rs := ReportState{
+			ReportID:   r.ID,
+			DispatchNo: r.DispatchNo,
+			Status:     r.Status,
+			Priority:   r.Priority,
+			PolicyID:   state.PolicyID,
+			PolicyName: names[*state.PolicyID],
+			TeknisiID:  r.TeknisiID,
+			Clock:      state.Clock,
+		}
+		cal, err := s.calendarFor(ctx, state.Clock, r.PartnerLocationID)
+		if err != nil {
+			return nil, err
+		}
+		rs.State, rs.Targets = evaluate(state, cal, r.OpenedAt, now)
+		if rs.State != StateAtRisk && rs.State != StateBreached {
+			continue
+		}
+		if q.State != "" && rs.State != q.State {
+			continue
+		}
+		out = append(out, rs)
+	}
+	sortByUrgency(out)
+	return out, nil
+}
+
+// sortByUrgency puts breached jobs first, then the jobs whose next target is due soonest.
+func sortByUrgency(states []ReportState) {
+	nextDue := func(rs ReportState) time.Time {
+		var next time.Time
+		for _, t := range rs.Targets {
+			if t.DoneAt == nil && t.DueAt != nil && (next.IsZero() || t.DueAt.Before(next)) {
+				next = *t.DueAt
+			}
+		}
+		return next
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].State != states[j].State {
+			return stateRank[states[i].State] > stateRank[states[j].State]
+		}
+		return nextDue(states[i]).Before(nextDue(states[j]))
+	})
+}
@@ -0,0 +1,82 @@
+package sla
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/company/internal-service-report/internal/domain/report"
+)
+
+func TestPolicyFor(t *testing.T) {
+	id := func(v uint64) *uint64 { return &v }
+	policies := []SLAPolicy{
+		{ID: 1, Name: "global"},
+		{ID: 2, Name: "global critical", Priority: report.PriorityCritical},
+		{ID: 3, Name: "site", PartnerLocationID: id(10)},
+		{ID: 4, Name: "site high", PartnerLocationID: id(10), Priority: report.PriorityHigh},
+		{ID: 5, Name: "customer", CustomerID: id(7)},
+	}
+	tests := []struct {
+		name   string
+		report report.ServiceReport
+		want   uint64
+	}{
+		{"no scope", report.ServiceReport{Priority: report.PriorityNormal}, 1},
+		{"priority match", report.ServiceReport{Priority: report.PriorityCritical}, 2},
+		{"site beats global priority", report.ServiceReport{Priority: report.PriorityCritical, PartnerLocationID: id(10)}, 3},
+		{"site priority", report.ServiceReport{Priority: report.PriorityHigh, PartnerLocationID: id(10)}, 4},
+		{"other site", report.ServiceReport{Priority: report.PriorityHigh, PartnerLocationID: id(11)}, 1},
+		{"customer beats site", report.ServiceReport{Priority: report.PriorityHigh, PartnerLocationID: id(10), CustomerID: id(7)}, 5},
+		{"other customer", report.ServiceReport{Priority: report.PriorityNormal, CustomerID: id(8)}, 1},
+	}
+	for _, tt := range tests {
+		got := policyFor(policies, &tt.report)
+		if got == nil || got.ID != tt.want {
+			t.Errorf("%s: policyFor() = %+v, want policy %d", tt.name, got, tt.want)
+		}
+	}
+	if got := policyFor(policies[2:4], &report.ServiceReport{Priority: report.PriorityLow}); got != nil {
+		t.Errorf("policyFor() without a global policy = %+v, want nil", got)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	opened := time.Date(2026, 3, 16, 8, 0, 0, 0, time.Local)
+	at := func(minutes int) *time.Time {
+		t := opened.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+	// Assigned after 20 minutes; the technician is due on site within 4 hours and the job
+	// must be resolved within a day.
+	policy := func(m report.Milestones) *ReportSLA {
+		return &ReportSLA{
+			AssignDueAt: at(30), ArriveDueAt: at(240), ResolveDueAt: at(1440),
+			AssignedAt: m.AssignedAt, ArrivedAt: m.ArrivedAt, ResolvedAt: m.ResolvedAt,
+		}
+	}
+	tests := []struct {
+		name   string
+		state  *ReportSLA
+		now    time.Time
+		worst  string
+		states []string
+	}{
+		{"assigned, on the way", policy(report.Milestones{AssignedAt: at(20)}), *at(60), StatePending, []string{StateMet, StatePending, StatePending}},
+		{"assigned, arrival at risk", policy(report.Milestones{AssignedAt: at(20)}), *at(200), StateAtRisk, []string{StateMet, StateAtRisk, StatePending}},
+		{"assigned, never arrived", policy(report.Milestones{AssignedAt: at(20)}), *at(300), StateBreached, []string{StateMet, StateBreached, StatePending}},
+		{"arrived in time", policy(report.Milestones{AssignedAt: at(20), ArrivedAt: at(180)}), *at(300), StatePending, []string{StateMet, StateMet, StatePending}},
+		{"resolved", policy(report.Milestones{AssignedAt: at(20), ArrivedAt: at(180), ResolvedAt: at(600)}), *at(2000), StateMet, []string{StateMet, StateMet, StateMet}},
+		{"no targets", &ReportSLA{}, *at(60), StateNone, []string{StateNone, StateNone, StateNone}},
+	}
+	for _, tt := range tests {
+		worst, targets := evaluate(tt.state, nil, opened, tt.now)
+		got := make([]string, len(targets))
+		for i, target := range targets {
+			got[i] = target.State
+		}
+		if worst != tt.worst || !reflect.DeepEqual(got, tt.states) {
+			t.Errorf("%s: evaluate = %s %v, want %s %v", tt.name, worst, got, tt.worst, tt.states)
+		}
+	}
+}
@@ -15,8 +15,14 @@ var (
 	ErrForbidden       = errors.New("forbidden")
 	ErrNotFound        = errors.New("user not found")
 	ErrInvalidRoleFlow = errors.New("role hierarchy violation")
+	ErrNotTechnician   = errors.New("user is not a technician")
+	ErrInactive        = errors.New("user is inactive")
+	ErrOutsideSubtree  = errors.New("user is not managed by this admin")
 )
 
+// maxHierarchyDepth bounds the ParentID walk when checking an admin's subtree.
+const maxHierarchyDepth = 8
+
 // Service provides business operations for users.
 type Service struct {
 	db     *gorm.DB
@@ -39,6 +45,47 @@ func (s *Service) GetByID(ctx context.Context, id uint64) (*User, error) {
 	return &u, nil
 }
 
+// ValidateAssignee checks that teknisiID is an active technician inside the admin's subtree.
+func (s *Service) ValidateAssignee(ctx context.Context, adminID, teknisiID uint64) error {
+	target, err := s.GetByID(ctx, teknisiID)
+	if err != nil {
+		return err
+	}
+	if target.RoleID != RoleTeknisiID {
+		return ErrNotTechnician
+	}
+	if target.Status != "active" {
+		return ErrInactive
+	}
+	inSubtree, err := s.isDescendant(ctx, target, adminID)
+	if err != nil {
+		return err
+	}
+	if !inSubtree {
+		return ErrOutsideSubtree
+	}
+	return nil
+}
+
+// isDescendant walks the ParentID chain of u looking for ancestorID.
+func (s *Service) isDescendant(ctx context.Context, u *User, ancestorID uint64) (bool, error) {
+	parentID := u.ParentID
+	for depth := 0; parentID != nil && depth < maxHierarchyDepth; depth++ {
+		if *parentID == ancestorID {
+			return true, nil
+		}
+		var parent User
+		if err := s.db.WithContext(ctx).Select("id", "parent_id").First(&parent, *parentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		parentID = parent.ParentID
+	}
+	return false, nil
+}
+
 func (s *Service) CreateAdmin(ctx context.Context, creatorID uint64, req CreateUserRequest) (*User, error) {
 	creator, err := s.GetByID(ctx, creatorID)
 	if err != nil {
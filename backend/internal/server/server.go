@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,9 +14,17 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/company/internal-service-report/internal/config"
+	"github.com/company/internal-service-report/internal/domain/asset"
 	"github.com/company/internal-service-report/internal/domain/auth"
+	"github.com/company/internal-service-report/internal/domain/calendar"
+	"github.com/company/internal-service-report/internal/domain/customer"
+	"github.com/company/internal-service-report/internal/domain/inventory"
+	"github.com/company/internal-service-report/internal/domain/maintenance"
 	"github.com/company/internal-service-report/internal/domain/partner"
 	"github.com/company/internal-service-report/internal/domain/report"
+	"github.com/company/internal-service-report/internal/domain/reportpdf"
+	"github.com/company/internal-service-report/internal/domain/seal"
+	"github.com/company/internal-service-report/internal/domain/sla"
 	"github.com/company/internal-service-report/internal/domain/user"
 	"github.com/company/internal-service-report/internal/middleware"
 	"github.com/company/internal-service-report/pkg/bcrypt"
@@ -165,17 +175,87 @@ func New(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	userSvc := user.NewService(db, hasher, mailSvc)
 	userHandler := user.NewHandler(userSvc)
 
-	reportSvc := report.NewService(db, cfg.UploadDir)
-	reportHandler := report.NewHandler(reportSvc)
+	assetSvc := asset.NewService(db)
+	assetHandler := asset.NewHandler(assetSvc)
 
 	partnerSvc := partner.NewService(db)
 	partnerHandler := partner.NewHandler(partnerSvc)
 
+	customerSvc := customer.NewService(db)
+	customerHandler := customer.NewHandler(customerSvc)
+
+	calendarSvc := calendar.NewService(db)
+	calendarHandler := calendar.NewHandler(calendarSvc)
+
+	reportSvc := report.NewService(db, cfg.UploadDir, userSvc, cfg.MaxOpenJobsPerTeknisi)
+	reportSvc.UseDeviceResolver(assetSvc)
+	reportSvc.UseCustomerResolver(customerSvc)
+	reportSvc.UseBusinessClock(calendarSvc)
+	reportSvc.UseBranchResolver(calendarSvc)
+	dispatchPattern, err := report.ParseDispatchPattern(cfg.DispatchNoPattern, cfg.DispatchNoCounter)
+	if err != nil {
+		log.Fatalf("invalid dispatch number pattern: %v", err)
+	}
+	reportSvc.UseDispatchPattern(dispatchPattern)
+	reportSvc.UseExportStore(cfg.ExportDir, cfg.ExportAsyncRows)
+	pdfSvc := reportpdf.NewService(reportSvc, cfg.UploadDir)
+	reportSvc.UseDocumentRenderer(pdfSvc)
+	reportHandler := report.NewHandler(reportSvc)
+	pdfHandler := reportpdf.NewHandler(pdfSvc)
+	go func() {
+		if err := assetSvc.LinkReports(context.Background()); err != nil {
+			log.Printf("asset: link reports failed: %v", err)
+		}
+		if err := partnerSvc.LinkReports(context.Background()); err != nil {
+			log.Printf("partner: link reports failed: %v", err)
+		}
+		if err := reportSvc.ReindexMissing(context.Background()); err != nil {
+			log.Printf("report search: reindex failed: %v", err)
+		}
+		if err := reportSvc.SyncMissingPayloadRows(context.Background()); err != nil {
+			log.Printf("report parts: sync failed: %v", err)
+		}
+		if err := reportSvc.SyncMissingTimesheets(context.Background()); err != nil {
+			log.Printf("report timesheet: sync failed: %v", err)
+		}
+	}()
+	if err := reportSvc.FailInterruptedExports(context.Background()); err != nil {
+		log.Printf("report export: cleanup failed: %v", err)
+	}
+	go reportSvc.RunExportExpiry(context.Background(), report.ExportExpiryInterval)
+
+	inventorySvc := inventory.NewService(db)
+	inventoryHandler := inventory.NewHandler(inventorySvc)
+	reportSvc.OnFinalize(inventorySvc)
+
+	sealKey, err := seal.LoadKey(cfg.SealKeyFile)
+	if err != nil {
+		log.Fatalf("failed to load seal key: %v", err)
+	}
+	sealSvc := seal.NewService(db, cfg.UploadDir, sealKey)
+	sealSvc.UseVerifyURL(cfg.VerifyURL)
+	sealHandler := seal.NewHandler(sealSvc)
+	reportSvc.OnFinalize(sealSvc)
+	pdfSvc.UseVerifyLinks(sealSvc)
+
+	pmSvc := maintenance.NewService(db, reportSvc)
+	pmHandler := maintenance.NewHandler(pmSvc)
+	if cfg.PMSchedulerInterval > 0 {
+		go pmSvc.Run(context.Background(), cfg.PMSchedulerInterval)
+	}
+
+	slaSvc := sla.NewService(db, calendarSvc)
+	slaHandler := sla.NewHandler(slaSvc)
+	if cfg.SLACheckInterval > 0 {
+		go slaSvc.Run(context.Background(), cfg.SLACheckInterval)
+	}
+
 	api := r.Group("/api/v1")
 
 	api.POST("/auth/login", authHandler.Login)
 	api.POST("/auth/logout", authHandler.Logout)
 	api.POST("/auth/forgot-password", authHandler.ForgotPassword)
+	api.GET("/verify/:token", sealHandler.Public)
 
 	protected := api.Group("")
 	protected.Use(middleware.Auth(jwtSvc))
@@ -195,6 +275,9 @@ func New(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	admin.PATCH("/teknisi/:id/reset-password", userHandler.ResetTeknisiPassword)
 	admin.POST("/reports", reportHandler.Create)
 	admin.PATCH("/reports/:id/assign", reportHandler.Assign)
+	admin.PATCH("/reports/:id/status", reportHandler.UpdateStatus)
+	admin.POST("/reports/:id/approve", reportHandler.Approve)
+	admin.POST("/reports/:id/reject", reportHandler.Reject)
 
 	teknisiView := protected.Group("")
 	teknisiView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
@@ -203,13 +286,107 @@ func New(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	reportsView := protected.Group("/reports")
 	reportsView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
 	reportsView.GET("", reportHandler.List)
+	reportsView.GET("/search", reportHandler.Search)
+	reportsView.GET("/parts-usage", reportHandler.PartsUsage)
+	reportsView.GET("/timesheets/totals", reportHandler.TimesheetTotals)
+	reportsView.GET("/export", reportHandler.Export)
+	reportsView.GET("/export/columns", reportHandler.ExportColumns)
+	reportsView.GET("/exports/:job_id", reportHandler.ExportJob)
+	reportsView.GET("/exports/:job_id/download", reportHandler.DownloadExport)
+	reportsView.POST("/archive", reportHandler.ArchiveRange)
+	reportsView.GET("/:id/timeline", reportHandler.Timeline)
+	reportsView.GET("/:id/assignments", reportHandler.Assignments)
+	reportsView.GET("/:id/parts", reportHandler.Parts)
+	reportsView.GET("/:id/sla", slaHandler.Report)
+	reportsView.GET("/:id/metrics", reportHandler.Metrics)
+	reportsView.GET("/:id/timesheet", reportHandler.Timesheet)
+	reportsView.GET("/:id/archive", reportHandler.Archive)
+	reportsView.GET("/:id/seal", sealHandler.Verify)
+	reportsView.GET("/:id/verification", sealHandler.Tokens)
+	reportsView.POST("/:id/verification", sealHandler.IssueToken)
+	reportsView.DELETE("/:id/verification", sealHandler.RevokeTokens)
+
+	slaView := protected.Group("/sla")
+	slaView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
+	slaView.GET("/at-risk", slaHandler.AtRisk)
+	slaView.GET("/policies", slaHandler.ListPolicies)
+	slaView.POST("/policies", slaHandler.CreatePolicy)
+	slaView.PUT("/policies/:id", slaHandler.UpdatePolicy)
+	slaView.DELETE("/policies/:id", slaHandler.DeletePolicy)
 
 	partnersView := protected.Group("/partners")
 	partnersView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
 	partnersView.GET("", partnerHandler.List)
+	partnersView.GET("/provinces", partnerHandler.Provinces)
 	partnersView.POST("", partnerHandler.Create)
+	partnersView.GET("/duplicates", partnerHandler.Duplicates)
+	partnersView.POST("/import", partnerHandler.Import)
+	partnersView.PATCH("/:id", partnerHandler.Update)
+	partnersView.POST("/:id/merge", partnerHandler.Merge)
 	partnersView.DELETE("/:id", partnerHandler.Delete)
 
+	customers := protected.Group("/customers")
+	customers.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
+	customers.GET("", customerHandler.List)
+	customers.POST("", customerHandler.Create)
+	customers.GET("/:id", customerHandler.Get)
+	customers.PATCH("/:id", customerHandler.Update)
+	customers.DELETE("/:id", customerHandler.Delete)
+	customers.POST("/:id/departments", customerHandler.CreateDepartment)
+	customers.PATCH("/:id/departments/:child_id", customerHandler.UpdateDepartment)
+	customers.DELETE("/:id/departments/:child_id", customerHandler.DeleteDepartment)
+	customers.POST("/:id/contacts", customerHandler.CreateContact)
+	customers.PATCH("/:id/contacts/:child_id", customerHandler.UpdateContact)
+	customers.DELETE("/:id/contacts/:child_id", customerHandler.DeleteContact)
+
+	devices := protected.Group("/devices")
+	devices.Use(middleware.RoleGuard(user.RoleTeknisi, user.RoleAdmin, user.RoleMasterAdmin))
+	devices.GET("", assetHandler.List)
+	devices.GET("/:id", assetHandler.Get)
+	devices.GET("/:id/history", assetHandler.History)
+	devices.POST("", middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin), assetHandler.Create)
+	devices.PATCH("/:id", middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin), assetHandler.Update)
+
+	inventoryView := protected.Group("/inventory")
+	inventoryView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
+	inventoryView.GET("/parts", inventoryHandler.ListParts)
+	inventoryView.POST("/parts", inventoryHandler.CreatePart)
+	inventoryView.PATCH("/parts/:id", inventoryHandler.UpdatePart)
+	inventoryView.GET("/locations", inventoryHandler.ListLocations)
+	inventoryView.POST("/locations", inventoryHandler.CreateLocation)
+	inventoryView.GET("/stock", inventoryHandler.Stock)
+	inventoryView.PUT("/stock/threshold", inventoryHandler.SetThreshold)
+	inventoryView.POST("/adjustments", inventoryHandler.Adjust)
+	inventoryView.GET("/ledger", inventoryHandler.Ledger)
+
+	calendarView := protected.Group("/calendar")
+	calendarView.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
+	calendarView.GET("/regions", calendarHandler.ListRegions)
+	calendarView.POST("/regions", calendarHandler.CreateRegion)
+	calendarView.PUT("/regions/:id", calendarHandler.UpdateRegion)
+	calendarView.DELETE("/regions/:id", calendarHandler.DeleteRegion)
+	calendarView.GET("/holidays", calendarHandler.ListHolidays)
+	calendarView.POST("/holidays", calendarHandler.CreateHoliday)
+	calendarView.POST("/holidays/import", calendarHandler.ImportHolidays)
+	calendarView.DELETE("/holidays/:id", calendarHandler.DeleteHoliday)
+	calendarView.GET("/business-duration", calendarHandler.Duration)
+
+	pm := protected.Group("/pm")
+	pm.Use(middleware.RoleGuard(user.RoleMasterAdmin, user.RoleAdmin))
+	pm.GET("/plans", pmHandler.ListPlans)
+	pm.POST("/plans", pmHandler.CreatePlan)
+	pm.GET("/plans/:id", pmHandler.GetPlan)
+	pm.PUT("/plans/:id", pmHandler.UpdatePlan)
+	pm.DELETE("/plans/:id", pmHandler.DeletePlan)
+	pm.POST("/plans/:id/usage", pmHandler.RecordUsage)
+	pm.GET("/plans/:id/visits", pmHandler.Visits)
+	pm.GET("/overdue", pmHandler.Overdue)
+
+	reportDocs := protected.Group("/reports")
+	reportDocs.Use(middleware.RoleGuard(user.RoleTeknisi, user.RoleAdmin, user.RoleMasterAdmin))
+	reportDocs.GET("/:id/pdf", pdfHandler.PDF)
+	reportDocs.GET("/:id/bundle", pdfHandler.Bundle)
+
 	teknisi := protected.Group("/teknisi")
 	teknisi.Use(middleware.RoleGuard(user.RoleTeknisi, user.RoleAdmin, user.RoleMasterAdmin))
 	teknisi.GET("/reports", reportHandler.ListAssigned)
@@ -220,6 +397,8 @@ func New(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	teknisi.PATCH("/reports/:id/form", reportHandler.SaveTechnicianForm)
 	teknisi.PATCH("/reports/:id/progress", reportHandler.UpdateProgress)
 
+	r.GET("/verify/:token", sealHandler.PublicPage)
+
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "time": time.Now()})
 	})
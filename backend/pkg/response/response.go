@@ -6,10 +6,18 @@ func OK(c *gin.Context, data interface{}) {
     c.JSON(200, gin.H{"data": data})
 }
 
+func OKWithMeta(c *gin.Context, data interface{}, meta interface{}) {
+    c.JSON(200, gin.H{"data": data, "meta": meta})
+}
+
 func Created(c *gin.Context, data interface{}) {
     c.JSON(201, gin.H{"data": data})
 }
 
+func Accepted(c *gin.Context, data interface{}) {
+    c.JSON(202, gin.H{"data": data})
+}
+
 func NoContent(c *gin.Context) {
     c.Status(204)
 }
@@ -37,6 +45,18 @@ func NotFound(c *gin.Context, msg string) {
     c.JSON(404, gin.H{"error": msg})
 }
 
+func Conflict(c *gin.Context, msg string) {
+    c.JSON(409, gin.H{"error": msg})
+}
+
+func UnprocessableEntity(c *gin.Context, msg string) {
+    c.JSON(422, gin.H{"error": msg})
+}
+
+func ValidationFailed(c *gin.Context, msg string, fields interface{}) {
+    c.JSON(422, gin.H{"error": msg, "fields": fields})
+}
+
 func InternalError(c *gin.Context, err error) {
     c.JSON(500, gin.H{"error": err.Error()})
 }
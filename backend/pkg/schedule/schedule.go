@@ -0,0 +1,22 @@
+// Package schedule runs recurring background jobs.
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// Every calls run immediately and then once per interval until ctx is cancelled. Running
+// first means a restart does not delay the job by a full interval.
+func Every(ctx context.Context, interval time.Duration, run func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package tabular reads and writes spreadsheets as CSV or XLSX.
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrUnsupportedFile = errors.New("file must be .csv or .xlsx")
+
+// Read returns the rows of a CSV file or of the first sheet of an XLSX workbook. The format
+// is picked from the file name extension.
+func Read(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return readCSV(r)
+	case ".xlsx":
+		rows, _, err := readXLSX(r, false)
+		return rows, err
+	default:
+		return nil, ErrUnsupportedFile
+	}
+}
+
+// ReadRaw is Read for callers that parse cell values themselves. XLSX cells hold their stored
+// value instead of the text Excel displays, so dates come as serial numbers whatever their
+// number format; convert them with ExcelDate. date1904 reports the workbook's date system and
+// is always false for CSV.
+func ReadRaw(filename string, r io.Reader) (rows [][]string, date1904 bool, err error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		rows, err = readCSV(r)
+		return rows, false, err
+	case ".xlsx":
+		return readXLSX(r, true)
+	default:
+		return nil, false, ErrUnsupportedFile
+	}
+}
+
+// ExcelDate converts a serial date cell read with ReadRaw to its day and time.
+func ExcelDate(cell string, date1904 bool) (time.Time, bool) {
+	serial, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil || serial < 1 {
+		return time.Time{}, false
+	}
+	t, err := excelize.ExcelDateToTime(serial, date1904)
+	return t, err == nil
+}
+
+// IsBlank reports whether every cell of a row is empty.
+func IsBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderKey folds a header cell to lower snake case and drops a UTF-8 byte order mark.
+func HeaderKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+// readCSV accepts comma or semicolon separated files; spreadsheets set to the Indonesian
+// locale export with semicolons.
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	firstLine, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	reader := csv.NewReader(br)
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
+
+func readXLSX(r io.Reader, raw bool) ([][]string, bool, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, false, nil
+	}
+	props, err := f.GetWorkbookProps()
+	if err != nil {
+		return nil, false, err
+	}
+	rows, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: raw})
+	return rows, props.Date1904 != nil && *props.Date1904, err
+}
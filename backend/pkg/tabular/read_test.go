@@ -0,0 +1,74 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadRawXLSXDates(t *testing.T) {
+	day := time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC)
+	for _, date1904 := range []bool{false, true} {
+		f := excelize.NewFile()
+		sheet := f.GetSheetName(0)
+		if err := f.SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904}); err != nil {
+			t.Fatal(err)
+		}
+		style, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.SetCellValue(sheet, "A1", day); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.SetCellStyle(sheet, "A1", "A1", style); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := f.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		formatted, err := Read("dates.xlsx", bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, got1904, err := ReadRaw("dates.xlsx", bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got1904 != date1904 {
+			t.Errorf("date1904 = %v, want %v", got1904, date1904)
+		}
+		if formatted[0][0] != "08-17-26" {
+			t.Errorf("Read cell = %q, want the displayed 08-17-26", formatted[0][0])
+		}
+		if got, ok := ExcelDate(raw[0][0], got1904); !ok || !got.Equal(day) {
+			t.Errorf("1904=%v: ExcelDate(%q) = %v %v, want %v", date1904, raw[0][0], got, ok, day)
+		}
+	}
+}
+
+func TestExcelDate(t *testing.T) {
+	tests := []struct {
+		cell string
+		want time.Time
+		ok   bool
+	}{
+		{"46251", time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC), true},
+		{" 46251.5 ", time.Date(2026, 8, 17, 12, 0, 0, 0, time.UTC), true},
+		{"0", time.Time{}, false},
+		{"-3", time.Time{}, false},
+		{"2026-08-17", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ExcelDate(tt.cell, false)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("ExcelDate(%q) = %v %v, want %v %v", tt.cell, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer streams rows to a CSV file or to the first sheet of an XLSX workbook. Text cells
+// that a spreadsheet program would run as a formula are written with a leading quote.
+type Writer interface {
+	Write(record []string) error
+	// Numeric marks columns, by zero-based index, that hold numbers. XLSX stores their
+	// values as numbers rather than text.
+	Numeric(columns ...int)
+	// Close flushes buffered rows. XLSX workbooks are only written to the output here.
+	Close() error
+}
+
+// Formats and their content types.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns a writer for format, csv or xlsx.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		// The byte order mark makes spreadsheet programs read the file as UTF-8.
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: csv.NewWriter(w), numeric: numericColumns{}}, nil
+	case FormatXLSX:
+		f := excelize.NewFile()
+		sw, err := f.NewStreamWriter(f.GetSheetName(0))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &xlsxWriter{f: f, sw: sw, out: w, numeric: numericColumns{}}, nil
+	default:
+		return nil, ErrUnsupportedFile
+	}
+}
+
+// formulaPrefixes start cell values that spreadsheet programs evaluate as formulas.
+const formulaPrefixes = "=+-@\t\r"
+
+// SafeCell prefixes a value that would be read as a formula with a quote, so that text
+// typed into a report cannot run in the spreadsheet of whoever opens the export.
+func SafeCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// numericColumns holds the columns marked with Numeric.
+type numericColumns map[int]bool
+
+func (n numericColumns) mark(columns []int) {
+	for _, c := range columns {
+		n[c] = true
+	}
+}
+
+// number returns the value of a numeric column cell.
+func (n numericColumns) number(column int, value string) (float64, bool) {
+	if !n[column] {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	numeric numericColumns
+	cells   []string
+}
+
+func (c *csvWriter) Numeric(columns ...int) {
+	c.numeric.mark(columns)
+}
+
+func (c *csvWriter) Write(record []string) error {
+	c.cells = c.cells[:0]
+	for i, v := range record {
+		if _, ok := c.numeric.number(i, v); !ok {
+			v = SafeCell(v)
+		}
+		c.cells = append(c.cells, v)
+	}
+	return c.w.Write(c.cells)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// xlsxWriter spills rows to a temporary file through excelize's stream writer, so large
+// sheets do not stay in memory.
+type xlsxWriter struct {
+	f       *excelize.File
+	sw      *excelize.StreamWriter
+	out     io.Writer
+	row     int
+	numeric numericColumns
+}
+
+func (x *xlsxWriter) Numeric(columns ...int) {
+	x.numeric.mark(columns)
+}
+
+func (x *xlsxWriter) Write(record []string) error {
+	x.row++
+	cells := make([]interface{}, len(record))
+	for i, v := range record {
+		if f, ok := x.numeric.number(i, v); ok {
+			cells[i] = f
+		} else {
+			cells[i] = SafeCell(v)
+		}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.sw.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.f.Close()
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+	_, err := x.f.WriteTo(x.out)
+	return err
+}
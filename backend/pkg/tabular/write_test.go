@@ -0,0 +1,83 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSafeCell(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		"RSUD Dr. Soetomo":  "RSUD Dr. Soetomo",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+62 31 5501078":    "'+62 31 5501078",
+		"-2+3":              "'-2+3",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\t=1":              "'\t=1",
+		"\r=1":              "'\r=1",
+		"a=1":               "a=1",
+	}
+	for in, want := range tests {
+		if got := SafeCell(in); got != want {
+			t.Errorf("SafeCell(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Numeric(1)
+	for _, record := range [][]string{{"Name", "Hours"}, {"=cmd", "-1.5"}, {"-x", "=1+1"}} {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeffName,Hours\n'=cmd,-1.5\n'-x,'=1+1\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Numeric(1)
+	for _, record := range [][]string{{"Name", "Hours"}, {"=cmd", "2.5"}, {"RS A", ""}} {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	if v, _ := f.GetCellValue(sheet, "A2"); v != "'=cmd" {
+		t.Errorf("A2 = %q, want %q", v, "'=cmd")
+	}
+	if typ, _ := f.GetCellType(sheet, "B2"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("B2 is stored as text")
+	}
+	if v, _ := f.GetCellValue(sheet, "B2"); v != "2.5" {
+		t.Errorf("B2 = %q, want %q", v, "2.5")
+	}
+	if v, _ := f.GetCellValue(sheet, "B3"); v != "" {
+		t.Errorf("B3 = %q, want empty", v)
+	}
+}
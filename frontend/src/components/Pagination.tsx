@@ -0,0 +1,49 @@
+import { ChevronLeft, ChevronRight } from "lucide-react";
+import type { PageMeta } from "../types/pagination";
+
+type PaginationProps = {
+  meta: PageMeta;
+  loading?: boolean;
+  onPageChange: (page: number) => void;
+};
+
+export default function Pagination({ meta, loading = false, onPageChange }: PaginationProps) {
+  const totalPages = Math.max(meta.total_pages, 1);
+  const first = meta.total === 0 ? 0 : (meta.page - 1) * meta.page_size + 1;
+  const last = Math.min(meta.page * meta.page_size, meta.total);
+  const buttonClass =
+    "inline-flex items-center gap-1 rounded-full border border-slate-200 bg-white px-3 py-1.5 text-[11px] font-semibold text-slate-700 shadow-sm hover:border-slate-300 disabled:cursor-not-allowed disabled:opacity-50";
+
+  return (
+    <div className="mt-4 flex flex-wrap items-center justify-between gap-3 text-xs text-slate-500">
+      <span>
+        {first}–{last} of {meta.total} reports
+      </span>
+      <div className="flex items-center gap-2">
+        <button
+          type="button"
+          className={buttonClass}
+          disabled={loading || meta.page <= 1}
+          onClick={() => onPageChange(meta.page - 1)}
+          aria-label="Previous page"
+        >
+          <ChevronLeft className="h-3.5 w-3.5" />
+          Prev
+        </button>
+        <span className="font-semibold text-slate-700">
+          Page {Math.min(meta.page, totalPages)} of {totalPages}
+        </span>
+        <button
+          type="button"
+          className={buttonClass}
+          disabled={loading || meta.page >= totalPages}
+          onClick={() => onPageChange(meta.page + 1)}
+          aria-label="Next page"
+        >
+          Next
+          <ChevronRight className="h-3.5 w-3.5" />
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -6,6 +6,10 @@ import { api } from "../../lib/api";
 import IndonesiaMapReal from "../../components/IndonesiaMapReal";
 import type { ProvinceWithPartners, PartnerLocation } from "../../types/partners";
 import { provinceOptions, getProvinceOption } from "../../data/provinces";
+import Pagination from "../../components/Pagination";
+import { emptyPageMeta, type PageMeta } from "../../types/pagination";
+
+const PAGE_SIZE = 20;
 
 type Report = {
   id: number;
@@ -24,6 +28,9 @@ type Report = {
 export default function AdminDashboard() {
   const navigate = useNavigate();
   const [reports, setReports] = useState<Report[]>([]);
+  const [page, setPage] = useState(1);
+  const [meta, setMeta] = useState<PageMeta>(emptyPageMeta(PAGE_SIZE));
+  const [summary, setSummary] = useState({ open: 0, progress: 0, done: 0 });
   const [loading, setLoading] = useState(true);
   const [isMapFocused, setIsMapFocused] = useState(false);
   const [partners, setPartners] = useState<PartnerLocation[]>([]);
@@ -33,18 +40,43 @@ export default function AdminDashboard() {
     province_code: provinceOptions[0]?.code ?? "",
     hospital_name: "",
     address: "",
-    maintenance_count: 0,
   });
   const [partnerSubmitting, setPartnerSubmitting] = useState(false);
   const [partnerDeleting, setPartnerDeleting] = useState(false);
 
   useEffect(() => {
+    let active = true;
     setLoading(true);
     api
-      .get("/reports")
-      .then((res) => setReports(res.data.data || []))
-      .catch(() => setReports([]))
-      .finally(() => setLoading(false));
+      .get("/reports", { params: { page, page_size: PAGE_SIZE } })
+      .then((res) => {
+        if (!active) return;
+        setReports(res.data.data || []);
+        setMeta(res.data.meta ?? emptyPageMeta(PAGE_SIZE));
+      })
+      .catch(() => {
+        if (!active) return;
+        setReports([]);
+        setMeta(emptyPageMeta(PAGE_SIZE));
+      })
+      .finally(() => {
+        if (active) setLoading(false);
+      });
+    return () => {
+      active = false;
+    };
+  }, [page]);
+
+  useEffect(() => {
+    // The cards count every report, not just the page on screen, so ask the API for totals.
+    const countStatus = (status: string) =>
+      api
+        .get("/reports", { params: { status, page_size: 1 } })
+        .then((res) => (res.data.meta?.total as number) ?? 0)
+        .catch(() => 0);
+    Promise.all([countStatus("open"), countStatus("progress"), countStatus("done")]).then(
+      ([open, progress, done]) => setSummary({ open, progress, done })
+    );
   }, []);
 
   const fetchPartners = () => {
@@ -69,15 +101,6 @@ export default function AdminDashboard() {
     };
   }, [isMapFocused]);
 
-  const summary = useMemo(() => {
-    return {
-      total: reports.length,
-      progress: reports.filter((r) => r.status === "progress").length,
-      done: reports.filter((r) => r.status === "done").length,
-      open: reports.filter((r) => r.status === "open").length,
-    };
-  }, [reports]);
-
   const provincesForMap = useMemo<ProvinceWithPartners[]>(() => {
     const grouped: Record<string, ProvinceWithPartners> = {};
     partners.forEach((partner) => {
@@ -101,7 +124,7 @@ export default function AdminDashboard() {
   const handlePartnerFieldChange = (field: keyof typeof partnerForm, value: string) => {
     setPartnerForm((prev) => ({
       ...prev,
-      [field]: field === "maintenance_count" ? Number(value) : value,
+      [field]: value,
     }));
   };
 
@@ -119,13 +142,11 @@ export default function AdminDashboard() {
         province_name: province?.name ?? partnerForm.province_code,
         hospital_name: partnerForm.hospital_name,
         address: partnerForm.address,
-        maintenance_count: partnerForm.maintenance_count,
       });
       setPartnerForm({
         province_code: partnerForm.province_code,
         hospital_name: "",
         address: "",
-        maintenance_count: 0,
       });
       fetchPartners();
     } catch (error) {
@@ -281,16 +302,6 @@ export default function AdminDashboard() {
                     onChange={(e) => handlePartnerFieldChange("address", e.target.value)}
                   />
                 </div>
-                <div>
-                  <label className="text-xs uppercase tracking-[0.2em] text-slate-400">Maintenance Visits</label>
-                  <input
-                    type="number"
-                    min={0}
-                    className="mt-1 w-full rounded-xl border border-slate-200 bg-white px-3 py-2 text-sm text-slate-700 focus:outline-none focus:ring-2 focus:ring-slate-200"
-                    value={partnerForm.maintenance_count}
-                    onChange={(e) => handlePartnerFieldChange("maintenance_count", e.target.value)}
-                  />
-                </div>
                 <button
                   type="submit"
                   disabled={partnerSubmitting}
@@ -453,6 +464,7 @@ export default function AdminDashboard() {
             </tbody>
           </table>
         </div>
+        <Pagination meta={meta} loading={loading} onPageChange={setPage} />
       </section>
     </div>
   );
@@ -2,6 +2,10 @@ import { useEffect, useState } from "react";
 import { useNavigate } from "react-router-dom";
 import { QrCode } from "lucide-react";
 import { api } from "../../lib/api";
+import Pagination from "../../components/Pagination";
+import { emptyPageMeta, type PageMeta } from "../../types/pagination";
+
+const PAGE_SIZE = 20;
 
 interface ReportHistory {
   id: number;
@@ -14,16 +18,32 @@ interface ReportHistory {
 export default function HistoryPage() {
   const navigate = useNavigate();
   const [histories, setHistories] = useState<ReportHistory[]>([]);
+  const [page, setPage] = useState(1);
+  const [meta, setMeta] = useState<PageMeta>(emptyPageMeta(PAGE_SIZE));
   const [loading, setLoading] = useState(true);
 
   useEffect(() => {
+    let active = true;
     setLoading(true);
     api
-      .get("/reports", { params: { status: "done" } })
-      .then((res) => setHistories(res.data.data || []))
-      .catch(() => setHistories([]))
-      .finally(() => setLoading(false));
-  }, []);
+      .get("/reports", { params: { status: "done", sort: "completed_at", page, page_size: PAGE_SIZE } })
+      .then((res) => {
+        if (!active) return;
+        setHistories(res.data.data || []);
+        setMeta(res.data.meta ?? emptyPageMeta(PAGE_SIZE));
+      })
+      .catch(() => {
+        if (!active) return;
+        setHistories([]);
+        setMeta(emptyPageMeta(PAGE_SIZE));
+      })
+      .finally(() => {
+        if (active) setLoading(false);
+      });
+    return () => {
+      active = false;
+    };
+  }, [page]);
 
   return (
     <section className="space-y-6">
@@ -59,7 +79,7 @@ export default function HistoryPage() {
                   </td>
                 </tr>
               )}
-              {histories.map((report) => (
+              {!loading && histories.map((report) => (
                 <tr key={report.id} className="border-t border-slate-100 bg-white odd:bg-white even:bg-slate-50">
                   <td className="px-4 py-3 font-semibold text-slate-900">{report.dispatch_no}</td>
                   <td className="px-4 py-3 text-slate-700">{new Date(report.updated_at).toLocaleDateString("en-GB", { day: "2-digit", month: "short", year: "numeric" })}</td>
@@ -84,6 +104,7 @@ export default function HistoryPage() {
             </tbody>
           </table>
         </div>
+        <Pagination meta={meta} loading={loading} onPageChange={setPage} />
       </div>
     </section>
   );
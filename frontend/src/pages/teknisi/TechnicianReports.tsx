@@ -9,6 +9,8 @@ import kmsLogo from "../../assets/kmsLogo.jpeg";
 import ServiceReportPrintModal, { type PrintableReport } from "../../components/print/ServiceReportPrintModal";
 import { useAuth } from "../../hooks/useAuth";
 import { DEFAULT_DISPATCH_PLACEHOLDER, useDocumentTitle } from "../../hooks/useDocumentTitle";
+import Pagination from "../../components/Pagination";
+import { emptyPageMeta, type PageMeta } from "../../types/pagination";
 
 type DeviceRow = {
   partNo: string;
@@ -96,6 +98,8 @@ type ReportFormValues = {
   approvedSignature: string;
 };
 
+const LIST_PAGE_SIZE = 20;
+
 const jobOptions = ["Inspection", "PPM/Maintenance", "Repair", "Sales Support", "Test & Comm.", "Training", "TSB"];
 
 const defaultValues: ReportFormValues = {
@@ -172,6 +176,8 @@ export default function ReportForm() {
   const [problemPhotos, setProblemPhotos] = useState<string[]>([]);
   const [reports, setReports] = useState<ReportDetail[]>([]);
   const [loadingList, setLoadingList] = useState(false);
+  const [listPage, setListPage] = useState(1);
+  const [listMeta, setListMeta] = useState<PageMeta>(emptyPageMeta(LIST_PAGE_SIZE));
   const [showFinalizeConfirm, setShowFinalizeConfirm] = useState(false);
   const [pendingFinalize, setPendingFinalize] = useState(false);
   const [finalizeReady, setFinalizeReady] = useState(false);
@@ -517,14 +523,16 @@ export default function ReportForm() {
     let active = true;
     setLoadingList(true);
     api
-      .get("/teknisi/reports")
+      .get("/teknisi/reports", { params: { page: listPage, page_size: LIST_PAGE_SIZE } })
       .then((res) => {
         if (!active) return;
         setReports(res.data?.data ?? []);
+        setListMeta(res.data?.meta ?? emptyPageMeta(LIST_PAGE_SIZE));
       })
       .catch(() => {
         if (!active) return;
         setReports([]);
+        setListMeta(emptyPageMeta(LIST_PAGE_SIZE));
       })
       .finally(() => {
         if (!active) return;
@@ -533,7 +541,7 @@ export default function ReportForm() {
     return () => {
         active = false;
     };
-  }, [id]);
+  }, [id, listPage]);
 
   useEffect(() => {
     if (!id) return;
@@ -769,7 +777,7 @@ export default function ReportForm() {
     if (id) {
       try {
         await api.patch(`/teknisi/reports/${id}/form`, { payload: payloadForStorage });
-        await api.patch(`/teknisi/reports/${id}/progress`, { status: "done", job_summary: "done", action_taken: "done" });
+        await api.patch(`/teknisi/reports/${id}/progress`, { status: "review", job_summary: "done", action_taken: "done" });
         setValue("finalizedDate", dateOnly, { shouldDirty: true });
         setMessage("Finalized and saved successfully.");
         setFinalizeFeedback({ status: "success", message: "Laporan berhasil difinalkan." });
@@ -887,6 +895,7 @@ export default function ReportForm() {
               </tbody>
             </table>
           </div>
+          <Pagination meta={listMeta} loading={loadingList} onPageChange={setListPage} />
         </section>
       ) : (
         <>
@@ -0,0 +1,13 @@
+export type PageMeta = {
+  page: number;
+  page_size: number;
+  total: number;
+  total_pages: number;
+};
+
+export const emptyPageMeta = (pageSize: number): PageMeta => ({
+  page: 1,
+  page_size: pageSize,
+  total: 0,
+  total_pages: 0,
+});